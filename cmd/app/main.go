@@ -26,7 +26,7 @@ func main() {
 		DB:     database.GetDB(),
 		Prefix: "gopay",
 		Chains: config.Config.Payment.Chains,
-		Fiats:  config.Config.Payment.Fiats,
+		Fiats:  config.Config.Payment.Fiats.Gopay(),
 	}); err != nil {
 		log.Fatalf("gopay error %v", err)
 	}
@@ -41,5 +41,8 @@ func main() {
 
 	workers.RegisterConsumers()
 
+	//Periodic jobs: projects expiry, unsigned contracts cancellation and reminders
+	go workers.ScheduleJobs()
+
 	gomq.Init()
 }
@@ -0,0 +1,159 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"socious/src/apps/models"
+	"socious/src/config"
+
+	"github.com/google/uuid"
+	"github.com/socious-io/gopay"
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/customer"
+	"github.com/stripe/stripe-go/v81/paymentmethod"
+	"github.com/stripe/stripe-go/v81/setupintent"
+)
+
+// SaveCardParams points to the card confirmed by the client on the fiat service,
+// either with the setup intent it's confirmed with or its payment method.
+type SaveCardParams struct {
+	IdentityID      uuid.UUID
+	Fiat            string
+	Email           string
+	SetupIntentID   string
+	PaymentMethodID string
+}
+
+// CardProvider saves the cards of identities on the fiat service deposits are charged on.
+// SaveCard returns the card to be created with the payment method of the fiat service as meta.
+type CardProvider interface {
+	SaveCard(ctx context.Context, params SaveCardParams) (*models.Card, error)
+	DeleteCard(ctx context.Context, fiat string, card models.Card) error
+}
+
+type stripeCardProvider struct {
+	fiats gopay.Fiats
+}
+
+// NewStripeCardProvider returns a provider saving cards on the stripe accounts of the fiats
+func NewStripeCardProvider(fiats gopay.Fiats) CardProvider {
+	return &stripeCardProvider{fiats: fiats}
+}
+
+var cardProvider CardProvider
+
+// SetCardProvider replaces the provider cards are saved on, nil falls back to stripe on the configured fiats
+func SetCardProvider(provider CardProvider) {
+	cardProvider = provider
+}
+
+// Cards returns the provider cards are saved on
+func Cards() CardProvider {
+	if cardProvider != nil {
+		return cardProvider
+	}
+	return NewStripeCardProvider(config.Config.Payment.Fiats.Gopay())
+}
+
+// CardFiat returns the fiat service the deposits on the card are charged on
+func CardFiat(isJp bool) string {
+	if isJp {
+		return string(models.OauthConnectedProvidersStripeJp)
+	}
+	return string(models.OauthConnectedProvidersStripe)
+}
+
+func (p *stripeCardProvider) fiat(name string) (*gopay.Fiat, error) {
+	for i, f := range p.fiats {
+		if f.Name == name {
+			return &p.fiats[i], nil
+		}
+	}
+	return nil, fmt.Errorf("fiat service %s is not configured", name)
+}
+
+// SaveCard attaches the payment method to a new customer, deposits are charged on the default payment
+// method of the customer so every card is saved as its own customer. Payment methods attached to a
+// customer already are refused, making them default would change the card charged on its other rows.
+func (p *stripeCardProvider) SaveCard(ctx context.Context, params SaveCardParams) (*models.Card, error) {
+	fiat, err := p.fiat(params.Fiat)
+	if err != nil {
+		return nil, err
+	}
+	backend := stripe.GetBackend(stripe.APIBackend)
+
+	paymentMethodID := params.PaymentMethodID
+	if params.SetupIntentID != "" {
+		intent, err := (&setupintent.Client{B: backend, Key: fiat.ApiKey}).Get(params.SetupIntentID, nil)
+		if err != nil {
+			return nil, err
+		}
+		if intent.Status != stripe.SetupIntentStatusSucceeded {
+			return nil, fmt.Errorf("setup intent is %s", intent.Status)
+		}
+		if intent.PaymentMethod == nil {
+			return nil, fmt.Errorf("setup intent has no payment method")
+		}
+		paymentMethodID = intent.PaymentMethod.ID
+	}
+	if paymentMethodID == "" {
+		return nil, fmt.Errorf("setup intent or payment method is required")
+	}
+
+	methods := &paymentmethod.Client{B: backend, Key: fiat.ApiKey}
+	pm, err := methods.Get(paymentMethodID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if pm.Card == nil {
+		return nil, fmt.Errorf("payment method %s is not a card", pm.ID)
+	}
+
+	if pm.Customer != nil {
+		return nil, fmt.Errorf("payment method %s is attached to a customer already", pm.ID)
+	}
+
+	cus, err := fiat.AddCustomer(params.Email)
+	if err != nil {
+		return nil, err
+	}
+	customerID := cus.ID
+	if pm, err = methods.Attach(pm.ID, &stripe.PaymentMethodAttachParams{Customer: stripe.String(customerID)}); err != nil {
+		return nil, err
+	}
+	if _, err := (&customer.Client{B: backend, Key: fiat.ApiKey}).Update(customerID, &stripe.CustomerParams{
+		InvoiceSettings: &stripe.CustomerInvoiceSettingsParams{
+			DefaultPaymentMethod: stripe.String(pm.ID),
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	meta, err := json.Marshal(pm)
+	if err != nil {
+		return nil, err
+	}
+	brand := string(pm.Card.Brand)
+	card := &models.Card{
+		Brand:    &brand,
+		Meta:     meta,
+		Customer: &customerID,
+	}
+	if pm.BillingDetails != nil && pm.BillingDetails.Name != "" {
+		card.HolderName = &pm.BillingDetails.Name
+	}
+	return card, nil
+}
+
+func (p *stripeCardProvider) DeleteCard(ctx context.Context, fiat string, card models.Card) error {
+	f, err := p.fiat(fiat)
+	if err != nil {
+		return err
+	}
+	// Cards saved before payment methods were kept as meta are left on their customer
+	if card.PaymentMethodID() == "" {
+		return nil
+	}
+	return f.DeleteCard(card.PaymentMethodID())
+}
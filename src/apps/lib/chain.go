@@ -0,0 +1,209 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"socious/src/apps/models"
+	"socious/src/config"
+	"strconv"
+	"strings"
+
+	"github.com/socious-io/gopay"
+)
+
+// Confirmations of a crypto deposit used when the payment config is missing
+const DEPOSIT_CONFIRMATIONS = 12
+
+// Transferred amounts are compared in units, this absorbs float conversion noise
+const amountTolerance = 1e-9
+
+var ErrDepositMismatch = errors.New("deposit does not match")
+
+// TxReceipt is a token transfer as found on chain
+type TxReceipt struct {
+	TxHash        string
+	From          string
+	To            string
+	TokenAddress  string
+	Amount        float64
+	Confirmations int
+}
+
+// ChainClient looks token transfers up on chain. Receipt returns nil without error
+// when the transaction is not found (yet) so it can be looked up again later.
+type ChainClient interface {
+	Receipt(ctx context.Context, txHash, tokenAddress string) (*TxReceipt, error)
+}
+
+type explorerClient struct {
+	chains gopay.Chains
+}
+
+// NewChainClient returns a client looking transfers up on the explorers of the chains
+func NewChainClient(chains gopay.Chains) ChainClient {
+	return &explorerClient{chains: chains}
+}
+
+// DepositConfirmations returns the blocks required on top of a crypto deposit before it's confirmed
+func DepositConfirmations() int {
+	if config.Config.Payment.Confirmations < 1 {
+		return DEPOSIT_CONFIRMATIONS
+	}
+	return config.Config.Payment.Confirmations
+}
+
+// FindChainToken returns the chain the token address belongs to
+func FindChainToken(chains gopay.Chains, tokenAddress string) (*gopay.Chain, *gopay.CryptoToken, error) {
+	for i, c := range chains {
+		for j, t := range c.Tokens {
+			if strings.EqualFold(t.Address, tokenAddress) {
+				return &chains[i], &chains[i].Tokens[j], nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("token address %s not found", tokenAddress)
+}
+
+// FindNetworkToken returns the token of the address when it's on the chain of the wallet network
+func FindNetworkToken(chains gopay.Chains, network models.WalletNetwork, tokenAddress string) (*gopay.CryptoToken, error) {
+	chain, token, err := FindChainToken(chains, tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(chain.Name, string(network)) {
+		return nil, fmt.Errorf("token %s is not on %s", tokenAddress, network)
+	}
+	return token, nil
+}
+
+func (c *explorerClient) Receipt(ctx context.Context, txHash, tokenAddress string) (*TxReceipt, error) {
+	chain, token, err := FindChainToken(c.chains, tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// gopay waits on EVM chains until the transfer is confirmed, the explorer is asked once instead
+	if chain.Type == gopay.EVM {
+		return evmReceipt(ctx, *chain, txHash)
+	}
+
+	info, err := chain.GetTXInfo(txHash, *token)
+	if err != nil {
+		return nil, err
+	}
+	receipt := &TxReceipt{
+		TxHash: info.TxHash,
+		From:   info.From,
+		To:     info.To,
+		Amount: info.TotalAmount,
+	}
+	// Only the amount of the token is summed up from the transaction outputs
+	if info.TotalAmount > 0 {
+		receipt.TokenAddress = token.Address
+	}
+	switch meta := info.Meta.(type) {
+	case gopay.CardanoTokenTransferResponse:
+		receipt.Confirmations = meta.Block.Confirmations
+	case gopay.MidnightTokenTransferResponse:
+		receipt.Confirmations = meta.Confirmations
+		receipt.TokenAddress = meta.AssetId
+	}
+	return receipt, nil
+}
+
+func evmReceipt(ctx context.Context, chain gopay.Chain, txHash string) (*TxReceipt, error) {
+	query := url.Values{}
+	query.Set("module", "account")
+	query.Set("action", "tokentx")
+	query.Set("address", chain.ContractAddress)
+	query.Set("sort", "desc")
+	query.Set("apikey", chain.ApiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s?%s", chain.Explorer, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("explorer of %s responded %s", chain.Name, resp.Status)
+	}
+
+	var response struct {
+		Status  string
+		Message string
+		Result  json.RawMessage
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	// Explorers return the error message as the result when the request failed
+	transfers := []gopay.EvmTokenTransferResponse{}
+	if err := json.Unmarshal(response.Result, &transfers); err != nil {
+		return nil, fmt.Errorf("explorer of %s responded %s", chain.Name, response.Message)
+	}
+
+	for _, t := range transfers {
+		if !strings.EqualFold(t.Hash, txHash) {
+			continue
+		}
+		confirmations, _ := strconv.Atoi(t.Confirmations)
+		return &TxReceipt{
+			TxHash:        t.Hash,
+			From:          t.From,
+			To:            t.To,
+			TokenAddress:  t.ContractAddress,
+			Amount:        tokenAmount(t.Value, t.TokenDecimal),
+			Confirmations: confirmations,
+		}, nil
+	}
+	return nil, nil
+}
+
+// tokenAmount converts the value in the smallest unit of the token to units
+func tokenAmount(value, decimals string) float64 {
+	v, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0
+	}
+	d, _ := strconv.Atoi(strings.TrimSpace(decimals))
+	v.Quo(v, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d)), nil)))
+	amount, _ := v.Float64()
+	return amount
+}
+
+// MatchReceipt checks the transfer pays at least the expected amount of the token to the recipient of the deposit
+func MatchReceipt(deposit models.CryptoDeposit, receipt TxReceipt) error {
+	if !strings.EqualFold(receipt.To, deposit.Recipient) {
+		return fmt.Errorf("%w: sent to %s instead of %s", ErrDepositMismatch, receipt.To, deposit.Recipient)
+	}
+	if !strings.EqualFold(receipt.TokenAddress, deposit.TokenAddress) {
+		return fmt.Errorf("%w: token %s sent instead of %s", ErrDepositMismatch, receipt.TokenAddress, deposit.TokenAddress)
+	}
+	if receipt.Amount+amountTolerance < deposit.ExpectedAmount {
+		return fmt.Errorf("%w: %v sent instead of %v", ErrDepositMismatch, receipt.Amount, deposit.ExpectedAmount)
+	}
+	return nil
+}
+
+// MatchPayoutReceipt checks the transfer sent the payout token to the referrer wallet for at least the payout amount
+func MatchPayoutReceipt(payout models.ReferralPayout, receipt TxReceipt) error {
+	if !strings.EqualFold(receipt.To, payout.Address) {
+		return fmt.Errorf("payout sent to %s instead of %s", receipt.To, payout.Address)
+	}
+	if !strings.EqualFold(receipt.TokenAddress, payout.Currency) {
+		return fmt.Errorf("payout token %s sent instead of %s", receipt.TokenAddress, payout.Currency)
+	}
+	if receipt.Amount+amountTolerance < payout.Amount {
+		return fmt.Errorf("payout of %v sent instead of %v", receipt.Amount, payout.Amount)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package lib
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"socious/src/apps/models"
+	"socious/src/config"
+
+	"github.com/google/uuid"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/socious-io/gomq"
+	"github.com/socious-io/gopay"
+)
+
+// NewInvoice prices the invoice of the contract payment, it's issued by the client to the provider and
+// bills the payout of the amounts only, fees are the platform's and not billed by the client. Tax at the
+// configured rate is included in the payout, the line items sum up to the subtotal before tax.
+func NewInvoice(contract models.Contract, paymentID uuid.UUID, invoiceType models.InvoiceType, amounts *models.AmountsBreakdown) *models.Invoice {
+	currency := string(models.USD)
+	if contract.Currency != nil {
+		currency = string(*contract.Currency)
+	}
+
+	taxRate := config.Config.Invoices.TaxRate
+	tax := int64(math.Round(float64(amounts.Payout) * taxRate / (1 + taxRate)))
+	subtotal := amounts.Payout - tax
+
+	return &models.Invoice{
+		Type:        invoiceType,
+		IssuerID:    contract.ClientID,
+		RecipientID: contract.ProviderID,
+		ContractID:  contract.ID,
+		PaymentID:   paymentID,
+		Currency:    currency,
+		LineItems: []models.InvoiceLineItem{{
+			Type:        models.AmountLineItemPayout,
+			Description: "Contract payout",
+			Amount:      amounts.Major(subtotal),
+		}},
+		Subtotal:         amounts.Major(subtotal),
+		TaxRate:          taxRate,
+		TaxAmount:        amounts.Major(tax),
+		Total:            amounts.Major(amounts.Payout),
+		IssuerDetails:    billingDetails(contract.Client),
+		RecipientDetails: billingDetails(contract.Provider),
+	}
+}
+
+func billingDetails(identity *models.Identity) *models.BillingDetails {
+	details := &models.BillingDetails{Name: identityName(identity)}
+	if identity == nil {
+		return details
+	}
+	meta := func(key string) *string {
+		if v, ok := identity.MetaMap[key].(string); ok && v != "" {
+			return &v
+		}
+		return nil
+	}
+	details.Email = meta("email")
+	details.Address = meta("address")
+	details.City = meta("city")
+	details.Country = meta("country")
+	details.TaxID = meta("tax_id")
+	return details
+}
+
+// IssueInvoice issues the invoice of the payment once per type and queues its email to both parties
+func IssueInvoice(ctx context.Context, contract models.Contract, paymentID uuid.UUID, invoiceType models.InvoiceType, amounts *models.AmountsBreakdown) (*models.Invoice, error) {
+	invoice, err := models.GetInvoiceByPayment(paymentID, invoiceType)
+	if err == nil {
+		return invoice, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	invoice = NewInvoice(contract, paymentID, invoiceType, amounts)
+	if err := invoice.Create(ctx); err != nil {
+		return nil, err
+	}
+
+	// Invoices not picked up from the queue are emailed by the invoice-emails job
+	gomq.Mq.SendJson("invoice_emails", map[string]string{
+		"id": invoice.ID.String(),
+	})
+	return invoice, nil
+}
+
+// IssueDepositInvoice issues the invoice of the deposited payment priced as the contract
+func IssueDepositInvoice(ctx context.Context, contract models.Contract, payment *gopay.Payment) (*models.Invoice, error) {
+	orgReferrer, _ := models.GetReferring(contract.ProviderID)
+	userReferrer, _ := models.GetReferring(contract.ClientID)
+	options := AmountsOptionsFromContract(contract, orgReferrer, userReferrer)
+	options.Amount = payment.TotalAmount
+	return IssueInvoice(ctx, contract, payment.ID, models.InvoiceTypeDeposit, CalculateAmounts(options))
+}
+
+// InvoicePdf stamps the invoice on the template pdf
+func InvoicePdf(templatePath string, invoice models.Invoice) ([]byte, error) {
+	template, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read invoice template: %v", err)
+	}
+
+	conf := model.NewDefaultConfiguration()
+	dim, err := pdfPageDim(template, conf)
+	if err != nil {
+		return nil, err
+	}
+	stamps, err := textStamps(dim, fmt.Sprintf("Invoice %s", invoice.Number), invoicePdfLines(invoice))
+	if err != nil {
+		return nil, err
+	}
+	return stampPdf(template, stamps, conf)
+}
+
+func invoicePdfLines(invoice models.Invoice) []string {
+	lines := []string{
+		fmt.Sprintf("Issued at: %s    Type: %s", invoice.IssuedAt.Format("2006-01-02"), invoice.Type),
+		fmt.Sprintf("Contract: %s", invoice.ContractID),
+		"",
+	}
+	for _, party := range []struct {
+		label   string
+		details *models.BillingDetails
+	}{{"From", invoice.IssuerDetails}, {"To", invoice.RecipientDetails}} {
+		if party.details == nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", party.label, party.details.Name))
+		for _, v := range []*string{party.details.Address, party.details.City, party.details.Country, party.details.Email} {
+			if v != nil {
+				lines = append(lines, fmt.Sprintf("  %s", *v))
+			}
+		}
+		if party.details.TaxID != nil {
+			lines = append(lines, fmt.Sprintf("  Tax ID: %s", *party.details.TaxID))
+		}
+	}
+	lines = append(lines, "")
+
+	for _, item := range invoice.LineItems {
+		lines = append(lines, fmt.Sprintf("%s: %v %s", item.Description, item.Amount, invoice.Currency))
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("Subtotal: %v %s", invoice.Subtotal, invoice.Currency),
+		fmt.Sprintf("Tax (%v%%): %v %s", invoice.TaxRate*100, invoice.TaxAmount, invoice.Currency),
+		fmt.Sprintf("Total: %v %s", invoice.Total, invoice.Currency),
+	)
+	return lines
+}
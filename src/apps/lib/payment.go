@@ -1,11 +1,15 @@
 package lib
 
 import (
+	"log"
 	"math"
+	"math/big"
 	"socious/src/apps/models"
+	"socious/src/config"
+	"strconv"
 )
 
-// Fee multipliers
+// Fee multipliers, used when no fee policy is active
 const IMPACT_ORG_FEE = 0.02
 const IMPACT_USER_FEE = 0.05
 const ORG_FEE = 0.03
@@ -23,14 +27,18 @@ type AmountsOptions struct {
 	UserReferredWallet *string
 	OrgFeeDiscount     bool
 	UserFeeDiscount    bool
+	Policy             *models.FeePolicy
+	// Shares of the fees credited to the referrers
+	OrgReferrerShare  float64
+	UserReferrerShare float64
 }
 
 func AmountsOptionsFromContract(contract models.Contract, orgReferrer *models.Referring, userReferrer *models.Referring) AmountsOptions {
 	round := 1.0
 	service := models.PaymentServiceStripe
 
-	if contract.Currency != nil && *contract.Currency != models.JPY {
-		round = 100.0
+	if contract.Currency != nil {
+		round = math.Pow10(contract.Currency.Decimals())
 	}
 	if *contract.PaymentType == models.PaymentModeTypeCrypto {
 		round = 100000.0
@@ -65,60 +73,172 @@ func AmountsOptionsFromContract(contract models.Contract, orgReferrer *models.Re
 		OrgFeeDiscount:     orgReferrerFeeDiscount,
 		UserFeeDiscount:    userReferrerFeeDiscount,
 		Service:            service,
+		Policy:             ContractFeePolicy(contract, isVerified.(bool), service),
+		OrgReferrerShare:   config.Config.Referrals.Share,
+		UserReferrerShare:  config.Config.Referrals.Share,
 	}
 }
 
-func CalculateAmounts(options AmountsOptions) map[string]any {
-	orgFeeRate, userFeeRate := ORG_FEE, USER_FEE
-	if options.IsVerified {
-		orgFeeRate, userFeeRate = IMPACT_ORG_FEE, IMPACT_USER_FEE
+// ContractFeePolicy returns the policy version snapshotted on the contract, or the active one for new contracts.
+// The snapshotted policy is fetched with the contract, it's only queried for contracts built apart.
+func ContractFeePolicy(contract models.Contract, isVerified bool, service models.PaymentService) *models.FeePolicy {
+	if contract.FeePolicy != nil {
+		return contract.FeePolicy
 	}
+	if contract.FeePolicyID != nil {
+		policy, err := models.GetFeePolicy(*contract.FeePolicyID)
+		if err == nil {
+			return policy
+		}
+		log.Printf("Error fetching fee policy %s of contract %s: %v\n", *contract.FeePolicyID, contract.ID, err)
+	}
+
+	policy, err := models.ResolveFeePolicy(models.FeePolicySelector{
+		IdentityID:    &contract.ProviderID,
+		Verified:      isVerified,
+		Service:       service,
+		Currency:      contract.Currency,
+		CryptoNetwork: contract.CryptoNetwork,
+	})
+	if err != nil {
+		log.Printf("Error resolving fee policy of contract %s: %v\n", contract.ID, err)
+		return nil
+	}
+	return policy
+}
+
+// DefaultFeePolicy holds the built-in rates applied when no policy is stored
+func DefaultFeePolicy(isVerified bool) models.FeePolicy {
+	policy := models.FeePolicy{
+		Name:                    "default",
+		OrgFee:                  ORG_FEE,
+		UserFee:                 USER_FEE,
+		StripeFee:               STRIPE_FEE,
+		ReferredOrgFeeDiscount:  REFERRED_ORG_FEE_DISCOUNT,
+		ReferredUserFeeDiscount: REFERRED_USER_FEE_DISCOUNT,
+	}
+	if isVerified {
+		policy.OrgFee, policy.UserFee = IMPACT_ORG_FEE, IMPACT_USER_FEE
+	}
+	return policy
+}
+
+// AmountsOptionsFromMilestone calculates fees on the milestone portion with the same rules as its contract
+func AmountsOptionsFromMilestone(contract models.Contract, milestone models.ContractMilestone, orgReferrer *models.Referring, userReferrer *models.Referring) AmountsOptions {
+	options := AmountsOptionsFromContract(contract, orgReferrer, userReferrer)
+	options.Amount = milestone.Amount
+	return options
+}
+
+// AmountsOptionsFromWork calculates fees on the amount of a submitted work of an hourly contract
+func AmountsOptionsFromWork(contract models.Contract, amount float64, orgReferrer *models.Referring, userReferrer *models.Referring) AmountsOptions {
+	options := AmountsOptionsFromContract(contract, orgReferrer, userReferrer)
+	options.Amount = amount
+	return options
+}
+
+// CalculateAmounts prices the options in minor units of their currency, fees are rounded up
+// and referral shares down so the line items always sum up to the total.
+func CalculateAmounts(options AmountsOptions) *models.AmountsBreakdown {
+	policy := DefaultFeePolicy(options.IsVerified)
+	if options.Policy != nil {
+		policy = *options.Policy
+	}
+	orgFeeRate, userFeeRate := decimal(policy.OrgFee), decimal(policy.UserFee)
 
 	if options.OrgReferredWallet != nil && options.OrgFeeDiscount {
-		orgFeeRate *= REFERRED_ORG_FEE_DISCOUNT
+		orgFeeRate.Mul(orgFeeRate, decimal(policy.ReferredOrgFeeDiscount))
 	}
 
 	if options.UserReferredWallet != nil && options.UserFeeDiscount {
-		userFeeRate *= REFERRED_USER_FEE_DISCOUNT
+		userFeeRate.Mul(userFeeRate, decimal(policy.ReferredUserFeeDiscount))
 	}
 
-	amount := options.Amount
-	fee := amount * orgFeeRate
-
 	//rounding
-	round := *options.Round
-	if options.Round == nil || *options.Round < 1 {
-		*options.Round = 1
+	scale := int64(1)
+	if options.Round != nil && *options.Round >= 1 {
+		scale = int64(*options.Round)
 	}
 
-	stripeFee := 0.0
-	if models.PaymentService(options.Service) == models.PaymentServiceStripe {
-		stripeFee = (fee + options.Amount) * STRIPE_FEE
+	amount := int64(math.Round(options.Amount * float64(scale)))
+	feeExact := new(big.Rat).Mul(big.NewRat(amount, 1), orgFeeRate)
+	fee := ceil(feeExact)
+
+	stripeExact := new(big.Rat)
+	if options.Service == models.PaymentServiceStripe {
+		stripeExact.Add(big.NewRat(amount, 1), feeExact)
+		stripeExact.Mul(stripeExact, decimal(policy.StripeFee))
 	}
 
-	total := math.Ceil((amount+fee+stripeFee)*round) / round
-	payoutFee := amount * userFeeRate
+	// Total is rounded as a whole so the stripe fee takes the remainder of the rounding
+	total := ceil(new(big.Rat).Add(new(big.Rat).Add(big.NewRat(amount, 1), feeExact), stripeExact))
+	stripeFee := total - amount - fee
+
+	payoutFee := ceil(new(big.Rat).Mul(big.NewRat(amount, 1), userFeeRate))
 	payout := amount - payoutFee
 
 	//Referrings
 	orgReferredWallet, userReferredWallet := "", ""
+	orgShare, userShare := int64(0), int64(0)
 	if options.OrgReferredWallet != nil {
 		orgReferredWallet = *options.OrgReferredWallet
+		orgShare = floor(new(big.Rat).Mul(big.NewRat(fee, 1), decimal(options.OrgReferrerShare)))
 	}
 	if options.UserReferredWallet != nil {
 		userReferredWallet = *options.UserReferredWallet
+		userShare = floor(new(big.Rat).Mul(big.NewRat(payoutFee, 1), decimal(options.UserReferrerShare)))
+	}
+
+	//Policy version the amounts are priced with, empty for the built-in rates
+	feePolicyID, feePolicyVersion := "", 0
+	if options.Policy != nil {
+		feePolicyID, feePolicyVersion = options.Policy.ID.String(), options.Policy.Version
+	}
+
+	return &models.AmountsBreakdown{
+		Scale:     scale,
+		Amount:    amount,
+		Fee:       fee,
+		StripeFee: stripeFee,
+		Total:     total,
+		Payout:    payout,
+		PayoutFee: payoutFee,
+		AppFee:    fee + payoutFee,
+		LineItems: []models.AmountLineItem{
+			{Type: models.AmountLineItemPayout, Amount: payout},
+			{Type: models.AmountLineItemPayoutFee, Amount: payoutFee - userShare},
+			{Type: models.AmountLineItemUserReferralShare, Amount: userShare, Wallet: options.UserReferredWallet},
+			{Type: models.AmountLineItemPlatformFee, Amount: fee - orgShare},
+			{Type: models.AmountLineItemOrgReferralShare, Amount: orgShare, Wallet: options.OrgReferredWallet},
+			{Type: models.AmountLineItemStripeFee, Amount: stripeFee},
+		},
+		OrgReferrerWallet:  orgReferredWallet,
+		UserReferrerWallet: userReferredWallet,
+		OrgFeeDiscount:     options.OrgFeeDiscount,
+		UserFeeDiscount:    options.UserFeeDiscount,
+		FeePolicyID:        feePolicyID,
+		FeePolicyVersion:   feePolicyVersion,
 	}
+}
+
+// decimal converts the rate exactly as written, 0.03 is 3/100 rather than its binary approximation
+func decimal(rate float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	return r
+}
+
+func ceil(r *big.Rat) int64 {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q.Int64()
+}
 
-	return map[string]any{
-		"amount":               amount,
-		"fee":                  fee,
-		"stripe_fee":           stripeFee,
-		"total":                total,
-		"payout":               payout,
-		"app_fee":              fee + payoutFee,
-		"org_referrer_wallet":  orgReferredWallet,
-		"user_referrer_wallet": userReferredWallet,
-		"org_fee_discount":     options.OrgFeeDiscount,
-		"user_fee_discount":    options.UserFeeDiscount,
+func floor(r *big.Rat) int64 {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() < 0 {
+		q.Sub(q, big.NewInt(1))
 	}
+	return q.Int64()
 }
@@ -0,0 +1,256 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"os"
+	"socious/src/apps/models"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	contractLineDesc     = "font:Helvetica, points:10, rot:0, pos:tl, offset:50 %d, scalefactor:1 abs, color:#000000"
+	contractTitleDesc    = "font:Helvetica-Bold, points:16, rot:0, pos:tl, offset:50 -60, scalefactor:1 abs, color:#000000"
+	contractQRDesc       = "rot:0, scale:.15 abs, pos:br, offset:-40 40"
+	contractLineFont     = "Helvetica"
+	contractLineSize     = 10
+	contractLineHeight   = 16
+	contractFirstLine    = -100
+	contractMargin       = 50
+	contractBottomMargin = 140 // keeps the lines clear of the QR code
+)
+
+// pdfStamp is a watermark stamped on a page of the pdf, counted from 1
+type pdfStamp struct {
+	page      int
+	watermark *model.Watermark
+}
+
+// ContractPdfParams is everything stamped on the contract agreement template
+type ContractPdfParams struct {
+	Contract   models.Contract
+	Amounts    *models.AmountsBreakdown
+	Signatures []models.ContractSignature
+	VerifyURL  string
+}
+
+// ContractPdf stamps the agreement details and a QR code of the verification url on the template pdf
+func ContractPdf(templatePath string, params ContractPdfParams) ([]byte, error) {
+	template, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read contract template: %v", err)
+	}
+
+	qr, err := qrcode.New(params.VerifyURL, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	var qrBuf bytes.Buffer
+	if err := png.Encode(&qrBuf, qr.Image(500)); err != nil {
+		return nil, err
+	}
+
+	conf := model.NewDefaultConfiguration()
+	onTop, update := true, false
+
+	qrWm, err := api.ImageWatermarkForReader(&qrBuf, contractQRDesc, onTop, update, types.POINTS)
+	if err != nil {
+		return nil, err
+	}
+	dim, err := pdfPageDim(template, conf)
+	if err != nil {
+		return nil, err
+	}
+	stamps, err := textStamps(dim, params.Contract.Name, contractPdfLines(params))
+	if err != nil {
+		return nil, err
+	}
+	return stampPdf(template, append([]pdfStamp{{page: 1, watermark: qrWm}}, stamps...), conf)
+}
+
+// pdfPageDim returns the size of the first page of the pdf
+func pdfPageDim(pdf []byte, conf *model.Configuration) (types.Dim, error) {
+	dims, err := api.PageDims(bytes.NewReader(pdf), conf)
+	if err != nil {
+		return types.Dim{}, err
+	}
+	if len(dims) < 1 {
+		return types.Dim{}, fmt.Errorf("pdf template has no pages")
+	}
+	return dims[0], nil
+}
+
+// textStamps lays the title and the lines out from the top left of the first page, lines are wrapped to
+// the width of the page and run over to the next pages once the page is full
+func textStamps(dim types.Dim, title string, lines []string) ([]pdfStamp, error) {
+	onTop, update := true, false
+
+	stamps := []pdfStamp{}
+	if title != "" {
+		titleWm, err := api.TextWatermark(title, contractTitleDesc, onTop, update, types.POINTS)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, pdfStamp{page: 1, watermark: titleWm})
+	}
+
+	width := dim.Width - 2*contractMargin
+	perPage := int((dim.Height-contractBottomMargin+contractFirstLine)/contractLineHeight) + 1
+	if perPage < 1 {
+		perPage = 1
+	}
+
+	wrapped := []string{}
+	for _, line := range lines {
+		wrapped = append(wrapped, wrapPdfLine(line, width)...)
+	}
+	for i, line := range wrapped {
+		// Empty lines only keep the spacing between sections
+		if line == "" {
+			continue
+		}
+		desc := fmt.Sprintf(contractLineDesc, contractFirstLine-(i%perPage)*contractLineHeight)
+		wm, err := api.TextWatermark(line, desc, onTop, update, types.POINTS)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, pdfStamp{page: i/perPage + 1, watermark: wm})
+	}
+	return stamps, nil
+}
+
+// wrapPdfLine breaks the line into lines fitting the width, wrapped lines keep the indentation of the line
+// and words wider than the line are broken apart
+func wrapPdfLine(line string, width float64) []string {
+	text := strings.TrimLeft(line, " ")
+	if text == "" {
+		return []string{line}
+	}
+	indent := line[:len(line)-len(text)]
+	fits := func(s string) bool {
+		return font.TextWidth(s, contractLineFont, contractLineSize) <= width
+	}
+
+	lines := []string{}
+	current := indent
+	for _, word := range strings.Split(text, " ") {
+		candidate := current + word
+		if current != indent {
+			candidate = current + " " + word
+		}
+		if fits(candidate) {
+			current = candidate
+			continue
+		}
+		if current != indent {
+			lines = append(lines, current)
+			current = indent
+		}
+		for _, r := range word {
+			if !fits(current+string(r)) && current != indent {
+				lines = append(lines, current)
+				current = indent
+			}
+			current += string(r)
+		}
+	}
+	return append(lines, current)
+}
+
+// stampPdf adds the stamps on their pages of the pdf, blank pages the size of the last one are added
+// for the stamps past the pages of the pdf
+func stampPdf(pdf []byte, stamps []pdfStamp, conf *model.Configuration) ([]byte, error) {
+	pages := 1
+	for _, s := range stamps {
+		if s.page > pages {
+			pages = s.page
+		}
+	}
+	count, err := api.PageCount(bytes.NewReader(pdf), conf)
+	if err != nil {
+		return nil, err
+	}
+	for ; count < pages; count++ {
+		var out bytes.Buffer
+		if err := api.InsertPages(bytes.NewReader(pdf), &out, []string{strconv.Itoa(count)}, false, nil, conf); err != nil {
+			return nil, err
+		}
+		pdf = out.Bytes()
+	}
+
+	for _, s := range stamps {
+		var out bytes.Buffer
+		if err := api.AddWatermarks(bytes.NewReader(pdf), &out, []string{strconv.Itoa(s.page)}, s.watermark, conf); err != nil {
+			return nil, err
+		}
+		pdf = out.Bytes()
+	}
+	return pdf, nil
+}
+
+func contractPdfLines(params ContractPdfParams) []string {
+	contract, amounts := params.Contract, params.Amounts
+	currency := ""
+	if contract.Currency != nil {
+		currency = string(*contract.Currency)
+	}
+	if contract.PaymentType != nil && *contract.PaymentType == models.PaymentModeTypeCrypto && contract.CryptoCurrency != nil {
+		currency = *contract.CryptoCurrency
+	}
+
+	lines := []string{
+		fmt.Sprintf("Contract: %s", contract.ID),
+		fmt.Sprintf("Type: %s    Status: %s", contract.Type, contract.Status),
+		fmt.Sprintf("Provider: %s", identityName(contract.Provider)),
+		fmt.Sprintf("Client: %s", identityName(contract.Client)),
+		"",
+		fmt.Sprintf("Commitment: %d hours, %d x %s", contract.Commitment, contract.CommitmentPeriodCount, contract.CommitmentPeriod),
+		fmt.Sprintf("Amount: %v %s", amounts.Major(amounts.Amount), currency),
+		fmt.Sprintf("Fee: %v %s", amounts.Major(amounts.Fee), currency),
+		fmt.Sprintf("Total: %v %s", amounts.Major(amounts.Total), currency),
+		fmt.Sprintf("Payout: %v %s", amounts.Major(amounts.Payout), currency),
+		"",
+	}
+
+	if contract.RequirementDescription != nil {
+		lines = append(lines, fmt.Sprintf("Requirements: %s", *contract.RequirementDescription))
+	}
+	files := []struct {
+		Filename string `json:"filename"`
+	}{}
+	if err := contract.RequirementFilesJson.Unmarshal(&files); err == nil {
+		for _, f := range files {
+			lines = append(lines, fmt.Sprintf("  - %s", f.Filename))
+		}
+	}
+	lines = append(lines, "")
+
+	if len(params.Signatures) < 1 {
+		lines = append(lines, "Not signed")
+	}
+	for _, s := range params.Signatures {
+		lines = append(lines, fmt.Sprintf("Signed by %s at %s", identityName(s.Identity), s.CreatedAt.Format("2006-01-02 15:04 MST")))
+		lines = append(lines, fmt.Sprintf("  SHA-256: %s", s.Hash))
+	}
+	return lines
+}
+
+func identityName(identity *models.Identity) string {
+	if identity == nil {
+		return ""
+	}
+	for _, key := range []string{"name", "username", "shortname"} {
+		if name, ok := identity.MetaMap[key].(string); ok && name != "" {
+			return name
+		}
+	}
+	return identity.ID.String()
+}
@@ -0,0 +1,262 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"socious/src/apps/models"
+	"socious/src/config"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Time rates are served from the cache when the rates config is missing
+const RATES_TTL = 60 * time.Minute
+
+var ErrRateNotFound = errors.New("rate not found")
+
+// RateSource returns the rates of the quote currencies (or tokens) for one unit of the base,
+// bases the source doesn't know return no rates.
+type RateSource interface {
+	Name() string
+	Rates(ctx context.Context, base string) (map[string]float64, error)
+}
+
+// fileRateSource reads the rates from a yaml (or json) file of rates by base then quote
+type fileRateSource struct {
+	path string
+}
+
+func NewFileRateSource(path string) RateSource {
+	return &fileRateSource{path: path}
+}
+
+func (s *fileRateSource) Name() string {
+	return "file"
+}
+
+func (s *fileRateSource) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	rates := map[string]map[string]float64{}
+	if err := yaml.Unmarshal(content, &rates); err != nil {
+		return nil, fmt.Errorf("invalid rates file %s: %v", s.path, err)
+	}
+	for b, quotes := range rates {
+		if strings.EqualFold(b, base) {
+			return quotes, nil
+		}
+	}
+	return map[string]float64{}, nil
+}
+
+// httpRateSource requests the rates of the base as {"base": "USD", "rates": {"JPY": 150.1}}
+type httpRateSource struct {
+	url    string
+	apiKey string
+}
+
+func NewHTTPRateSource(url, apiKey string) RateSource {
+	return &httpRateSource{url: url, apiKey: apiKey}
+}
+
+func (s *httpRateSource) Name() string {
+	return "http"
+}
+
+func (s *httpRateSource) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	query := url.Values{}
+	query.Set("base", base)
+	if s.apiKey != "" {
+		query.Set("apikey", s.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s?%s", s.url, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rates source responded %s", resp.Status)
+	}
+
+	var response struct {
+		Base  string             `json:"base"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	if response.Base != "" && !strings.EqualFold(response.Base, base) {
+		return nil, fmt.Errorf("rates source responded rates of %s instead of %s", response.Base, base)
+	}
+	if response.Rates == nil {
+		return map[string]float64{}, nil
+	}
+	return response.Rates, nil
+}
+
+var rateSource RateSource
+
+// SetRateSource replaces the source rates are fetched from, nil falls back to the configured one
+func SetRateSource(source RateSource) {
+	rateSource = source
+}
+
+// NewRateSource returns the source of the rates config
+func NewRateSource() (RateSource, error) {
+	conf := config.Config.Rates
+	switch conf.Source {
+	case "http":
+		if conf.Url == "" {
+			return nil, fmt.Errorf("rates url is not configured")
+		}
+		return NewHTTPRateSource(conf.Url, conf.ApiKey), nil
+	case "file", "":
+		if conf.File == "" {
+			return nil, fmt.Errorf("rates file is not configured")
+		}
+		return NewFileRateSource(conf.File), nil
+	default:
+		return nil, fmt.Errorf("rates source %s is not supported", conf.Source)
+	}
+}
+
+func ratesTTL() time.Duration {
+	if config.Config.Rates.Ttl < 1 {
+		return RATES_TTL
+	}
+	return time.Duration(config.Config.Rates.Ttl) * time.Minute
+}
+
+// ValidRateBase reports whether rates of the base can be asked for, bases are the currencies
+// contracts are priced in and the symbols of the configured chain tokens.
+func ValidRateBase(base string) bool {
+	if models.Currency(strings.ToUpper(base)).Valid() {
+		return true
+	}
+	for _, chain := range config.Config.Payment.Chains {
+		for _, token := range chain.Tokens {
+			if strings.EqualFold(token.Symbol, base) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetRates returns the rates of the base, they're fetched from the source again once the cached ones expire
+func GetRates(ctx context.Context, base string) ([]models.CurrencyRate, error) {
+	base = strings.ToUpper(base)
+	cached, err := models.GetCurrencyRates(base)
+	if err != nil {
+		return nil, err
+	}
+	if len(cached) > 0 && time.Since(oldestRate(cached)) < ratesTTL() {
+		return cached, nil
+	}
+
+	source := rateSource
+	if source == nil {
+		if source, err = NewRateSource(); err != nil {
+			return nil, err
+		}
+	}
+	quotes, err := source.Rates(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	rates := []models.CurrencyRate{}
+	for quote, rate := range quotes {
+		if rate <= 0 {
+			continue
+		}
+		rates = append(rates, models.CurrencyRate{
+			Base:      base,
+			Quote:     strings.ToUpper(quote),
+			Rate:      rate,
+			Source:    source.Name(),
+			FetchedAt: now,
+		})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Quote < rates[j].Quote })
+
+	if err := models.SaveCurrencyRates(ctx, rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+func oldestRate(rates []models.CurrencyRate) time.Time {
+	oldest := rates[0].FetchedAt
+	for _, r := range rates[1:] {
+		if r.FetchedAt.Before(oldest) {
+			oldest = r.FetchedAt
+		}
+	}
+	return oldest
+}
+
+// GetRate returns the rate of the quote for one unit of the base, inverting the rate of the base
+// for one unit of the quote when the source only knows that one (as prices of tokens).
+func GetRate(ctx context.Context, base, quote string) (*models.CurrencyRate, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if base == quote {
+		return &models.CurrencyRate{Base: base, Quote: quote, Rate: 1, FetchedAt: time.Now().UTC()}, nil
+	}
+
+	rates, err := GetRates(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rates {
+		if r.Quote == quote {
+			return &r, nil
+		}
+	}
+
+	inverse, err := GetRates(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range inverse {
+		if r.Quote == base {
+			return &models.CurrencyRate{
+				Base:      base,
+				Quote:     quote,
+				Rate:      1 / r.Rate,
+				Source:    r.Source,
+				FetchedAt: r.FetchedAt,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+}
+
+// TokenAmount converts the amount to the token priced at the rate, truncated to the decimals
+// of the token (up to 9 as amounts are floats) so the payer can transfer it exactly.
+func TokenAmount(amount, rate float64, decimals int) float64 {
+	tokens := amount / rate
+	if decimals > 0 {
+		scale := math.Pow10(min(decimals, 9))
+		// Float noise must not truncate an exact amount a unit down
+		tokens = math.Floor(tokens*scale*(1+1e-12)) / scale
+	}
+	return tokens
+}
@@ -0,0 +1,44 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"socious/src/apps/models"
+	"socious/src/config"
+	"strings"
+
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/transfer"
+)
+
+// TransferReferralPayout transfers the fiat payout to the Stripe account of the referrer and returns the transfer id,
+// the transfer is keyed by the payout so retrying a payout not marked paid yet never transfers it twice.
+func TransferReferralPayout(payout models.ReferralPayout) (string, error) {
+	if payout.FiatService == nil {
+		return "", fmt.Errorf("payout %s is not in fiat", payout.ID)
+	}
+
+	var apiKey string
+	for _, f := range config.Config.Payment.Fiats {
+		if f.Name == *payout.FiatService {
+			apiKey = f.ApiKey
+		}
+	}
+	if apiKey == "" {
+		return "", fmt.Errorf("fiat service %s is not configured", *payout.FiatService)
+	}
+
+	currency := models.Currency(payout.Currency)
+	params := &stripe.TransferParams{
+		Amount:      stripe.Int64(int64(math.Round(payout.Amount * math.Pow10(currency.Decimals())))),
+		Currency:    stripe.String(strings.ToLower(payout.Currency)),
+		Destination: stripe.String(payout.Address),
+	}
+	params.SetIdempotencyKey(fmt.Sprintf("referral-payout-%s", payout.ID))
+
+	t, err := (&transfer.Client{B: stripe.GetBackend(stripe.APIBackend), Key: apiKey}).New(params)
+	if err != nil {
+		return "", err
+	}
+	return t.ID, nil
+}
@@ -0,0 +1,250 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"socious/src/apps/models"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/fxamacker/cbor/v2"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// Time a wallet challenge can be signed within
+const WALLET_CHALLENGE_TTL = 10 * time.Minute
+
+// NewWalletChallenge returns the challenge the user signs to prove owning the wallet address
+func NewWalletChallenge(wallet models.Wallet) (*models.WalletChallenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	challenge := &models.WalletChallenge{
+		UserID:     wallet.UserID,
+		IdentityID: wallet.IdentityID,
+		Address:    wallet.Address,
+		Network:    wallet.Network,
+		Testnet:    wallet.Testnet,
+		ExpiresAt:  now.Add(WALLET_CHALLENGE_TTL),
+	}
+	challenge.Message = fmt.Sprintf(
+		"Socious wants you to prove you own this wallet.\n\nAddress: %s\nNetwork: %s\nNonce: %s\nIssued At: %s\nExpires At: %s",
+		wallet.Address,
+		wallet.Network,
+		hex.EncodeToString(nonce),
+		now.Format(time.RFC3339),
+		challenge.ExpiresAt.Format(time.RFC3339),
+	)
+	return challenge, nil
+}
+
+// VerifyWalletSignature checks the message has been signed by the address, with EIP-191 on EVM networks
+// and CIP-8 on cardano where the key is the COSE key returned along with the signature.
+func VerifyWalletSignature(network models.WalletNetwork, address, message, signature string, key *string) error {
+	switch network {
+	case models.WalletNetworkBSC, models.WalletNetworkSepolia:
+		return verifyEIP191(address, message, signature)
+	case models.WalletNetworkCardano:
+		if key == nil {
+			return fmt.Errorf("key is required to verify cardano signatures")
+		}
+		return verifyCIP8(address, message, signature, *key)
+	default:
+		return fmt.Errorf("wallet signatures on %s are not supported", network)
+	}
+}
+
+func verifyEIP191(address, message, signature string) error {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil || len(sig) != 65 {
+		return fmt.Errorf("invalid signature")
+	}
+	// Wallets sign with recovery id 27/28, some with 0/1
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return fmt.Errorf("invalid signature recovery id")
+	}
+
+	prefixed := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
+	compact := append([]byte{27 + v}, sig[:64]...)
+	pub, _, err := ecdsa.RecoverCompact(compact, keccak256([]byte(prefixed)))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+
+	signer := "0x" + hex.EncodeToString(keccak256(pub.SerializeUncompressed()[1:])[12:])
+	if !strings.EqualFold(signer, address) {
+		return fmt.Errorf("message is signed by %s instead of %s", signer, address)
+	}
+	return nil
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// coseSign1 is the COSE_Sign1 structure of CIP-8 signatures
+type coseSign1 struct {
+	_           struct{} `cbor:",toarray"`
+	Protected   []byte
+	Unprotected map[interface{}]interface{}
+	Payload     []byte
+	Signature   []byte
+}
+
+func verifyCIP8(address, message, signature, key string) error {
+	rawSign, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature")
+	}
+	sign := new(coseSign1)
+	if err := cbor.Unmarshal(rawSign, sign); err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+
+	rawKey, err := hex.DecodeString(key)
+	if err != nil {
+		return fmt.Errorf("invalid key")
+	}
+	coseKey := map[int]interface{}{}
+	if err := cbor.Unmarshal(rawKey, &coseKey); err != nil {
+		return fmt.Errorf("invalid key: %v", err)
+	}
+	pub, ok := coseKey[-2].([]byte)
+	if !ok || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid key")
+	}
+
+	// Sig_structure of RFC 8152 with no external data
+	sigStructure, err := cbor.Marshal([]interface{}{"Signature1", sign.Protected, []byte{}, sign.Payload})
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, sigStructure, sign.Signature) {
+		return fmt.Errorf("invalid signature")
+	}
+
+	payload := []byte(message)
+	if hashed, _ := sign.Unprotected["hashed"].(bool); hashed {
+		payload = blake2b224(payload)
+	}
+	if !bytes.Equal(sign.Payload, payload) {
+		return fmt.Errorf("signed message does not match the challenge")
+	}
+
+	protected := map[interface{}]interface{}{}
+	if err := cbor.Unmarshal(sign.Protected, &protected); err != nil {
+		return fmt.Errorf("invalid signature headers: %v", err)
+	}
+	signer, _ := protected["address"].([]byte)
+	addr, err := cardanoAddressBytes(address)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(signer, addr) {
+		return fmt.Errorf("message is signed by another address")
+	}
+	// The payment (or stake) credential of the address is the hash of the signing key
+	if len(addr) < 29 || !bytes.Equal(addr[1:29], blake2b224(pub)) {
+		return fmt.Errorf("message is not signed by the key of %s", address)
+	}
+	return nil
+}
+
+func blake2b224(data []byte) []byte {
+	h, _ := blake2b.New(28, nil)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// cardanoAddressBytes decodes bech32 (addr1..., stake1...) or hex cardano addresses
+func cardanoAddressBytes(address string) ([]byte, error) {
+	if b, err := hex.DecodeString(address); err == nil {
+		return b, nil
+	}
+	_, data, err := bech32Decode(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cardano address: %v", err)
+	}
+	return data, nil
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Decode decodes and verifies a bech32 string, without the 90 characters limit cardano addresses exceed
+func bech32Decode(s string) (string, []byte, error) {
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, fmt.Errorf("invalid bech32 separator")
+	}
+	hrp := s[:sep]
+
+	values := make([]byte, 0, len(s)-sep-1)
+	for _, c := range s[sep+1:] {
+		i := strings.IndexRune(bech32Charset, c)
+		if i < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 character %q", c)
+		}
+		values = append(values, byte(i))
+	}
+
+	expanded := make([]byte, 0, len(hrp)*2+1+len(values))
+	for _, c := range hrp {
+		expanded = append(expanded, byte(c>>5))
+	}
+	expanded = append(expanded, 0)
+	for _, c := range hrp {
+		expanded = append(expanded, byte(c&31))
+	}
+	if bech32Polymod(append(expanded, values...)) != 1 {
+		return "", nil, fmt.Errorf("invalid bech32 checksum")
+	}
+
+	// Regroup the 5 bits values without the checksum to bytes
+	var (
+		data []byte
+		acc  uint
+		bits uint
+	)
+	for _, v := range values[:len(values)-6] {
+		acc = acc<<5 | uint(v)
+		bits += 5
+		for bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return "", nil, fmt.Errorf("invalid bech32 padding")
+	}
+	return hrp, data, nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type AmountLineItemType string
+
+const (
+	AmountLineItemPayout            AmountLineItemType = "PAYOUT"
+	AmountLineItemPlatformFee       AmountLineItemType = "PLATFORM_FEE"
+	AmountLineItemPayoutFee         AmountLineItemType = "PAYOUT_FEE"
+	AmountLineItemStripeFee         AmountLineItemType = "STRIPE_FEE"
+	AmountLineItemOrgReferralShare  AmountLineItemType = "ORG_REFERRAL_SHARE"
+	AmountLineItemUserReferralShare AmountLineItemType = "USER_REFERRAL_SHARE"
+)
+
+// AmountLineItem is a part of the total paid, in minor units
+type AmountLineItem struct {
+	Type   AmountLineItemType
+	Amount int64
+	Wallet *string
+}
+
+// AmountsBreakdown holds the amounts of a payment in minor units of its currency,
+// Scale is the count of minor units in one unit (1 for JPY, 100 for USD).
+type AmountsBreakdown struct {
+	Scale     int64
+	Amount    int64
+	Fee       int64
+	StripeFee int64
+	Total     int64
+	Payout    int64
+	PayoutFee int64
+	AppFee    int64
+	LineItems []AmountLineItem
+
+	OrgReferrerWallet  string
+	UserReferrerWallet string
+	OrgFeeDiscount     bool
+	UserFeeDiscount    bool
+
+	FeePolicyID      string
+	FeePolicyVersion int
+}
+
+// Major converts minor units of the breakdown currency to units
+func (b AmountsBreakdown) Major(minor int64) float64 {
+	if b.Scale < 1 {
+		return float64(minor)
+	}
+	return float64(minor) / float64(b.Scale)
+}
+
+// Check verifies the line items sum up to the total and the payout with its fee to the amount
+func (b AmountsBreakdown) Check() error {
+	var sum int64
+	for _, item := range b.LineItems {
+		if item.Amount < 0 {
+			return fmt.Errorf("negative %s line item: %d", item.Type, item.Amount)
+		}
+		sum += item.Amount
+	}
+	if sum != b.Total {
+		return fmt.Errorf("line items sum %d does not match total %d", sum, b.Total)
+	}
+	if b.Amount+b.Fee+b.StripeFee != b.Total {
+		return fmt.Errorf("amount %d with fees %d and %d does not match total %d", b.Amount, b.Fee, b.StripeFee, b.Total)
+	}
+	if b.Payout+b.PayoutFee != b.Amount {
+		return fmt.Errorf("payout %d with fee %d does not match amount %d", b.Payout, b.PayoutFee, b.Amount)
+	}
+	return nil
+}
+
+// MarshalJSON renders the amounts in units as they have been served before the breakdown was typed
+func (b AmountsBreakdown) MarshalJSON() ([]byte, error) {
+	items := make([]map[string]any, len(b.LineItems))
+	for i, item := range b.LineItems {
+		items[i] = map[string]any{
+			"type":   item.Type,
+			"amount": b.Major(item.Amount),
+			"wallet": item.Wallet,
+		}
+	}
+
+	return json.Marshal(map[string]any{
+		"amount":               b.Major(b.Amount),
+		"fee":                  b.Major(b.Fee),
+		"stripe_fee":           b.Major(b.StripeFee),
+		"total":                b.Major(b.Total),
+		"payout":               b.Major(b.Payout),
+		"payout_fee":           b.Major(b.PayoutFee),
+		"app_fee":              b.Major(b.AppFee),
+		"scale":                b.Scale,
+		"line_items":           items,
+		"org_referrer_wallet":  b.OrgReferrerWallet,
+		"user_referrer_wallet": b.UserReferrerWallet,
+		"org_fee_discount":     b.OrgFeeDiscount,
+		"user_fee_discount":    b.UserFeeDiscount,
+		"fee_policy_id":        b.FeePolicyID,
+		"fee_policy_version":   b.FeePolicyVersion,
+	})
+}
@@ -0,0 +1,266 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+	database "github.com/socious-io/pkg_database"
+)
+
+// Applicant is the application of a user to a job project, the project owner shortlists,
+// rejects or hires the applicant.
+type Applicant struct {
+	ID               uuid.UUID       `db:"id" json:"id"`
+	ProjectID        uuid.UUID       `db:"project_id" json:"project_id"`
+	UserID           uuid.UUID       `db:"user_id" json:"user_id"`
+	CoverLetter      *string         `db:"cover_letter" json:"cover_letter"`
+	PaymentRate      *int            `db:"payment_rate" json:"payment_rate"`
+	PaymentType      *PaymentType    `db:"payment_type" json:"payment_type"`
+	OfferRate        *float64        `db:"offer_rate" json:"offer_rate"`
+	OfferMessage     *string         `db:"offer_message" json:"offer_message"`
+	Feedback         *string         `db:"feedback" json:"feedback"`
+	Status           ApplicantStatus `db:"status" json:"status"`
+	CvLink           *string         `db:"cv_link" json:"cv_link"`
+	CvName           *string         `db:"cv_name" json:"cv_name"`
+	ShareContactInfo *bool           `db:"share_contact_info" json:"share_contact_info"`
+	Attachment       *uuid.UUID      `db:"attachment" json:"-"`
+	OldId            *int            `db:"old_id" json:"-"`
+	SearchTsv        *string         `db:"search_tsv" json:"-"`
+
+	User        *Identity `db:"-" json:"user"`
+	Attachments []Media   `db:"-" json:"attachments"`
+	Answers     []Answer  `db:"-" json:"answers"`
+
+	UserJson        types.JSONText `db:"user" json:"-"`
+	AttachmentsJson types.JSONText `db:"attachments" json:"-"`
+	AnswersJson     types.JSONText `db:"answers" json:"-"`
+
+	ClosedAt  *time.Time `db:"closed_at" json:"closed_at"`
+	DeletedAt *time.Time `db:"deleted_at" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Answer of the applicant to a screening question of the project
+type Answer struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	ProjectID      uuid.UUID `db:"project_id" json:"-"`
+	QuestionID     uuid.UUID `db:"question_id" json:"question_id"`
+	ApplicantID    uuid.UUID `db:"applicant_id" json:"-"`
+	Answer         *string   `db:"answer" json:"answer"`
+	SelectedOption *int      `db:"selected_option" json:"selected_option"`
+
+	Question string  `db:"-" json:"question"`
+	Option   *string `db:"-" json:"option"`
+}
+
+type ApplicantAttachment struct {
+	ApplicantID uuid.UUID `db:"applicant_id"`
+	MediaID     uuid.UUID `db:"media_id"`
+}
+
+func (Applicant) TableName() string {
+	return "applicants"
+}
+
+func (Applicant) FetchQuery() string {
+	return "applicants/fetch"
+}
+
+// Statuses the applicant can be moved to from each status
+var applicantTransitions = map[ApplicantStatus][]ApplicantStatus{
+	ApplicantStatusPending:     {ApplicantStatusShortlisted, ApplicantStatusRejected, ApplicantStatusHired},
+	ApplicantStatusShortlisted: {ApplicantStatusRejected, ApplicantStatusHired},
+}
+
+// Create applies the user to the project with the attachments and the answers to its questions.
+func (a *Applicant) Create(ctx context.Context, attachments []uuid.UUID, answers []Answer) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(
+		ctx,
+		tx,
+		"applicants/create",
+		a.ProjectID,
+		a.UserID,
+		a.CoverLetter,
+		a.PaymentRate,
+		a.PaymentType,
+		a.CvLink,
+		a.CvName,
+		a.ShareContactInfo,
+	)
+	if err != nil {
+		tx.Rollback()
+		if strings.Contains(err.Error(), "idx_applied") {
+			return fmt.Errorf("already applied to the project")
+		}
+		return err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(a); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	rows.Close()
+
+	attachmentsData := []ApplicantAttachment{}
+	for _, attachment := range attachments {
+		attachmentsData = append(attachmentsData, ApplicantAttachment{ApplicantID: a.ID, MediaID: attachment})
+	}
+	if len(attachmentsData) > 0 {
+		if _, err := database.TxExecuteQuery(tx, "applicants/create_attachments", attachmentsData); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for i := range answers {
+		answers[i].ProjectID = a.ProjectID
+		answers[i].ApplicantID = a.ID
+	}
+	if len(answers) > 0 {
+		if _, err := database.TxExecuteQuery(tx, "applicants/create_answers", answers); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return database.Fetch(a, a.ID)
+}
+
+// Hire closes the applicant as hired and creates its contract in one transaction, applicants
+// hired or closed meanwhile are not hired again.
+func (a *Applicant) Hire(ctx context.Context, contract *Contract) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "applicants/hire", a.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	hired := false
+	for rows.Next() {
+		if err := rows.StructScan(a); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		hired = true
+	}
+	rows.Close()
+	if !hired {
+		tx.Rollback()
+		return fmt.Errorf("applicant can not be hired anymore")
+	}
+
+	rows, err = database.TxQuery(ctx, tx, "contracts/create", contract.createParams()...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(contract); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if err := database.Fetch(contract, contract.ID); err != nil {
+		return err
+	}
+	return database.Fetch(a, a.ID)
+}
+
+// UpdateStatus moves the applicant to the status, rejected and hired applicants are closed.
+func (a *Applicant) UpdateStatus(ctx context.Context, status ApplicantStatus, feedback *string) error {
+	allowed := false
+	for _, s := range applicantTransitions[a.Status] {
+		if s == status {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%s applicant can not be %s", strings.ToLower(string(a.Status)), strings.ToLower(string(status)))
+	}
+
+	var closedAt *time.Time
+	if status == ApplicantStatusRejected || status == ApplicantStatusHired {
+		now := time.Now()
+		closedAt = &now
+	}
+
+	rows, err := database.Query(ctx, "applicants/update_status", a.ID, status, feedback, closedAt)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(a); err != nil {
+			return err
+		}
+	}
+	rows.Close()
+	return database.Fetch(a, a.ID)
+}
+
+func GetApplicant(id uuid.UUID) (*Applicant, error) {
+	a := new(Applicant)
+	if err := database.Fetch(a, id); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func GetApplicants(projectID uuid.UUID, p database.Paginate) ([]Applicant, int, error) {
+	var (
+		applicants = []Applicant{}
+		fetchList  []database.FetchList
+		ids        []interface{}
+	)
+
+	status := []string{}
+	for _, filter := range p.Filters {
+		if filter.Key == "status" {
+			status = strings.Split(strings.ToUpper(filter.Value), ",")
+		}
+	}
+
+	if err := database.QuerySelect("applicants/get", &fetchList, projectID, p.Limit, p.Offet, pq.Array(status)); err != nil {
+		return nil, 0, err
+	}
+
+	if len(fetchList) < 1 {
+		return applicants, 0, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&applicants, ids...); err != nil {
+		return nil, 0, err
+	}
+	return applicants, fetchList[0].TotalCount, nil
+}
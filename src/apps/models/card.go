@@ -1,6 +1,8 @@
 package models
 
 import (
+	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,14 +10,21 @@ import (
 	database "github.com/socious-io/pkg_database"
 )
 
+// Card is saved on the fiat service for an identity, the default card is the one
+// its deposits are charged on when no card is chosen.
 type Card struct {
-	ID         uuid.UUID      `db:"id" json:"id"`
-	IdentityId uuid.UUID      `db:"identity_id" json:"identity_id"`
-	HolderName *string        `db:"holder_name" json:"holder_name"`
-	Brand      *string        `db:"brand" json:"brand"`
-	Meta       types.JSONText `db:"meta" json:"meta"`
-	Customer   *string        `db:"customer" json:"customer"`
-	IsJp       bool           `db:"is_jp" json:"is_jp"`
+	ID         uuid.UUID              `db:"id" json:"id"`
+	IdentityId uuid.UUID              `db:"identity_id" json:"identity_id"`
+	HolderName *string                `db:"holder_name" json:"holder_name"`
+	Brand      *string                `db:"brand" json:"brand"`
+	Last4      *string                `db:"-" json:"last4"`
+	ExpMonth   *int64                 `db:"-" json:"exp_month"`
+	ExpYear    *int64                 `db:"-" json:"exp_year"`
+	MetaMap    map[string]interface{} `db:"-" json:"meta"`
+	Meta       types.JSONText         `db:"meta" json:"-"`
+	Customer   *string                `db:"customer" json:"customer"`
+	IsJp       bool                   `db:"is_jp" json:"is_jp"`
+	Default    bool                   `db:"is_default" json:"default"`
 
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
@@ -29,11 +38,147 @@ func (Card) FetchQuery() string {
 	return "cards/fetch"
 }
 
+// loadMeta fills the card details in from the payment method kept as meta,
+// older cards keep the details on top of the meta instead of under "card".
+func (c *Card) loadMeta() {
+	c.MetaMap = nil
+	if len(c.Meta) > 0 {
+		json.Unmarshal(c.Meta, &c.MetaMap)
+	}
+	details := c.MetaMap
+	if card, ok := c.MetaMap["card"].(map[string]interface{}); ok {
+		details = card
+	}
+
+	if v, ok := details["last4"].(string); ok && v != "" {
+		c.Last4 = &v
+	}
+	if v, ok := details["exp_month"].(float64); ok {
+		month := int64(v)
+		c.ExpMonth = &month
+	}
+	if v, ok := details["exp_year"].(float64); ok {
+		year := int64(v)
+		c.ExpYear = &year
+	}
+	if v, ok := details["brand"].(string); ok && v != "" && c.Brand == nil {
+		c.Brand = &v
+	}
+}
+
+// PaymentMethodID returns the id of the payment method of the card on the fiat service
+func (c Card) PaymentMethodID() string {
+	id, _ := c.MetaMap["id"].(string)
+	return id
+}
+
+// Create saves the card, the first card of the identity becomes its default one.
+func (c *Card) Create(ctx context.Context) error {
+	rows, err := database.Query(
+		ctx,
+		"cards/create",
+		c.IdentityId,
+		c.HolderName,
+		c.Brand,
+		c.Meta,
+		c.Customer,
+		c.IsJp,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(c); err != nil {
+			return err
+		}
+	}
+	c.loadMeta()
+	return nil
+}
+
+// Delete removes the card, the latest other card of the identity becomes default in place of a default one.
+func (c *Card) Delete(ctx context.Context) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "cards/delete", c.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rows.Close()
+
+	if c.Default {
+		rows, err = database.TxQuery(ctx, tx, "cards/promote", c.IdentityId)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		rows.Close()
+	}
+	return tx.Commit()
+}
+
+// SetDefault makes the card the default one of the identity.
+func (c *Card) SetDefault(ctx context.Context) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "cards/unset_default", c.IdentityId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rows.Close()
+
+	rows, err = database.TxQuery(ctx, tx, "cards/set_default", c.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(c); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	rows.Close()
+	c.loadMeta()
+	return tx.Commit()
+}
+
 func GetCard(id uuid.UUID, identityId uuid.UUID) (*Card, error) {
 	c := new(Card)
 	if err := database.Get(c, "cards/fetch_by_identity", id, identityId); err != nil {
 		return nil, err
 	}
+	c.loadMeta()
+	return c, nil
+
+}
+
+func GetDefaultCard(identityId uuid.UUID) (*Card, error) {
+	c := new(Card)
+	if err := database.Get(c, "cards/get_default", identityId); err != nil {
+		return nil, err
+	}
+	c.loadMeta()
 	return c, nil
+}
 
+func GetCards(identityId uuid.UUID) ([]Card, error) {
+	cards := []Card{}
+	if err := database.QuerySelect("cards/get", &cards, identityId); err != nil {
+		return nil, err
+	}
+	for i := range cards {
+		cards[i].loadMeta()
+	}
+	return cards, nil
 }
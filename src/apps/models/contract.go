@@ -2,10 +2,14 @@ package models
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/types"
 	"github.com/lib/pq"
 	"github.com/socious-io/gopay"
@@ -25,6 +29,8 @@ type Contract struct {
 	CryptoCurrency        *string                  `db:"crypto_currency" json:"crypto_currency"`
 	CryptoNetwork         *WalletNetwork           `db:"crypto_network" json:"crypto_network"`
 	CurrencyRate          float32                  `db:"currency_rate" json:"currency_rate"`
+	LockedCurrencyRate    *float64                 `db:"locked_currency_rate" json:"locked_currency_rate"`
+	CurrencyRateLockedAt  *time.Time               `db:"currency_rate_locked_at" json:"currency_rate_locked_at"`
 	Commitment            int                      `db:"commitment" json:"commitment"`
 	CommitmentPeriod      ContractCommitmentPeriod `db:"commitment_period" json:"commitment_period"`
 	CommitmentPeriodCount int                      `db:"commitment_period_count" json:"commitment_period_count"`
@@ -43,13 +49,24 @@ type Contract struct {
 	ProviderFeedback bool `db:"provider_feedback" json:"provider_feedback"`
 	ClientFeedback   bool `db:"client_feedback" json:"client_feedback"`
 
-	Amounts map[string]any `db:"-" json:"amounts"`
+	Amounts    *AmountsBreakdown   `db:"-" json:"amounts"`
+	Milestones []ContractMilestone `db:"-" json:"milestones"`
+	Amendments []ContractAmendment `db:"-" json:"amendments"`
+
+	ApprovedHours  float64 `db:"approved_hours" json:"approved_hours"`
+	ApprovedAmount float64 `db:"approved_amount" json:"approved_amount"`
+
+	// Set while the provider's payment is disputed at Stripe
+	ChargebackID *string    `db:"chargeback_id" json:"chargeback_id"`
+	ChargebackAt *time.Time `db:"chargeback_at" json:"chargeback_at"`
 
 	ApplicantID *uuid.UUID `db:"applicant_id" json:"applicant_id"`
 	ProjectID   *uuid.UUID `db:"project_id" json:"project_id"`
 	PaymentID   *uuid.UUID `db:"payment_id" json:"payment_id"`
 	OfferID     *uuid.UUID `db:"offer_id" json:"offer_id"`
 	MissionID   *uuid.UUID `db:"mission_id" json:"mission_id"`
+	FeePolicyID *uuid.UUID `db:"fee_policy_id" json:"fee_policy_id"`
+	FeePolicy   *FeePolicy `db:"-" json:"fee_policy"`
 
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
@@ -61,6 +78,9 @@ type Contract struct {
 	ProjectJson          types.JSONText `db:"project" json:"-"`
 	ApplicantJson        types.JSONText `db:"applicant" json:"-"`
 	RequirementFilesJson types.JSONText `db:"requirement_files" json:"requirement_files"`
+	MilestonesJson       types.JSONText `db:"milestones" json:"-"`
+	AmendmentsJson       types.JSONText `db:"amendments" json:"-"`
+	FeePolicyJson        types.JSONText `db:"fee_policy" json:"-"`
 }
 
 func (Contract) TableName() string {
@@ -71,10 +91,9 @@ func (Contract) FetchQuery() string {
 	return "contracts/fetch"
 }
 
-func (c *Contract) Create(ctx context.Context) error {
-	rows, err := database.Query(
-		ctx,
-		"contracts/create",
+// createParams are the params of the contracts/create query
+func (c *Contract) createParams() []interface{} {
+	return []interface{}{
 		c.Name,
 		c.Description,
 		c.Type,
@@ -91,7 +110,12 @@ func (c *Contract) Create(ctx context.Context) error {
 		c.ProviderID,
 		c.ClientID,
 		c.CryptoNetwork,
-	)
+		c.RequirementDescription,
+	}
+}
+
+func (c *Contract) Create(ctx context.Context) error {
+	rows, err := database.Query(ctx, "contracts/create", c.createParams()...)
 
 	if err != nil {
 		return err
@@ -113,6 +137,18 @@ func (c *Contract) Update(ctx context.Context, requirementFiles []uuid.UUID) err
 		return err
 	}
 
+	if err := c.update(ctx, tx, requirementFiles); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	tx.Commit()
+
+	return database.Fetch(c, c.ID)
+}
+
+// update stores the contract within the transaction, the caller rolls it back on errors.
+func (c *Contract) update(ctx context.Context, tx *sqlx.Tx, requirementFiles []uuid.UUID) error {
 	rows, err := database.TxQuery(
 		ctx,
 		tx,
@@ -128,20 +164,17 @@ func (c *Contract) Update(ctx context.Context, requirementFiles []uuid.UUID) err
 		c.CommitmentPeriod,
 		c.CommitmentPeriodCount,
 		c.PaymentType,
-		c.Status,
 		c.PaymentID,
 		c.RequirementDescription,
 		c.CryptoNetwork,
 	)
 
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		if err := rows.StructScan(c); err != nil {
-			tx.Rollback()
 			return err
 		}
 	}
@@ -153,8 +186,6 @@ func (c *Contract) Update(ctx context.Context, requirementFiles []uuid.UUID) err
 			c.ID,
 		)
 		if err != nil {
-			tx.Rollback()
-
 			return err
 		}
 		rows.Close()
@@ -166,16 +197,13 @@ func (c *Contract) Update(ctx context.Context, requirementFiles []uuid.UUID) err
 
 		if len(requirementFilesData) > 0 {
 			if _, err = database.TxExecuteQuery(tx, "contracts/create_requirement_file", requirementFilesData); err != nil {
-				tx.Rollback()
 				return err
 			}
 		}
 		rows.Close()
 	}
 
-	tx.Commit()
-
-	return database.Fetch(c, c.ID)
+	return nil
 }
 
 func GetContract(id uuid.UUID) (*Contract, error) {
@@ -228,3 +256,61 @@ func (c *Contract) Delete(ctx context.Context) error {
 	defer rows.Close()
 	return nil
 }
+
+// GetPaymentIDByIntent returns the gopay payment deposited with the Stripe payment intent.
+func GetPaymentIDByIntent(intentID string) (*uuid.UUID, error) {
+	var row database.FetchList
+	if err := database.Get(&row, "contracts/get_payment_by_intent", intentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &row.ID, nil
+}
+
+// GetContractByPayment returns the contract paid by the gopay payment, directly or by one of its milestones.
+func GetContractByPayment(paymentID uuid.UUID) (*Contract, error) {
+	var row database.FetchList
+	if err := database.Get(&row, "contracts/get_by_payment", paymentID); err != nil {
+		return nil, err
+	}
+	return GetContract(row.ID)
+}
+
+// LockCurrencyRate sets the rate the contract is paid with on its first deposit, the rate of a contract
+// already locked is kept. The offered currency_rate of the signed terms is left as it is.
+func (c *Contract) LockCurrencyRate(ctx context.Context, rate float64) error {
+	rows, err := database.Query(ctx, "contracts/lock_currency_rate", c.ID, rate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(c); err != nil {
+			return err
+		}
+	}
+	if err := database.Fetch(c, c.ID); err != nil {
+		return err
+	}
+	if c.LockedCurrencyRate == nil {
+		return fmt.Errorf("currency rate of the contract couldn't be locked")
+	}
+	return nil
+}
+
+// FlagChargeback marks the contract as charged back by the Stripe dispute, a nil dispute clears the flag.
+func (c *Contract) FlagChargeback(ctx context.Context, disputeID *string) error {
+	rows, err := database.Query(ctx, "contracts/update_chargeback", c.ID, disputeID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(c); err != nil {
+			return err
+		}
+	}
+	return database.Fetch(c, c.ID)
+}
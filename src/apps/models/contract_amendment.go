@@ -0,0 +1,312 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	database "github.com/socious-io/pkg_database"
+)
+
+// ContractTerms holds the contract fields that can be changed by amendments only once the contract is locked.
+// Unset fields are not part of the amendment.
+type ContractTerms struct {
+	TotalAmount           *float64                  `json:"total_amount,omitempty"`
+	Currency              *Currency                 `json:"currency,omitempty"`
+	CryptoCurrency        *string                   `json:"crypto_currency,omitempty"`
+	CryptoNetwork         *WalletNetwork            `json:"crypto_network,omitempty"`
+	CurrencyRate          *float32                  `json:"currency_rate,omitempty"`
+	Commitment            *int                      `json:"commitment,omitempty"`
+	CommitmentPeriod      *ContractCommitmentPeriod `json:"commitment_period,omitempty"`
+	CommitmentPeriodCount *int                      `json:"commitment_period_count,omitempty"`
+	PaymentType           *PaymentModeType          `json:"payment_type,omitempty"`
+}
+
+type ContractAmendment struct {
+	ID          uuid.UUID               `db:"id" json:"id"`
+	ContractID  uuid.UUID               `db:"contract_id" json:"contract_id"`
+	ProposerID  uuid.UUID               `db:"proposer_id" json:"proposer_id"`
+	ResponderID *uuid.UUID              `db:"responder_id" json:"responder_id"`
+	Status      ContractAmendmentStatus `db:"status" json:"status"`
+	Reason      *string                 `db:"reason" json:"reason"`
+
+	OldTerms     ContractTerms  `db:"-" json:"old_terms"`
+	NewTerms     ContractTerms  `db:"-" json:"new_terms"`
+	OldTermsJson types.JSONText `db:"old_terms" json:"-"`
+	NewTermsJson types.JSONText `db:"new_terms" json:"-"`
+
+	RespondedAt *time.Time `db:"responded_at" json:"responded_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (ContractAmendment) TableName() string {
+	return "contract_amendments"
+}
+
+func (ContractAmendment) FetchQuery() string {
+	return "contracts/fetch_amendment"
+}
+
+// TermsLocked reports whether the contract terms can be changed through accepted amendments only.
+func (c *Contract) TermsLocked() bool {
+	return c.Status != ContractStatusCreated || c.PaymentID != nil
+}
+
+// Funded reports whether the contract is deposited in full or any of its milestones is.
+func (c *Contract) Funded() (bool, error) {
+	if c.PaymentID != nil {
+		return true, nil
+	}
+	milestones, err := GetContractMilestones(c.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, m := range milestones {
+		if m.PaymentID != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ChangesMoney reports whether the terms change what has been escrowed for the contract.
+func (t ContractTerms) ChangesMoney() bool {
+	return t.TotalAmount != nil || t.Currency != nil || t.CryptoCurrency != nil ||
+		t.CryptoNetwork != nil || t.CurrencyRate != nil || t.PaymentType != nil
+}
+
+// checkFundedTerms refuses changing the money terms of funded contracts, the escrowed payment
+// would no longer match the contract.
+func (c *Contract) checkFundedTerms(t ContractTerms) error {
+	if !t.ChangesMoney() {
+		return nil
+	}
+	funded, err := c.Funded()
+	if err != nil {
+		return err
+	}
+	if funded {
+		return fmt.Errorf("money terms of funded contracts can't be amended")
+	}
+	return nil
+}
+
+// DiffTerms returns the current and proposed values of the terms the proposal changes.
+func (c *Contract) DiffTerms(proposed ContractTerms) (ContractTerms, ContractTerms, bool) {
+	var old, changed ContractTerms
+	diff := false
+	diffTerm(&c.TotalAmount, proposed.TotalAmount, &old.TotalAmount, &changed.TotalAmount, &diff)
+	diffOptionalTerm(c.Currency, proposed.Currency, &old.Currency, &changed.Currency, &diff)
+	diffOptionalTerm(c.CryptoCurrency, proposed.CryptoCurrency, &old.CryptoCurrency, &changed.CryptoCurrency, &diff)
+	diffOptionalTerm(c.CryptoNetwork, proposed.CryptoNetwork, &old.CryptoNetwork, &changed.CryptoNetwork, &diff)
+	diffTerm(&c.CurrencyRate, proposed.CurrencyRate, &old.CurrencyRate, &changed.CurrencyRate, &diff)
+	diffTerm(&c.Commitment, proposed.Commitment, &old.Commitment, &changed.Commitment, &diff)
+	diffTerm(&c.CommitmentPeriod, proposed.CommitmentPeriod, &old.CommitmentPeriod, &changed.CommitmentPeriod, &diff)
+	diffTerm(&c.CommitmentPeriodCount, proposed.CommitmentPeriodCount, &old.CommitmentPeriodCount, &changed.CommitmentPeriodCount, &diff)
+	diffOptionalTerm(c.PaymentType, proposed.PaymentType, &old.PaymentType, &changed.PaymentType, &diff)
+	return old, changed, diff
+}
+
+func (c *Contract) applyTerms(t ContractTerms) {
+	if t.TotalAmount != nil {
+		c.TotalAmount = *t.TotalAmount
+	}
+	if t.Currency != nil {
+		c.Currency = t.Currency
+	}
+	if t.CryptoCurrency != nil {
+		c.CryptoCurrency = t.CryptoCurrency
+	}
+	if t.CryptoNetwork != nil {
+		c.CryptoNetwork = t.CryptoNetwork
+	}
+	if t.CurrencyRate != nil {
+		c.CurrencyRate = *t.CurrencyRate
+	}
+	if t.Commitment != nil {
+		c.Commitment = *t.Commitment
+	}
+	if t.CommitmentPeriod != nil {
+		c.CommitmentPeriod = *t.CommitmentPeriod
+	}
+	if t.CommitmentPeriodCount != nil {
+		c.CommitmentPeriodCount = *t.CommitmentPeriodCount
+	}
+	if t.PaymentType != nil {
+		c.PaymentType = t.PaymentType
+	}
+}
+
+func diffTerm[T comparable](current *T, proposed *T, old **T, changed **T, diff *bool) {
+	if proposed == nil || *current == *proposed {
+		return
+	}
+	*old, *changed, *diff = current, proposed, true
+}
+
+func diffOptionalTerm[T comparable](current *T, proposed *T, old **T, changed **T, diff *bool) {
+	if proposed == nil || (current != nil && *current == *proposed) {
+		return
+	}
+	*old, *changed, *diff = current, proposed, true
+}
+
+// ProposeAmendment stores the pending amendment of the contract terms proposed by one of the parties.
+func (c *Contract) ProposeAmendment(ctx context.Context, proposerID uuid.UUID, proposed ContractTerms, reason *string) (*ContractAmendment, error) {
+	if c.IsTerminal() {
+		return nil, fmt.Errorf("contract is %s and can't be amended", c.Status)
+	}
+	old, changed, diff := c.DiffTerms(proposed)
+	if !diff {
+		return nil, fmt.Errorf("amendment does not change any contract term")
+	}
+	if err := c.checkFundedTerms(changed); err != nil {
+		return nil, err
+	}
+
+	oldJson, _ := json.Marshal(old)
+	newJson, _ := json.Marshal(changed)
+
+	a := new(ContractAmendment)
+	rows, err := database.Query(ctx, "contracts/create_amendment", c.ID, proposerID, types.JSONText(oldJson), types.JSONText(newJson), reason)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(a); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := database.Fetch(a, a.ID); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Accept applies the amended terms to the contract and marks the amendment accepted in one transaction,
+// the amendment gets stale and can't be accepted when the contract terms have been changed after it was proposed.
+func (a *ContractAmendment) Accept(ctx context.Context, c *Contract, responderID uuid.UUID) error {
+	if a.Status != ContractAmendmentStatusPending {
+		return fmt.Errorf("amendment is already %s", a.Status)
+	}
+	if c.IsTerminal() {
+		return fmt.Errorf("contract is %s and can't be amended", c.Status)
+	}
+	if _, _, diff := c.DiffTerms(a.OldTerms); diff {
+		return fmt.Errorf("contract terms have been changed since the amendment was proposed")
+	}
+	// The contract may have been funded after the amendment was proposed
+	if err := c.checkFundedTerms(a.NewTerms); err != nil {
+		return err
+	}
+
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	c.applyTerms(a.NewTerms)
+	if err := c.update(ctx, tx, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := a.respondTx(ctx, tx, responderID, ContractAmendmentStatusAccepted); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if err := database.Fetch(c, c.ID); err != nil {
+		return err
+	}
+	return database.Fetch(a, a.ID)
+}
+
+func (a *ContractAmendment) Reject(ctx context.Context, responderID uuid.UUID) error {
+	return a.respond(ctx, responderID, ContractAmendmentStatusRejected)
+}
+
+func (a *ContractAmendment) Withdraw(ctx context.Context) error {
+	return a.respond(ctx, a.ProposerID, ContractAmendmentStatusWithdrawn)
+}
+
+func (a *ContractAmendment) respond(ctx context.Context, responderID uuid.UUID, status ContractAmendmentStatus) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+	if err := a.respondTx(ctx, tx, responderID, status); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return database.Fetch(a, a.ID)
+}
+
+// respondTx answers the pending amendment within the transaction, the caller rolls it back on errors.
+func (a *ContractAmendment) respondTx(ctx context.Context, tx *sqlx.Tx, responderID uuid.UUID, status ContractAmendmentStatus) error {
+	rows, err := database.TxQuery(ctx, tx, "contracts/respond_amendment", a.ID, responderID, status)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	responded := false
+	for rows.Next() {
+		if err := rows.StructScan(a); err != nil {
+			return err
+		}
+		responded = true
+	}
+	if !responded {
+		return fmt.Errorf("amendment is already %s", a.Status)
+	}
+	return rows.Err()
+}
+
+func GetContractAmendment(id uuid.UUID, contractID uuid.UUID) (*ContractAmendment, error) {
+	a := new(ContractAmendment)
+	if err := database.Fetch(a, id); err != nil {
+		return nil, err
+	}
+	if a.ContractID != contractID {
+		return nil, fmt.Errorf("amendment not found")
+	}
+	return a, nil
+}
+
+func GetContractAmendments(contractID uuid.UUID) ([]ContractAmendment, error) {
+	var (
+		amendments = []ContractAmendment{}
+		fetchList  []database.FetchList
+		ids        []interface{}
+	)
+
+	if err := database.QuerySelect("contracts/get_amendments", &fetchList, contractID); err != nil {
+		return nil, err
+	}
+
+	if len(fetchList) < 1 {
+		return amendments, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&amendments, ids...); err != nil {
+		return nil, err
+	}
+	return amendments, nil
+}
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/socious-io/gopay"
+	database "github.com/socious-io/pkg_database"
+)
+
+type ContractMilestone struct {
+	ID          uuid.UUID               `db:"id" json:"id"`
+	ContractID  uuid.UUID               `db:"contract_id" json:"contract_id"`
+	Title       string                  `db:"title" json:"title"`
+	Description *string                 `db:"description" json:"description"`
+	Amount      float64                 `db:"amount" json:"amount"`
+	DueDate     *time.Time              `db:"due_date" json:"due_date"`
+	Status      ContractMilestoneStatus `db:"status" json:"status"`
+
+	PaymentID   *uuid.UUID     `db:"payment_id" json:"payment_id"`
+	Payment     *gopay.Payment `db:"-" json:"payment"`
+	PaymentJson types.JSONText `db:"payment" json:"-"`
+
+	Amounts *AmountsBreakdown `db:"-" json:"amounts"`
+
+	FundedAt   *time.Time `db:"funded_at" json:"funded_at"`
+	ReleasedAt *time.Time `db:"released_at" json:"released_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (ContractMilestone) TableName() string {
+	return "contract_milestones"
+}
+
+func (ContractMilestone) FetchQuery() string {
+	return "contracts/fetch_milestone"
+}
+
+func (m *ContractMilestone) Create(ctx context.Context) error {
+	rows, err := database.Query(
+		ctx,
+		"contracts/create_milestone",
+		m.ContractID,
+		m.Title,
+		m.Description,
+		m.Amount,
+		m.DueDate,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(m); err != nil {
+			return err
+		}
+	}
+
+	return database.Fetch(m, m.ID)
+}
+
+func (m *ContractMilestone) Update(ctx context.Context) error {
+	return m.updateWith(ctx, "contracts/update_milestone", m.Title, m.Description, m.Amount, m.DueDate)
+}
+
+// Fund links the deposited payment to the milestone, only pending milestones can be funded.
+func (m *ContractMilestone) Fund(ctx context.Context, paymentID uuid.UUID) error {
+	return m.updateWith(ctx, "contracts/fund_milestone", paymentID)
+}
+
+// LinkPayment links a deposit still waiting for confirmation to the pending milestone, the milestone
+// is funded with FundMilestonesByPayment once the payment is deposited.
+func (m *ContractMilestone) LinkPayment(ctx context.Context, paymentID uuid.UUID) error {
+	return m.updateWith(ctx, "contracts/link_milestone_payment", paymentID)
+}
+
+// Approve marks the milestone released, its escrow must be paid out to the client first.
+func (m *ContractMilestone) Approve(ctx context.Context) error {
+	return m.updateWith(ctx, "contracts/approve_milestone")
+}
+
+// Refund marks the funded milestone refunded, its escrow must be paid back to the provider first.
+func (m *ContractMilestone) Refund(ctx context.Context) error {
+	return m.updateWith(ctx, "contracts/refund_milestone")
+}
+
+func (m *ContractMilestone) updateWith(ctx context.Context, queryName string, args ...interface{}) error {
+	rows, err := database.Query(ctx, queryName, append([]interface{}{m.ID}, args...)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	updated := false
+	for rows.Next() {
+		if err := rows.StructScan(m); err != nil {
+			return err
+		}
+		updated = true
+	}
+	if !updated {
+		return fmt.Errorf("milestone is %s and can't be changed", m.Status)
+	}
+
+	return database.Fetch(m, m.ID)
+}
+
+func (m *ContractMilestone) Delete(ctx context.Context) error {
+	if m.Status != ContractMilestoneStatusPending {
+		return fmt.Errorf("milestone is %s and can't be deleted", m.Status)
+	}
+	rows, err := database.Query(ctx, "contracts/delete_milestone", m.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return nil
+}
+
+// FundMilestonesByPayment funds the pending milestones linked to the payment once it's deposited
+func FundMilestonesByPayment(ctx context.Context, paymentID uuid.UUID) error {
+	rows, err := database.Query(ctx, "contracts/fund_milestones_by_payment", paymentID)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+func GetContractMilestone(id uuid.UUID, contractID uuid.UUID) (*ContractMilestone, error) {
+	m := new(ContractMilestone)
+	if err := database.Fetch(m, id); err != nil {
+		return nil, err
+	}
+	if m.ContractID != contractID {
+		return nil, fmt.Errorf("milestone not found")
+	}
+	return m, nil
+}
+
+func GetContractMilestones(contractID uuid.UUID) ([]ContractMilestone, error) {
+	var (
+		milestones = []ContractMilestone{}
+		fetchList  []database.FetchList
+		ids        []interface{}
+	)
+
+	if err := database.QuerySelect("contracts/get_milestones", &fetchList, contractID); err != nil {
+		return nil, err
+	}
+
+	if len(fetchList) < 1 {
+		return milestones, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&milestones, ids...); err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+// ValidateMilestoneAmount makes sure the milestones of a contract never exceed its total amount.
+// amount is the new milestone value and except skips the milestone being updated.
+func (c *Contract) ValidateMilestoneAmount(except *uuid.UUID, amount float64) error {
+	milestones, err := GetContractMilestones(c.ID)
+	if err != nil {
+		return err
+	}
+	total := amount
+	for _, m := range milestones {
+		if except != nil && m.ID == *except {
+			continue
+		}
+		total += m.Amount
+	}
+	if total > c.TotalAmount {
+		return fmt.Errorf("milestones total %v exceeds contract total amount %v", total, c.TotalAmount)
+	}
+	return nil
+}
+
+// ValidateMilestonesTotal makes sure the milestones of a contract cover its total amount,
+// so funding all of them escrows the whole contract.
+func (c *Contract) ValidateMilestonesTotal() error {
+	milestones, err := GetContractMilestones(c.ID)
+	if err != nil {
+		return err
+	}
+	if total := milestonesTotal(milestones); !sameAmount(total, c.TotalAmount) {
+		return fmt.Errorf("milestones total %v must equal contract total amount %v", total, c.TotalAmount)
+	}
+	return nil
+}
+
+// MilestonesApproved reports whether the contract has milestones covering its total amount
+// and all of them are approved.
+func (c *Contract) MilestonesApproved() (bool, error) {
+	milestones, err := GetContractMilestones(c.ID)
+	if err != nil {
+		return false, err
+	}
+	if len(milestones) < 1 || !sameAmount(milestonesTotal(milestones), c.TotalAmount) {
+		return false, nil
+	}
+	for _, m := range milestones {
+		if m.Status != ContractMilestoneStatusApproved {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// HasMilestones reports whether the contract is split into milestones and funded per milestone.
+func (c *Contract) HasMilestones() (bool, error) {
+	milestones, err := GetContractMilestones(c.ID)
+	if err != nil {
+		return false, err
+	}
+	return len(milestones) > 0, nil
+}
+
+func milestonesTotal(milestones []ContractMilestone) float64 {
+	total := 0.0
+	for _, m := range milestones {
+		total += m.Amount
+	}
+	return total
+}
+
+// sameAmount compares amounts to the cent, float sums of milestones drift below that.
+func sameAmount(a, b float64) bool {
+	return math.Round(a*100) == math.Round(b*100)
+}
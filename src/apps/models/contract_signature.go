@@ -0,0 +1,185 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	database "github.com/socious-io/pkg_database"
+)
+
+type ContractSignature struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	ContractID uuid.UUID `db:"contract_id" json:"contract_id"`
+	IdentityID uuid.UUID `db:"identity_id" json:"identity_id"`
+	Document   string    `db:"document" json:"document"`
+	Hash       string    `db:"hash" json:"hash"`
+	IPAddress  *string   `db:"ip_address" json:"ip_address"`
+	UserAgent  *string   `db:"user_agent" json:"user_agent"`
+
+	Identity     *Identity      `db:"-" json:"identity"`
+	IdentityJson types.JSONText `db:"identity" json:"-"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ContractDocument is the canonical form of the contract terms the parties sign.
+// Fields order is fixed by the struct so the same terms always serialize to the same bytes.
+type ContractDocument struct {
+	ID                     uuid.UUID                `json:"id"`
+	Name                   string                   `json:"name"`
+	Description            *string                  `json:"description"`
+	Type                   ContractType             `json:"type"`
+	TotalAmount            float64                  `json:"total_amount"`
+	Currency               *Currency                `json:"currency"`
+	CryptoCurrency         *string                  `json:"crypto_currency"`
+	CryptoNetwork          *WalletNetwork           `json:"crypto_network"`
+	CurrencyRate           float32                  `json:"currency_rate"`
+	PaymentType            *PaymentModeType         `json:"payment_type"`
+	Commitment             int                      `json:"commitment"`
+	CommitmentPeriod       ContractCommitmentPeriod `json:"commitment_period"`
+	CommitmentPeriodCount  int                      `json:"commitment_period_count"`
+	RequirementDescription *string                  `json:"requirement_description"`
+	RequirementFiles       []string                 `json:"requirement_files"`
+	ProviderID             uuid.UUID                `json:"provider_id"`
+	ClientID               uuid.UUID                `json:"client_id"`
+	ProjectID              *uuid.UUID               `json:"project_id"`
+}
+
+func (ContractSignature) TableName() string {
+	return "contract_signatures"
+}
+
+func (ContractSignature) FetchQuery() string {
+	return "contracts/fetch_signature"
+}
+
+// Document serializes the current contract terms canonically and returns it with its SHA-256 hash.
+func (c *Contract) Document() (string, string, error) {
+	files := []struct {
+		ID uuid.UUID `json:"id"`
+	}{}
+	if len(c.RequirementFilesJson) > 0 {
+		if err := json.Unmarshal(c.RequirementFilesJson, &files); err != nil {
+			return "", "", err
+		}
+	}
+	fileIDs := []string{}
+	for _, f := range files {
+		fileIDs = append(fileIDs, f.ID.String())
+	}
+	sort.Strings(fileIDs)
+
+	document, err := json.Marshal(ContractDocument{
+		ID:                     c.ID,
+		Name:                   c.Name,
+		Description:            c.Description,
+		Type:                   c.Type,
+		TotalAmount:            c.TotalAmount,
+		Currency:               c.Currency,
+		CryptoCurrency:         c.CryptoCurrency,
+		CryptoNetwork:          c.CryptoNetwork,
+		CurrencyRate:           c.CurrencyRate,
+		PaymentType:            c.PaymentType,
+		Commitment:             c.Commitment,
+		CommitmentPeriod:       c.CommitmentPeriod,
+		CommitmentPeriodCount:  c.CommitmentPeriodCount,
+		RequirementDescription: c.RequirementDescription,
+		RequirementFiles:       fileIDs,
+		ProviderID:             c.ProviderID,
+		ClientID:               c.ClientID,
+		ProjectID:              c.ProjectID,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	sum := sha256.Sum256(document)
+	return string(document), hex.EncodeToString(sum[:]), nil
+}
+
+// Sign moves the contract to SIGNED and stores the signature of the signed terms in the same transaction.
+func (c *Contract) Sign(ctx context.Context, identityID uuid.UUID, reason, ipAddress, userAgent *string) (*ContractSignature, error) {
+	party, err := c.PartyOf(identityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := CanTransitContract(c.Status, ContractStatusSinged, party); err != nil {
+		return nil, err
+	}
+
+	document, hash, err := c.Document()
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(ContractSignature)
+	err = c.transit(ctx, &identityID, ContractStatusSinged, reason, func(tx *sqlx.Tx) error {
+		rows, err := database.TxQuery(ctx, tx, "contracts/create_signature",
+			c.ID,
+			identityID,
+			document,
+			hash,
+			ipAddress,
+			userAgent,
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := rows.StructScan(s); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.Fetch(s, s.ID); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Verify recomputes the hash of the current contract terms and reports whether they still match the signed ones.
+func (s *ContractSignature) Verify(c *Contract) (bool, string, error) {
+	_, hash, err := c.Document()
+	if err != nil {
+		return false, "", err
+	}
+	return hash == s.Hash, hash, nil
+}
+
+func GetContractSignatures(contractID uuid.UUID) ([]ContractSignature, error) {
+	var (
+		signatures = []ContractSignature{}
+		fetchList  []database.FetchList
+		ids        []interface{}
+	)
+
+	if err := database.QuerySelect("contracts/get_signatures", &fetchList, contractID); err != nil {
+		return nil, err
+	}
+
+	if len(fetchList) < 1 {
+		return signatures, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&signatures, ids...); err != nil {
+		return nil, err
+	}
+	return signatures, nil
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	database "github.com/socious-io/pkg_database"
+)
+
+type ContractStatusHistory struct {
+	ID         uuid.UUID      `db:"id" json:"id"`
+	ContractID uuid.UUID      `db:"contract_id" json:"contract_id"`
+	IdentityID *uuid.UUID     `db:"identity_id" json:"identity_id"`
+	OldStatus  ContractStatus `db:"old_status" json:"old_status"`
+	NewStatus  ContractStatus `db:"new_status" json:"new_status"`
+	Reason     *string        `db:"reason" json:"reason"`
+
+	Identity     *Identity      `db:"-" json:"identity"`
+	IdentityJson types.JSONText `db:"identity" json:"-"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+func (ContractStatusHistory) TableName() string {
+	return "contract_status_history"
+}
+
+func (ContractStatusHistory) FetchQuery() string {
+	return "contracts/fetch_status_history"
+}
+
+// contractTransitions declares every allowed status change and the parties that may trigger it.
+// Statuses missing from the table (canceled and completed contracts) are terminal.
+var contractTransitions = map[ContractStatus]map[ContractStatus][]ContractParty{
+	ContractStatusCreated: {
+		ContractStatusClientApproved:   {ContractPartyClient},
+		ContractStatusSinged:           {ContractPartyClient},
+		ContractStatusProviderCanceled: {ContractPartyProvider},
+		ContractStatusClientCanceled:   {ContractPartyClient},
+	},
+	ContractStatusClientApproved: {
+		ContractStatusSinged:           {ContractPartyClient},
+		ContractStatusProviderCanceled: {ContractPartyProvider},
+		ContractStatusClientCanceled:   {ContractPartyClient},
+	},
+	ContractStatusSinged: {
+		ContractStatusApplied:          {ContractPartyClient},
+		ContractStatusCompleted:        {ContractPartyProvider},
+		ContractStatusProviderCanceled: {ContractPartyProvider},
+		ContractStatusClientCanceled:   {ContractPartyClient},
+	},
+	ContractStatusApplied: {
+		ContractStatusCompleted:        {ContractPartyProvider},
+		ContractStatusProviderCanceled: {ContractPartyProvider},
+		ContractStatusClientCanceled:   {ContractPartyClient},
+	},
+}
+
+// CanTransitContract checks the transition table for moving from one status to another by the given party.
+func CanTransitContract(from, to ContractStatus, party ContractParty) error {
+	parties, ok := contractTransitions[from][to]
+	if !ok {
+		return fmt.Errorf("contract can't move from %s to %s", from, to)
+	}
+	for _, p := range parties {
+		if p == party {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not allowed to move contract from %s to %s", party, from, to)
+}
+
+// IsTerminal reports whether the contract can't move to any other status.
+func (c *Contract) IsTerminal() bool {
+	_, ok := contractTransitions[c.Status]
+	return !ok
+}
+
+func (c *Contract) PartyOf(identityID uuid.UUID) (ContractParty, error) {
+	switch identityID {
+	case c.ProviderID:
+		return ContractPartyProvider, nil
+	case c.ClientID:
+		return ContractPartyClient, nil
+	}
+	return "", fmt.Errorf("identity is not a party of this contract")
+}
+
+// Transit moves the contract to the given status on behalf of identityID and records it on the history.
+func (c *Contract) Transit(ctx context.Context, identityID uuid.UUID, status ContractStatus, reason *string) error {
+	party, err := c.PartyOf(identityID)
+	if err != nil {
+		return err
+	}
+	if err := CanTransitContract(c.Status, status, party); err != nil {
+		return err
+	}
+	return c.transit(ctx, &identityID, status, reason, nil)
+}
+
+// TransitOnBehalf moves the contract on behalf of a party without an acting identity,
+// used when the platform decides the outcome (e.g. a resolved dispute).
+func (c *Contract) TransitOnBehalf(ctx context.Context, party ContractParty, status ContractStatus, reason *string) error {
+	if err := CanTransitContract(c.Status, status, party); err != nil {
+		return err
+	}
+	return c.transit(ctx, nil, status, reason, nil)
+}
+
+// transit updates the status and its history in one transaction, record stores
+// any extra data of the transition (e.g. the signature) in the same transaction.
+func (c *Contract) transit(ctx context.Context, identityID *uuid.UUID, status ContractStatus, reason *string, record func(tx *sqlx.Tx) error) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	oldStatus := c.Status
+	rows, err := database.TxQuery(ctx, tx, "contracts/update_status", c.ID, oldStatus, status)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	updated := false
+	for rows.Next() {
+		if err := rows.StructScan(c); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		updated = true
+	}
+	rows.Close()
+
+	if !updated {
+		tx.Rollback()
+		return fmt.Errorf("contract status has been changed from %s, try again", oldStatus)
+	}
+
+	rows, err = database.TxQuery(ctx, tx, "contracts/create_status_history",
+		c.ID,
+		identityID,
+		oldStatus,
+		status,
+		reason,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rows.Close()
+
+	if record != nil {
+		if err := record(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return database.Fetch(c, c.ID)
+}
+
+func GetContractStatusHistory(contractID uuid.UUID, p database.Paginate) ([]ContractStatusHistory, int, error) {
+	var (
+		history   = []ContractStatusHistory{}
+		fetchList []database.FetchList
+		ids       []interface{}
+	)
+
+	if err := database.QuerySelect("contracts/get_status_history", &fetchList, contractID, p.Limit, p.Offet); err != nil {
+		return nil, 0, err
+	}
+
+	if len(fetchList) < 1 {
+		return history, 0, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&history, ids...); err != nil {
+		return nil, 0, err
+	}
+	return history, fetchList[0].TotalCount, nil
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+// ContractTemplate pre-fills the contracts an identity issues repeatedly, unset fields are left to the contract form.
+type ContractTemplate struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	IdentityID uuid.UUID `db:"identity_id" json:"identity_id"`
+	Title      string    `db:"title" json:"title"`
+
+	Name                   *string                  `db:"name" json:"name"`
+	Description            *string                  `db:"description" json:"description"`
+	Type                   ContractType             `db:"type" json:"type"`
+	TotalAmount            *float64                 `db:"total_amount" json:"total_amount"`
+	Currency               *Currency                `db:"currency" json:"currency"`
+	CryptoCurrency         *string                  `db:"crypto_currency" json:"crypto_currency"`
+	CryptoNetwork          *WalletNetwork           `db:"crypto_network" json:"crypto_network"`
+	Commitment             *int                     `db:"commitment" json:"commitment"`
+	CommitmentPeriod       ContractCommitmentPeriod `db:"commitment_period" json:"commitment_period"`
+	CommitmentPeriodCount  *int                     `db:"commitment_period_count" json:"commitment_period_count"`
+	PaymentType            *PaymentModeType         `db:"payment_type" json:"payment_type"`
+	RequirementDescription *string                  `db:"requirement_description" json:"requirement_description"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (ContractTemplate) TableName() string {
+	return "contract_templates"
+}
+
+func (ContractTemplate) FetchQuery() string {
+	return "contracts/fetch_template"
+}
+
+func (t *ContractTemplate) Create(ctx context.Context) error {
+	return t.save(ctx, "contracts/create_template", t.IdentityID)
+}
+
+func (t *ContractTemplate) Update(ctx context.Context) error {
+	return t.save(ctx, "contracts/update_template", t.ID)
+}
+
+func (t *ContractTemplate) save(ctx context.Context, queryName string, key uuid.UUID) error {
+	rows, err := database.Query(
+		ctx,
+		queryName,
+		key,
+		t.Title,
+		t.Name,
+		t.Description,
+		t.Type,
+		t.TotalAmount,
+		t.Currency,
+		t.CryptoCurrency,
+		t.CryptoNetwork,
+		t.Commitment,
+		t.CommitmentPeriod,
+		t.CommitmentPeriodCount,
+		t.PaymentType,
+		t.RequirementDescription,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(t); err != nil {
+			return err
+		}
+	}
+
+	return database.Fetch(t, t.ID)
+}
+
+func (t *ContractTemplate) Delete(ctx context.Context) error {
+	rows, err := database.Query(ctx, "contracts/delete_template", t.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return nil
+}
+
+// GetContractTemplate returns the template only to the identity owning it.
+func GetContractTemplate(id uuid.UUID, identityID uuid.UUID) (*ContractTemplate, error) {
+	t := new(ContractTemplate)
+	if err := database.Fetch(t, id); err != nil {
+		return nil, err
+	}
+	if t.IdentityID != identityID {
+		return nil, fmt.Errorf("contract template not found")
+	}
+	return t, nil
+}
+
+func GetContractTemplates(identityID uuid.UUID, p database.Paginate) ([]ContractTemplate, int, error) {
+	var (
+		templates = []ContractTemplate{}
+		fetchList []database.FetchList
+		ids       []interface{}
+	)
+
+	if err := database.QuerySelect("contracts/get_templates", &fetchList, identityID, p.Limit, p.Offet); err != nil {
+		return nil, 0, err
+	}
+
+	if len(fetchList) < 1 {
+		return templates, 0, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&templates, ids...); err != nil {
+		return nil, 0, err
+	}
+	return templates, fetchList[0].TotalCount, nil
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	database "github.com/socious-io/pkg_database"
+)
+
+// CryptoDeposit is a deposit transaction submitted by the payer, it stays pending until the
+// worker finds it on chain matching the expectation with enough confirmations.
+type CryptoDeposit struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	ContractID uuid.UUID `db:"contract_id" json:"contract_id"`
+	PaymentID  uuid.UUID `db:"payment_id" json:"payment_id"`
+	IdentityID uuid.UUID `db:"identity_id" json:"identity_id"`
+	TxID       string    `db:"tx_id" json:"tx_id"`
+
+	// Expectation
+	TokenAddress          string  `db:"token_address" json:"token_address"`
+	Recipient             string  `db:"recipient" json:"recipient"`
+	ExpectedAmount        float64 `db:"expected_amount" json:"expected_amount"`
+	RequiredConfirmations int     `db:"required_confirmations" json:"required_confirmations"`
+
+	Status        CryptoDepositStatus `db:"status" json:"status"`
+	Confirmations int                 `db:"confirmations" json:"confirmations"`
+	Attempts      int                 `db:"attempts" json:"attempts"`
+	Error         *string             `db:"error" json:"error"`
+	Meta          types.JSONText      `db:"meta" json:"meta"`
+
+	CheckedAt   *time.Time `db:"checked_at" json:"checked_at"`
+	ConfirmedAt *time.Time `db:"confirmed_at" json:"confirmed_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (CryptoDeposit) TableName() string {
+	return "crypto_deposits"
+}
+
+func (CryptoDeposit) FetchQuery() string {
+	return "crypto_deposits/fetch"
+}
+
+func (cd *CryptoDeposit) Create(ctx context.Context, meta interface{}) error {
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.Query(
+		ctx,
+		"crypto_deposits/create",
+		cd.ContractID,
+		cd.PaymentID,
+		cd.IdentityID,
+		cd.TxID,
+		cd.TokenAddress,
+		cd.Recipient,
+		cd.ExpectedAmount,
+		cd.RequiredConfirmations,
+		metaJSON,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(cd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check records a lookup of the deposit on chain, a pending status keeps it for the next lookup.
+// It fails when the deposit has been settled meanwhile so it's settled once.
+func (cd *CryptoDeposit) Check(ctx context.Context, status CryptoDepositStatus, confirmations int, reason *string) error {
+	rows, err := database.Query(ctx, "crypto_deposits/update", cd.ID, status, confirmations, reason)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	updated := false
+	for rows.Next() {
+		if err := rows.StructScan(cd); err != nil {
+			return err
+		}
+		updated = true
+	}
+	if !updated {
+		return fmt.Errorf("crypto deposit %s is not pending", cd.ID)
+	}
+	return nil
+}
+
+// Confirm runs the settlement of the deposit and marks it confirmed last, both while the deposit is locked so
+// the queue and the job never settle it together. A failed settlement leaves the deposit pending to be retried,
+// so it must complete what it recorded already when it's run again.
+func (cd *CryptoDeposit) Confirm(ctx context.Context, confirmations int, settle func() error) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "crypto_deposits/lock", cd.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(cd); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	rows.Close()
+	if cd.Status != CryptoDepositStatusPending {
+		tx.Rollback()
+		return fmt.Errorf("crypto deposit %s is not pending", cd.ID)
+	}
+
+	if err := settle(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rows, err = database.TxQuery(ctx, tx, "crypto_deposits/update", cd.ID, CryptoDepositStatusConfirmed, confirmations, nil)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(cd); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	rows.Close()
+
+	return tx.Commit()
+}
+
+func GetCryptoDeposit(id uuid.UUID) (*CryptoDeposit, error) {
+	cd := new(CryptoDeposit)
+	if err := database.Fetch(cd, id); err != nil {
+		return nil, err
+	}
+	return cd, nil
+}
+
+// GetPendingCryptoDeposits returns the pending deposits, the ones checked earlier first
+func GetPendingCryptoDeposits(limit int) ([]CryptoDeposit, error) {
+	deposits := []CryptoDeposit{}
+	if err := database.QuerySelect("crypto_deposits/get_pending", &deposits, limit); err != nil {
+		return nil, err
+	}
+	return deposits, nil
+}
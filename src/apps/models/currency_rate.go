@@ -0,0 +1,50 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	database "github.com/socious-io/pkg_database"
+)
+
+// CurrencyRate is a cached rate of the quote currency for one unit of the base currency
+type CurrencyRate struct {
+	Base      string    `db:"base" json:"base"`
+	Quote     string    `db:"quote" json:"quote"`
+	Rate      float64   `db:"rate" json:"rate"`
+	Source    string    `db:"source" json:"source"`
+	FetchedAt time.Time `db:"fetched_at" json:"fetched_at"`
+}
+
+// SaveCurrencyRates caches the rates replacing the ones fetched before
+func SaveCurrencyRates(ctx context.Context, rates []CurrencyRate) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	for i, r := range rates {
+		rows, err := database.TxQuery(ctx, tx, "currency_rates/upsert", r.Base, r.Quote, r.Rate, r.Source, r.FetchedAt)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		for rows.Next() {
+			if err := rows.StructScan(&rates[i]); err != nil {
+				rows.Close()
+				tx.Rollback()
+				return err
+			}
+		}
+		rows.Close()
+	}
+	return tx.Commit()
+}
+
+func GetCurrencyRates(base string) ([]CurrencyRate, error) {
+	rates := []CurrencyRate{}
+	if err := database.QuerySelect("currency_rates/get", &rates, base); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
@@ -0,0 +1,375 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	database "github.com/socious-io/pkg_database"
+)
+
+// Number of contributors invited to vote on each dispute
+const DisputeJurorsCount = 3
+
+// Days the respondent has to answer before jurors can vote without the response
+const DisputeResponseDays = 7
+
+// Days jurors have to vote after the response deadline, undecided disputes are resolved by the votes cast
+const DisputeVotingDays = 7
+
+type Dispute struct {
+	ID               uuid.UUID       `db:"id" json:"id"`
+	Title            string          `db:"title" json:"title"`
+	Code             string          `db:"code" json:"code"`
+	Category         DisputeCategory `db:"category" json:"category"`
+	State            DisputeState    `db:"state" json:"state"`
+	WinnerParty      *DisputeSide    `db:"winner_party" json:"winner_party"`
+	ClaimantID       uuid.UUID       `db:"claimant_id" json:"claimant_id"`
+	RespondentID     uuid.UUID       `db:"respondent_id" json:"respondent_id"`
+	ContractID       *uuid.UUID      `db:"contract_id" json:"contract_id"`
+	MissionID        *uuid.UUID      `db:"mission_id" json:"-"`
+	ResponseDeadline *time.Time      `db:"response_deadline" json:"response_deadline"`
+
+	Claimant        *Identity      `db:"-" json:"claimant"`
+	Respondent      *Identity      `db:"-" json:"respondent"`
+	Events          []DisputeEvent `db:"-" json:"events"`
+	Jurors          []DisputeJuror `db:"-" json:"jurors"`
+	ClaimantVotes   int            `db:"claimant_votes" json:"claimant_votes"`
+	RespondentVotes int            `db:"respondent_votes" json:"respondent_votes"`
+	ClaimantJson    types.JSONText `db:"claimant" json:"-"`
+	RespondentJson  types.JSONText `db:"respondent" json:"-"`
+	EventsJson      types.JSONText `db:"events" json:"-"`
+	JurorsJson      types.JSONText `db:"jurors" json:"-"`
+
+	ResolvedAt *time.Time `db:"resolved_at" json:"resolved_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+type DisputeEvent struct {
+	ID         uuid.UUID        `json:"id"`
+	Type       DisputeEventType `json:"type"`
+	Message    *string          `json:"message"`
+	IdentityID uuid.UUID        `json:"identity_id"`
+	Evidences  []Media          `json:"evidences"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+type DisputeJuror struct {
+	JurorID uuid.UUID `json:"juror_id"`
+	Voted   bool      `json:"voted"`
+}
+
+func (Dispute) TableName() string {
+	return "disputes"
+}
+
+func (Dispute) FetchQuery() string {
+	return "disputes/fetch"
+}
+
+// Create opens the dispute with its first message and evidences and invites the jurors.
+func (d *Dispute) Create(ctx context.Context, message string, evidences []uuid.UUID) error {
+	deadline := time.Now().AddDate(0, 0, DisputeResponseDays)
+	d.ResponseDeadline = &deadline
+
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "disputes/create",
+		d.Title,
+		d.Category,
+		d.ClaimantID,
+		d.RespondentID,
+		d.ContractID,
+		d.ResponseDeadline,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(d); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	rows.Close()
+
+	if err := d.addEvent(ctx, tx, d.ClaimantID, DisputeEventTypeMessage, &message, evidences); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Contributors are invited first and admins fill the seats left, parties
+	// and members of party organizations never sit on their own dispute
+	rows, err = database.TxQuery(ctx, tx, "disputes/select_jurors", d.ID, d.ClaimantID, d.RespondentID, DisputeJurorsCount)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	jurors := 0
+	for rows.Next() {
+		jurors++
+	}
+	rows.Close()
+	if jurors < DisputeJurorsCount {
+		tx.Rollback()
+		return fmt.Errorf("not enough jurors available to review the dispute")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return database.Fetch(d, d.ID)
+}
+
+// Respond records the respondent answer and moves the dispute to jurors review.
+func (d *Dispute) Respond(ctx context.Context, message string, evidences []uuid.UUID) error {
+	if d.State != DisputeStateAwaitingResponse {
+		return fmt.Errorf("dispute is not awaiting response")
+	}
+	if d.ResponseDeadline != nil && time.Now().After(*d.ResponseDeadline) {
+		return fmt.Errorf("response deadline has been passed")
+	}
+
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+	if err := d.addEvent(ctx, tx, d.RespondentID, DisputeEventTypeResponse, &message, evidences); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := d.updateState(ctx, tx, DisputeStatePendingReview); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return database.Fetch(d, d.ID)
+}
+
+// Withdraw lets the claimant drop the dispute before it is decided.
+func (d *Dispute) Withdraw(ctx context.Context, message *string) error {
+	if !d.IsOpen() {
+		return fmt.Errorf("dispute is already %s", d.State)
+	}
+
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+	if err := d.addEvent(ctx, tx, d.ClaimantID, DisputeEventTypeWithdraw, message, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := d.updateState(ctx, tx, DisputeStateWithdrawn); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return database.Fetch(d, d.ID)
+}
+
+// Vote submits the juror decision, voting opens once the respondent answered or the deadline has passed.
+// It returns the winner side as soon as the majority of jurors agree on it.
+func (d *Dispute) Vote(ctx context.Context, jurorID uuid.UUID, side DisputeSide) (*DisputeSide, error) {
+	if !d.VotingOpen() {
+		return nil, fmt.Errorf("dispute is not open for voting")
+	}
+
+	rows, err := database.Query(ctx, "disputes/vote", d.ID, jurorID, side)
+	if err != nil {
+		return nil, err
+	}
+	voted := rows.Next()
+	rows.Close()
+	if !voted {
+		return nil, fmt.Errorf("identity is not a juror of this dispute or has already voted")
+	}
+
+	if err := database.Fetch(d, d.ID); err != nil {
+		return nil, err
+	}
+
+	majority := len(d.Jurors)/2 + 1
+	switch {
+	case d.ClaimantVotes >= majority:
+		winner := DisputeSideClaimant
+		return &winner, nil
+	case d.RespondentVotes >= majority:
+		winner := DisputeSideRespondent
+		return &winner, nil
+	}
+	return nil, nil
+}
+
+// Resolve closes the dispute in favour of the winner side.
+func (d *Dispute) Resolve(ctx context.Context, winner DisputeSide) error {
+	rows, err := database.Query(ctx, "disputes/resolve", d.ID, winner)
+	if err != nil {
+		return err
+	}
+	rows.Close()
+	return database.Fetch(d, d.ID)
+}
+
+func (d *Dispute) IsOpen() bool {
+	return d.State != DisputeStateWithdrawn && d.State != DisputeStateClosed
+}
+
+func (d *Dispute) VotingOpen() bool {
+	if d.Overdue() {
+		return false
+	}
+	if d.State == DisputeStatePendingReview {
+		return true
+	}
+	return d.State == DisputeStateAwaitingResponse && d.ResponseDeadline != nil && time.Now().After(*d.ResponseDeadline)
+}
+
+// VotingDeadline is the time the dispute is resolved by the votes cast when the jurors haven't decided it.
+func (d *Dispute) VotingDeadline() *time.Time {
+	if d.ResponseDeadline == nil {
+		return nil
+	}
+	deadline := d.ResponseDeadline.AddDate(0, 0, DisputeVotingDays)
+	return &deadline
+}
+
+// Overdue reports whether the dispute is still open after its voting deadline.
+func (d *Dispute) Overdue() bool {
+	deadline := d.VotingDeadline()
+	return d.IsOpen() && deadline != nil && time.Now().After(*deadline)
+}
+
+// OverdueWinner returns the side with more votes, ties go to the respondent when they answered
+// the claim and to the claimant when they never did.
+func (d *Dispute) OverdueWinner() DisputeSide {
+	switch {
+	case d.ClaimantVotes > d.RespondentVotes:
+		return DisputeSideClaimant
+	case d.RespondentVotes > d.ClaimantVotes:
+		return DisputeSideRespondent
+	case d.State == DisputeStateAwaitingResponse:
+		return DisputeSideClaimant
+	}
+	return DisputeSideRespondent
+}
+
+// SideIdentity returns the identity standing on the given side of the dispute.
+func (d *Dispute) SideIdentity(side DisputeSide) uuid.UUID {
+	if side == DisputeSideClaimant {
+		return d.ClaimantID
+	}
+	return d.RespondentID
+}
+
+func (d *Dispute) IsJuror(identityID uuid.UUID) bool {
+	for _, j := range d.Jurors {
+		if j.JurorID == identityID {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dispute) addEvent(ctx context.Context, tx *sqlx.Tx, identityID uuid.UUID, eventType DisputeEventType, message *string, evidences []uuid.UUID) error {
+	var eventID uuid.UUID
+	rows, err := database.TxQuery(ctx, tx, "disputes/create_event", d.ID, identityID, eventType, message)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		if err := rows.Scan(&eventID); err != nil {
+			rows.Close()
+			return err
+		}
+	}
+	rows.Close()
+
+	evidencesData := []map[string]any{}
+	for _, media := range evidences {
+		evidencesData = append(evidencesData, map[string]any{
+			"dispute_id":       d.ID,
+			"dispute_event_id": eventID,
+			"identity_id":      identityID,
+			"media_id":         media,
+		})
+	}
+	if len(evidencesData) > 0 {
+		if _, err := database.TxExecuteQuery(tx, "disputes/create_evidence", evidencesData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dispute) updateState(ctx context.Context, tx *sqlx.Tx, state DisputeState) error {
+	rows, err := database.TxQuery(ctx, tx, "disputes/update_state", d.ID, d.State, state)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return fmt.Errorf("dispute state has been changed from %s, try again", d.State)
+	}
+	return nil
+}
+
+func GetDispute(id uuid.UUID) (*Dispute, error) {
+	d := new(Dispute)
+	if err := database.Fetch(d, id); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func GetContractDisputes(contractID uuid.UUID, p database.Paginate) ([]Dispute, int, error) {
+	var (
+		disputes  = []Dispute{}
+		fetchList []database.FetchList
+		ids       []interface{}
+	)
+
+	if err := database.QuerySelect("disputes/get", &fetchList, contractID, p.Limit, p.Offet); err != nil {
+		return nil, 0, err
+	}
+
+	if len(fetchList) < 1 {
+		return disputes, 0, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&disputes, ids...); err != nil {
+		return nil, 0, err
+	}
+	return disputes, fetchList[0].TotalCount, nil
+}
+
+// HasOpenDispute reports whether the contract payout is frozen by a dispute in progress.
+func (c *Contract) HasOpenDispute() (bool, error) {
+	f := new(database.FetchList)
+	if err := database.Get(f, "disputes/get_open", c.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -24,6 +24,14 @@ func (ps ProjectStatus) Value() (driver.Value, error) {
 	return string(ps), nil
 }
 
+func (ps ProjectStatus) Valid() bool {
+	return validEnum(ps,
+		ProjectStatusDraft,
+		ProjectStatusExpire,
+		ProjectStatusActive,
+	)
+}
+
 type ProjectType string
 
 const (
@@ -40,6 +48,14 @@ func (pt ProjectType) Value() (driver.Value, error) {
 	return string(pt), nil
 }
 
+func (pt ProjectType) Valid() bool {
+	return validEnum(pt,
+		ProjectTypeOneOff,
+		ProjectTypePartTime,
+		ProjectTypeFullTime,
+	)
+}
+
 type PaymentModeType string
 
 const (
@@ -55,6 +71,13 @@ func (pmt PaymentModeType) Value() (driver.Value, error) {
 	return string(pmt), nil
 }
 
+func (pmt PaymentModeType) Valid() bool {
+	return validEnum(pmt,
+		PaymentModeTypeCrypto,
+		PaymentModeTypeFiat,
+	)
+}
+
 type PaymentScheme string
 
 const (
@@ -78,6 +101,13 @@ func (ps PaymentScheme) Value() (driver.Value, error) {
 	return string(ps), nil
 }
 
+func (ps PaymentScheme) Valid() bool {
+	return validEnum(ps,
+		PaymentSchemeHourly,
+		PaymentSchemeFixed,
+	)
+}
+
 type PaymentService string
 
 const (
@@ -147,6 +177,13 @@ func (pt PaymentType) Value() (driver.Value, error) {
 	return string(pt), nil
 }
 
+func (pt PaymentType) Valid() bool {
+	return validEnum(pt,
+		PaymentTypeVolunteer,
+		PaymentTypePaid,
+	)
+}
+
 type ProjectLength string
 
 const (
@@ -177,6 +214,20 @@ func (pl ProjectLength) Value() (driver.Value, error) {
 	return string(pl), nil
 }
 
+func (pl ProjectLength) Valid() bool {
+	return validEnum(pl,
+		ProjectLengthLess1Day,
+		ProjectLengthLess1Month,
+		ProjectLength1To3Month,
+		ProjectLength3To6Month,
+		ProjectLengthMore6Month,
+		ProjectLength1To3Day,
+		ProjectLength1Week,
+		ProjectLength2Weeks,
+		ProjectLength1Month,
+	)
+}
+
 type ProjectRemotePreference string
 
 const (
@@ -201,6 +252,14 @@ func (prp ProjectRemotePreference) Value() (driver.Value, error) {
 	return string(prp), nil
 }
 
+func (prp ProjectRemotePreference) Valid() bool {
+	return validEnum(prp,
+		ProjectRemotePreferenceOnsite,
+		ProjectRemotePreferenceRemote,
+		ProjectRemotePreferenceHybrid,
+	)
+}
+
 type ProjectKind string
 
 const (
@@ -224,6 +283,13 @@ func (pk ProjectKind) Value() (driver.Value, error) {
 	return string(pk), nil
 }
 
+func (pk ProjectKind) Valid() bool {
+	return validEnum(pk,
+		ProjectKindJob,
+		ProjectKindService,
+	)
+}
+
 type ContractStatus string
 
 const (
@@ -248,6 +314,13 @@ func (pk *ContractStatus) Scan(value interface{}) error {
 	return nil
 }
 
+type ContractParty string
+
+const (
+	ContractPartyProvider ContractParty = "PROVIDER"
+	ContractPartyClient   ContractParty = "CLIENT"
+)
+
 type ContractType string
 
 const (
@@ -288,13 +361,241 @@ func (pk *ContractCommitmentPeriod) Scan(value interface{}) error {
 	return nil
 }
 
+type ContractMilestoneStatus string
+
+const (
+	ContractMilestoneStatusPending  ContractMilestoneStatus = "PENDING"
+	ContractMilestoneStatusFunded   ContractMilestoneStatus = "FUNDED"
+	ContractMilestoneStatusApproved ContractMilestoneStatus = "APPROVED"
+	ContractMilestoneStatusRefunded ContractMilestoneStatus = "REFUNDED"
+)
+
+func (cms *ContractMilestoneStatus) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(cms))
+}
+
+func (cms ContractMilestoneStatus) Value() (driver.Value, error) {
+	return string(cms), nil
+}
+
+type SubmittedWorkStatus string
+
+const (
+	SubmittedWorkStatusPending   SubmittedWorkStatus = "PENDING"
+	SubmittedWorkStatusConfirmed SubmittedWorkStatus = "CONFIRMED"
+	SubmittedWorkStatusRejected  SubmittedWorkStatus = "REJECTED"
+)
+
+func (sws *SubmittedWorkStatus) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(sws))
+}
+
+func (sws SubmittedWorkStatus) Value() (driver.Value, error) {
+	return string(sws), nil
+}
+
+type ContractAmendmentStatus string
+
+const (
+	ContractAmendmentStatusPending   ContractAmendmentStatus = "PENDING"
+	ContractAmendmentStatusAccepted  ContractAmendmentStatus = "ACCEPTED"
+	ContractAmendmentStatusRejected  ContractAmendmentStatus = "REJECTED"
+	ContractAmendmentStatusWithdrawn ContractAmendmentStatus = "WITHDRAWN"
+)
+
+func (cas *ContractAmendmentStatus) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(cas))
+}
+
+func (cas ContractAmendmentStatus) Value() (driver.Value, error) {
+	return string(cas), nil
+}
+
+type DisputeState string
+
+const (
+	DisputeStateAwaitingResponse  DisputeState = "AWAITING_RESPONSE"
+	DisputeStateJurorSelection    DisputeState = "JUROR_SELECTION"
+	DisputeStatePendingReview     DisputeState = "PENDING_REVIEW"
+	DisputeStateDecisionSubmitted DisputeState = "DECISION_SUBMITTED"
+	DisputeStateWithdrawn         DisputeState = "WITHDRAWN"
+	DisputeStateClosed            DisputeState = "CLOSED"
+)
+
+func (ds *DisputeState) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(ds))
+}
+
+func (ds DisputeState) Value() (driver.Value, error) {
+	return string(ds), nil
+}
+
+type DisputeCategory string
+
+const (
+	DisputeCategoryScopeAndContractIssues DisputeCategory = "SCOPE_AND_CONTRACT_DISPUTES_ISSUES"
+	DisputeCategoryIncompleteWork         DisputeCategory = "INCOMPLETE_OR_UNSATISFACTORY_WORK"
+	DisputeCategoryCommunication          DisputeCategory = "COMMUNICATION_PROBLEMS"
+	DisputeCategoryScopeAndContract       DisputeCategory = "SCOPE_AND_CONTRACT_DISPUTES"
+	DisputeCategoryIntellectualProperty   DisputeCategory = "INTELLECTUAL_PROPERTY_AND_CONFIDENTIALITY"
+	DisputeCategoryProfessionalism        DisputeCategory = "PROFESSIONALISM_AND_CONDUCT"
+	DisputeCategoryCancellationAndRefunds DisputeCategory = "CANCELLATION_AND_REFUNDS"
+	DisputeCategoryOthers                 DisputeCategory = "OTHERS"
+)
+
+func (dc *DisputeCategory) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(dc))
+}
+
+func (dc DisputeCategory) Value() (driver.Value, error) {
+	return string(dc), nil
+}
+
+type DisputeEventType string
+
+const (
+	DisputeEventTypeMessage  DisputeEventType = "MESSAGE"
+	DisputeEventTypeResponse DisputeEventType = "RESPONSE"
+	DisputeEventTypeWithdraw DisputeEventType = "WITHDRAW"
+)
+
+func (det *DisputeEventType) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(det))
+}
+
+func (det DisputeEventType) Value() (driver.Value, error) {
+	return string(det), nil
+}
+
+type DisputeSide string
+
+const (
+	DisputeSideClaimant   DisputeSide = "CLAIMANT"
+	DisputeSideRespondent DisputeSide = "RESPONDENT"
+)
+
+func (ds *DisputeSide) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(ds))
+}
+
+func (ds DisputeSide) Value() (driver.Value, error) {
+	return string(ds), nil
+}
+
+type ReferralRewardStatus string
+
+const (
+	ReferralRewardStatusPending   ReferralRewardStatus = "PENDING"
+	ReferralRewardStatusScheduled ReferralRewardStatus = "SCHEDULED"
+	ReferralRewardStatusPaidOut   ReferralRewardStatus = "PAID_OUT"
+)
+
+func (rrs *ReferralRewardStatus) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(rrs))
+}
+
+func (rrs ReferralRewardStatus) Value() (driver.Value, error) {
+	return string(rrs), nil
+}
+
+type ReferralPayoutStatus string
+
+const (
+	ReferralPayoutStatusScheduled ReferralPayoutStatus = "SCHEDULED"
+	ReferralPayoutStatusPaidOut   ReferralPayoutStatus = "PAID_OUT"
+)
+
+func (rps *ReferralPayoutStatus) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(rps))
+}
+
+func (rps ReferralPayoutStatus) Value() (driver.Value, error) {
+	return string(rps), nil
+}
+
+type CryptoDepositStatus string
+
+const (
+	CryptoDepositStatusPending   CryptoDepositStatus = "PENDING"
+	CryptoDepositStatusConfirmed CryptoDepositStatus = "CONFIRMED"
+	CryptoDepositStatusFailed    CryptoDepositStatus = "FAILED"
+)
+
+func (cds *CryptoDepositStatus) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(cds))
+}
+
+func (cds CryptoDepositStatus) Value() (driver.Value, error) {
+	return string(cds), nil
+}
+
+type InvoiceType string
+
+const (
+	InvoiceTypeDeposit InvoiceType = "DEPOSIT"
+	InvoiceTypeRelease InvoiceType = "RELEASE"
+)
+
+func (it *InvoiceType) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(it))
+}
+
+func (it InvoiceType) Value() (driver.Value, error) {
+	return string(it), nil
+}
+
+type WebhookParty string
+
+const (
+	WebhookPartyProofspace WebhookParty = "PROOFSPACE"
+	WebhookPartyStripe     WebhookParty = "STRIPE"
+)
+
+func (wp *WebhookParty) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(wp))
+}
+
+func (wp WebhookParty) Value() (driver.Value, error) {
+	return string(wp), nil
+}
+
 type Currency string
 
 const (
 	USD Currency = "USD"
 	JPY Currency = "JPY"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	CHF Currency = "CHF"
+	DKK Currency = "DKK"
+	AUD Currency = "AUD"
+	CAD Currency = "CAD"
+	MXN Currency = "MXN"
+	BRL Currency = "BRL"
+	ARS Currency = "ARS"
+	CLP Currency = "CLP"
+	COP Currency = "COP"
+	PEN Currency = "PEN"
+	UYU Currency = "UYU"
+	CRC Currency = "CRC"
+	DOP Currency = "DOP"
+	GTQ Currency = "GTQ"
+	INR Currency = "INR"
+	BDT Currency = "BDT"
+	NPR Currency = "NPR"
+	KRW Currency = "KRW"
+	THB Currency = "THB"
+	ZAR Currency = "ZAR"
+	EGP Currency = "EGP"
+	GHS Currency = "GHS"
+	RWF Currency = "RWF"
 )
 
+func (pk Currency) Valid() bool {
+	return validEnum(pk, USD, JPY, EUR, GBP, CHF, DKK, AUD, CAD, MXN, BRL, ARS, CLP, COP, PEN, UYU, CRC,
+		DOP, GTQ, INR, BDT, NPR, KRW, THB, ZAR, EGP, GHS, RWF)
+}
+
 func (pk *Currency) Scan(value interface{}) error {
 	switch v := value.(type) {
 	case []byte:
@@ -307,6 +608,20 @@ func (pk *Currency) Scan(value interface{}) error {
 	return nil
 }
 
+func (pk Currency) Value() (driver.Value, error) {
+	return string(pk), nil
+}
+
+// Decimals returns the digits of the minor unit of the currency
+func (pk Currency) Decimals() int {
+	switch pk {
+	case JPY, KRW, CLP, RWF:
+		return 0
+	default:
+		return 2
+	}
+}
+
 type IdentityType string
 
 const (
@@ -368,6 +683,35 @@ func scanEnum(value interface{}, target interface{}) error {
 	return nil
 }
 
+// validEnum reports whether the value is one of the allowed values of its enum
+func validEnum[T ~string](value T, allowed ...T) bool {
+	for _, v := range allowed {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// SocialCauses are the values of social_causes_type the causes tags of projects are tagged with
+var SocialCauses = []string{
+	"SOCIAL", "POVERTY", "HOMELESSNESS", "HUNGER", "HEALTH", "SUBSTANCE_ABUSE", "MENTAL", "BULLYING",
+	"SECURITY", "EDUCATION", "GENDER_EQUALITY", "GENDER_BASED_VIOLENCE", "SEXUAL_VIOLENCE",
+	"DOMESTIC_VIOLENCE", "WATER_SANITATION", "SUSTAINABLE_ENERGY", "DECENT_WORK", "INEQUALITY",
+	"MINORITY", "MULTICULTURALISM", "DIVERSITY_INCLUSION", "INDIGENOUS_PEOPLES", "DISABILITY",
+	"LGBTQI", "REFUGEE", "MIGRANTS", "ORPHANS", "CHILD_PROTECTION", "COMMUNITY_DEVELOPMENT",
+	"DEPOPULATION", "OVERPOPULATION", "HUMAN_RIGHTS", "SUSTAINABILITY", "RESPONSIBLE_CONSUMPTION",
+	"CLIMATE_CHANGE", "NATURAL_DISASTERS", "BIODIVERSITY", "ANIMAL_RIGHTS", "ARMED_CONFLICT",
+	"PEACEBUILDING", "DEMOCRACY", "CIVIC_ENGAGEMENT", "JUSTICE", "GOVERNANCE", "CRIME_PREVENTION",
+	"CORRUPTION", "OTHER", "RURAL_DEVELOPMENT", "VEGANISM", "BLACK_LIVES_MATTER", "ISLAMOPHOBIA",
+	"ANTI_SEMITISM", "ABORTION", "EUTHANASIA", "NEURODIVERSITY", "SUSTAINABLE_COMMUNITIES",
+	"BIODIVERSITY_LIFE_BELOW_WATER", "PEACE_JUSTICE", "COLLABORATION_FOR_IMPACT", "INNOVATION",
+}
+
+func ValidSocialCause(cause string) bool {
+	return validEnum(cause, SocialCauses...)
+}
+
 type OrganizationStatus string
 
 const (
@@ -400,3 +744,43 @@ func (w *WalletNetwork) Scan(value interface{}) error {
 func (w WalletNetwork) Value() (driver.Value, error) {
 	return string(w), nil
 }
+
+type ApplicantStatus string
+
+const (
+	ApplicantStatusPending     ApplicantStatus = "PENDING"
+	ApplicantStatusShortlisted ApplicantStatus = "SHORTLISTED"
+	ApplicantStatusOffered     ApplicantStatus = "OFFERED"
+	ApplicantStatusRejected    ApplicantStatus = "REJECTED"
+	ApplicantStatusWithdrawn   ApplicantStatus = "WITHDRAWN"
+	ApplicantStatusApproved    ApplicantStatus = "APPROVED"
+	ApplicantStatusHired       ApplicantStatus = "HIRED"
+	ApplicantStatusClosed      ApplicantStatus = "CLOSED"
+)
+
+func (as *ApplicantStatus) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(as))
+}
+
+func (as ApplicantStatus) Value() (driver.Value, error) {
+	return string(as), nil
+}
+
+type ProjectMarkType string
+
+const (
+	ProjectMarkTypeSave          ProjectMarkType = "SAVE"
+	ProjectMarkTypeNotInterested ProjectMarkType = "NOT_INTERESTED"
+)
+
+func (pm *ProjectMarkType) Scan(value interface{}) error {
+	return scanEnum(value, (*string)(pm))
+}
+
+func (pm ProjectMarkType) Value() (driver.Value, error) {
+	return string(pm), nil
+}
+
+func (pm ProjectMarkType) Valid() bool {
+	return validEnum(pm, ProjectMarkTypeSave, ProjectMarkTypeNotInterested)
+}
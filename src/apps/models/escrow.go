@@ -0,0 +1,155 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	database "github.com/socious-io/pkg_database"
+)
+
+type Escrow struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	ContractID *uuid.UUID `db:"contract_id" json:"contract_id"`
+	ProjectID  *uuid.UUID `db:"project_id" json:"project_id"`
+	PaymentID  uuid.UUID  `db:"payment_id" json:"payment_id"`
+	MissionID  *uuid.UUID `db:"mission_id" json:"mission_id"`
+	OfferID    *uuid.UUID `db:"offer_id" json:"offer_id"`
+
+	Amount   *float64  `db:"amount" json:"amount"`
+	Currency *Currency `db:"currency" json:"currency"`
+	Payout   *float64  `db:"payout" json:"payout"`
+	AppFee   *float64  `db:"app_fee" json:"app_fee"`
+
+	ReleaseID  *string `db:"release_id" json:"release_id"`
+	ReleaseKey *string `db:"release_key" json:"-"`
+	RefundID   *string `db:"refund_id" json:"refund_id"`
+	RefundKey  *string `db:"refund_key" json:"-"`
+
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	ReleasedAt *time.Time `db:"released_at" json:"released_at"`
+	RefoundAt  *time.Time `db:"refound_at" json:"refound_at"`
+}
+
+// EscrowSettlement pays the escrowed funds out and returns the reference of the payout transaction.
+// It runs before the escrow is marked settled, so it must complete the payout it recorded already
+// when it's retried after the escrow failed to be marked.
+type EscrowSettlement func() (string, error)
+
+func (Escrow) TableName() string {
+	return "escrows"
+}
+
+func (Escrow) FetchQuery() string {
+	return "escrows/fetch"
+}
+
+// Settled reports whether the escrow has been already released or refunded.
+func (e *Escrow) Settled() bool {
+	return e.ReleasedAt != nil || e.RefoundAt != nil
+}
+
+// Release pays the escrow out to the client once. Retrying with the same idempotency key
+// returns the already released escrow without running the settlement again.
+func (e *Escrow) Release(ctx context.Context, key string, payout, appFee float64, settle EscrowSettlement) error {
+	return e.settle(ctx, key, func(tx *sqlx.Tx, releaseID string) error {
+		return e.scan(database.TxQuery(ctx, tx, "escrows/release", e.ID, key, releaseID, payout, appFee))
+	}, func() bool {
+		return e.ReleaseKey != nil && *e.ReleaseKey == key
+	}, settle)
+}
+
+// Refund pays the escrow back to the provider once, with the same idempotency rules as Release.
+func (e *Escrow) Refund(ctx context.Context, key string, settle EscrowSettlement) error {
+	return e.settle(ctx, key, func(tx *sqlx.Tx, refundID string) error {
+		return e.scan(database.TxQuery(ctx, tx, "escrows/refund", e.ID, key, refundID))
+	}, func() bool {
+		return e.RefundKey != nil && *e.RefundKey == key
+	}, settle)
+}
+
+func (e *Escrow) settle(ctx context.Context, key string, update func(tx *sqlx.Tx, ref string) error, replayed func() bool, settle EscrowSettlement) error {
+	if key == "" {
+		return fmt.Errorf("idempotency key is required")
+	}
+
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	// Locking the escrow row makes concurrent retries wait for the first one to finish
+	if err := e.scan(database.TxQuery(ctx, tx, "escrows/lock", e.ID)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if e.Settled() {
+		tx.Rollback()
+		if replayed() {
+			return nil
+		}
+		return fmt.Errorf("escrow has been already settled")
+	}
+
+	ref, err := settle()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := update(tx, ref); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (e *Escrow) scan(rows *sqlx.Rows, err error) error {
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// HoldEscrow returns the escrow holding the contract payment, creating it on first access.
+func (c *Contract) HoldEscrow(ctx context.Context) (*Escrow, error) {
+	if c.PaymentID == nil {
+		return nil, fmt.Errorf("contract has not been deposited")
+	}
+	return holdEscrow(ctx, *c, *c.PaymentID, c.TotalAmount)
+}
+
+// HoldEscrow returns the escrow holding the milestone payment apart from the contract one.
+func (m *ContractMilestone) HoldEscrow(ctx context.Context, contract Contract) (*Escrow, error) {
+	if m.PaymentID == nil || m.Status == ContractMilestoneStatusPending {
+		return nil, fmt.Errorf("milestone has not been funded")
+	}
+	return holdEscrow(ctx, contract, *m.PaymentID, m.Amount)
+}
+
+func holdEscrow(ctx context.Context, contract Contract, paymentID uuid.UUID, amount float64) (*Escrow, error) {
+	e := new(Escrow)
+	err := e.scan(database.Query(
+		ctx,
+		"escrows/upsert",
+		contract.ID,
+		contract.ProjectID,
+		paymentID,
+		amount,
+		contract.Currency,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+// ExpiryReminder is a project or an unsigned contract about to expire and the identity to remind about it
+type ExpiryReminder struct {
+	ID        uuid.UUID `db:"id"`
+	Title     string    `db:"title"`
+	ExpiresAt time.Time `db:"expires_at"`
+	Email     *string   `db:"email"`
+	Name      *string   `db:"name"`
+}
+
+// ExpireProjects moves the active projects past their expires_at to EXPIRE and returns their ids.
+func ExpireProjects(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := database.Query(ctx, "jobs/expire_projects")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetUnsignedContracts returns the contracts still waiting for signature after ttlDays.
+func GetUnsignedContracts(ttlDays int, limit int) ([]Contract, error) {
+	var (
+		contracts = []Contract{}
+		fetchList []database.FetchList
+		ids       []interface{}
+	)
+
+	if err := database.QuerySelect("jobs/get_unsigned_contracts", &fetchList, ttlDays, limit); err != nil {
+		return nil, err
+	}
+
+	if len(fetchList) < 1 {
+		return contracts, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&contracts, ids...); err != nil {
+		return nil, err
+	}
+	return contracts, nil
+}
+
+// GetExpiringProjects returns the active projects expiring within days which are not reminded yet.
+func GetExpiringProjects(days int, limit int) ([]ExpiryReminder, error) {
+	reminders := []ExpiryReminder{}
+	if err := database.QuerySelect("jobs/get_expiring_projects", &reminders, days, limit); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// GetExpiringContracts returns the unsigned contracts to be canceled within days which are not reminded yet.
+func GetExpiringContracts(ttlDays int, days int, limit int) ([]ExpiryReminder, error) {
+	reminders := []ExpiryReminder{}
+	if err := database.QuerySelect("jobs/get_expiring_contracts", &reminders, ttlDays, days, limit); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// ClaimExpiryReminder marks the project or contract as reminded, it returns false when
+// another run has already claimed it so the reminder is sent once.
+func ClaimExpiryReminder(ctx context.Context, projectID, contractID *uuid.UUID) (bool, error) {
+	rows, err := database.Query(ctx, "jobs/create_reminder", projectID, contractID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	return rows.Next(), nil
+}
+
+// ReleaseExpiryReminder drops the claim of a reminder that couldn't be sent so the next run retries it.
+func ReleaseExpiryReminder(ctx context.Context, projectID, contractID *uuid.UUID) error {
+	rows, err := database.Query(ctx, "jobs/delete_reminder", projectID, contractID)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+// FeePolicy holds the fee rates applied on payments. Policies are never updated, a new version is
+// created instead so contracts priced with an older version keep their amounts reproducible.
+type FeePolicy struct {
+	ID      uuid.UUID `db:"id" json:"id"`
+	Name    string    `db:"name" json:"name"`
+	Version int       `db:"version" json:"version"`
+
+	// Selectors, a nil selector matches any value
+	IdentityID    *uuid.UUID      `db:"identity_id" json:"identity_id"`
+	Verified      *bool           `db:"verified" json:"verified"`
+	Service       *PaymentService `db:"service" json:"service"`
+	Currency      *Currency       `db:"currency" json:"currency"`
+	CryptoNetwork *WalletNetwork  `db:"crypto_network" json:"crypto_network"`
+
+	OrgFee                  float64 `db:"org_fee" json:"org_fee"`
+	UserFee                 float64 `db:"user_fee" json:"user_fee"`
+	StripeFee               float64 `db:"stripe_fee" json:"stripe_fee"`
+	ReferredOrgFeeDiscount  float64 `db:"referred_org_fee_discount" json:"referred_org_fee_discount"`
+	ReferredUserFeeDiscount float64 `db:"referred_user_fee_discount" json:"referred_user_fee_discount"`
+
+	EffectiveFrom time.Time  `db:"effective_from" json:"effective_from"`
+	EffectiveTo   *time.Time `db:"effective_to" json:"effective_to"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// FeePolicySelector describes the payment a policy is resolved for
+type FeePolicySelector struct {
+	IdentityID    *uuid.UUID
+	Verified      bool
+	Service       PaymentService
+	Currency      *Currency
+	CryptoNetwork *WalletNetwork
+}
+
+func (FeePolicy) TableName() string {
+	return "fee_policies"
+}
+
+func (FeePolicy) FetchQuery() string {
+	return "fee_policies/fetch"
+}
+
+// Create stores the policy as the next version of its name.
+func (fp *FeePolicy) Create(ctx context.Context) error {
+	var effectiveFrom *time.Time
+	if !fp.EffectiveFrom.IsZero() {
+		effectiveFrom = &fp.EffectiveFrom
+	}
+
+	rows, err := database.Query(
+		ctx,
+		"fee_policies/create",
+		fp.Name,
+		fp.IdentityID,
+		fp.Verified,
+		fp.Service,
+		fp.Currency,
+		fp.CryptoNetwork,
+		fp.OrgFee,
+		fp.UserFee,
+		fp.StripeFee,
+		fp.ReferredOrgFeeDiscount,
+		fp.ReferredUserFeeDiscount,
+		effectiveFrom,
+		fp.EffectiveTo,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(fp); err != nil {
+			return err
+		}
+	}
+
+	return database.Fetch(fp, fp.ID)
+}
+
+// ResolveFeePolicy returns the active policy matching the selector, identity specific policies
+// come first then the ones with more matching selectors and the latest effective one.
+// It returns nil without error when there is no active policy.
+func ResolveFeePolicy(s FeePolicySelector) (*FeePolicy, error) {
+	fp := new(FeePolicy)
+	if err := database.Get(fp, "fee_policies/resolve", s.IdentityID, s.Verified, s.Service, s.Currency, s.CryptoNetwork); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return fp, nil
+}
+
+func GetFeePolicy(id uuid.UUID) (*FeePolicy, error) {
+	fp := new(FeePolicy)
+	if err := database.Fetch(fp, id); err != nil {
+		return nil, err
+	}
+	return fp, nil
+}
+
+// SnapshotFeePolicy stores the policy version the contract has been priced with, once.
+func (c *Contract) SnapshotFeePolicy(ctx context.Context, policyID uuid.UUID) error {
+	rows, err := database.Query(ctx, "contracts/update_fee_policy", c.ID, policyID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(c); err != nil {
+			return err
+		}
+	}
+	return database.Fetch(c, c.ID)
+}
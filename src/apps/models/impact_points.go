@@ -6,6 +6,9 @@ type CalculateImpactPointsParams struct {
 	Contract Contract
 	Project  Project
 	Category JobCategory
+	// TotalHours the points are calculated for, contract commitment on fixed projects
+	// and the approved work hours on hourly ones
+	TotalHours float64
 }
 
 func calculate(params CalculateImpactPointsParams) float64 {
@@ -17,12 +20,11 @@ func calculate(params CalculateImpactPointsParams) float64 {
 		3: 0.1,
 	}
 
-	contract := params.Contract
 	project := params.Project
 	category := params.Category
 
 	hourlyWage := *category.HourlyWageDollars
-	totalHours := float64(contract.Commitment)                      //handle if null
+	totalHours := params.TotalHours
 	experienceRatio := experienceRatioMap[*project.ExperienceLevel] //handle if null
 
 	totalPoints := hourlyWage * totalHours * (1 + experienceRatio)
@@ -46,10 +48,40 @@ func CalculateImpactPoints(c *Contract) (float64, *CalculateImpactPointsParams,
 		return 0, nil, err
 	}
 
-	if *project.PaymentScheme == PaymentSchemeFixed && contract.Status != ContractStatusCompleted {
+	if *project.PaymentScheme == PaymentSchemeHourly {
+		return 0, nil, fmt.Errorf("impact points of hourly contracts are calculated per submitted work")
+	}
+	if contract.Status != ContractStatusCompleted {
 		return 0, nil, fmt.Errorf("contract is not confirmed")
 	}
 
+	return calculateImpactPoints(*contract, *project, float64(contract.Commitment))
+}
+
+// CalculateWorkImpactPoints calculates the impact points earned by a confirmed work of an hourly contract
+func CalculateWorkImpactPoints(c *Contract, work *SubmittedWork) (float64, *CalculateImpactPointsParams, error) {
+	contract, err := GetContract(c.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	//Getting Contract's project & validate
+	project, err := GetProject(*contract.ProjectID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if *project.PaymentScheme != PaymentSchemeHourly {
+		return 0, nil, fmt.Errorf("project is not on hourly payment scheme")
+	}
+	if work.Status != SubmittedWorkStatusConfirmed {
+		return 0, nil, fmt.Errorf("work is not confirmed")
+	}
+
+	return calculateImpactPoints(*contract, *project, work.TotalHours)
+}
+
+func calculateImpactPoints(contract Contract, project Project, totalHours float64) (float64, *CalculateImpactPointsParams, error) {
 	//Getting Project's category & validate
 	if project.JobCategoryId == nil {
 		return 0, nil, fmt.Errorf("there are no job category for project: %s", project.ID)
@@ -59,42 +91,15 @@ func CalculateImpactPoints(c *Contract) (float64, *CalculateImpactPointsParams,
 		return 0, nil, fmt.Errorf("there are no job category for project: %s", project.ID)
 	}
 
-	totalHours := float64(contract.Commitment)
-	// if project.PaymentScheme == PaymentSchemeFixed {
-	// 	totalHours = float64(contract.Commitment)
-	// } else {
-	// 	//TODO: Fix this if we currently use the submitted_work_id because it was all null
-	// 	// Hourly basis jobs
-	// 	// export const impactPointsCalculatedWorksIds = async (missionId) => {
-	// 	// const { rows } = await app.db.query(sql`
-	// 	// 	SELECT submitted_work_id FROM impact_points_history WHERE mission_id=${missionId}
-	// 	// `)
-	// 	// return rows.map((r) => r.submitted_work_id)
-	// 	// }
-	// 	// const calculatedWorks = await impactPointsCalculatedWorksIds(mission.id)
-	// 	// const works = mission.submitted_works?.filter((w) => !calculatedWorks.includes(w.id))
-
-	// 	// if (!works || works.length < 1) {
-	// 	// 	logger.error(
-	// 	// 	`Faild canculate impact score for ${mission.id},
-	// 	// 	there are no submitted works for this mission that exists or not calculated already`
-	// 	// 	)
-	// 	// 	return false
-	// 	// }
-	// 	// workId = works[0].id
-	// 	// totalHours = works[0].total_hours
-
-	// 	totalHours = float64(contract.Commitment)
-	// }
-
 	if totalHours < 1 {
 		return 0, nil, fmt.Errorf("total hours is under 1 hour")
 	}
 
 	calculateParams := CalculateImpactPointsParams{
-		Contract: *contract,
-		Project:  *project,
-		Category: *category,
+		Contract:   contract,
+		Project:    project,
+		Category:   *category,
+		TotalHours: totalHours,
 	}
 
 	return calculate(calculateParams), &calculateParams, nil
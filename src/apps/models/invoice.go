@@ -0,0 +1,223 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	database "github.com/socious-io/pkg_database"
+)
+
+// InvoiceLineItem is a line of the amounts breakdown in units of the invoice currency
+type InvoiceLineItem struct {
+	Type        AmountLineItemType `json:"type"`
+	Description string             `json:"description"`
+	Amount      float64            `json:"amount"`
+}
+
+// BillingDetails of an identity as they were when the invoice was issued
+type BillingDetails struct {
+	Name    string  `json:"name"`
+	Email   *string `json:"email"`
+	Address *string `json:"address"`
+	City    *string `json:"city"`
+	Country *string `json:"country"`
+	TaxID   *string `json:"tax_id"`
+}
+
+// Invoice of a settled contract payment, numbered in sequence per issuer
+type Invoice struct {
+	ID          uuid.UUID   `db:"id" json:"id"`
+	Number      string      `db:"number" json:"number"`
+	Sequence    int         `db:"sequence" json:"sequence"`
+	Type        InvoiceType `db:"type" json:"type"`
+	IssuerID    uuid.UUID   `db:"issuer_id" json:"issuer_id"`
+	RecipientID uuid.UUID   `db:"recipient_id" json:"recipient_id"`
+	ContractID  uuid.UUID   `db:"contract_id" json:"contract_id"`
+	PaymentID   uuid.UUID   `db:"payment_id" json:"payment_id"`
+
+	Currency         string            `db:"currency" json:"currency"`
+	LineItems        []InvoiceLineItem `db:"-" json:"line_items"`
+	Subtotal         float64           `db:"subtotal" json:"subtotal"`
+	TaxRate          float64           `db:"tax_rate" json:"tax_rate"`
+	TaxAmount        float64           `db:"tax_amount" json:"tax_amount"`
+	Total            float64           `db:"total" json:"total"`
+	IssuerDetails    *BillingDetails   `db:"-" json:"issuer_details"`
+	RecipientDetails *BillingDetails   `db:"-" json:"recipient_details"`
+
+	EmailedAt *time.Time `db:"emailed_at" json:"emailed_at"`
+	IssuedAt  time.Time  `db:"issued_at" json:"issued_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+
+	LineItemsJson        types.JSONText `db:"line_items" json:"-"`
+	IssuerDetailsJson    types.JSONText `db:"issuer_details" json:"-"`
+	RecipientDetailsJson types.JSONText `db:"recipient_details" json:"-"`
+}
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+func (Invoice) FetchQuery() string {
+	return "invoices/fetch"
+}
+
+// Create numbers the invoice next in the sequence of its issuer, the number is taken back when it fails.
+func (i *Invoice) Create(ctx context.Context) error {
+	lineItems, err := json.Marshal(i.LineItems)
+	if err != nil {
+		return err
+	}
+	issuerDetails, err := json.Marshal(i.IssuerDetails)
+	if err != nil {
+		return err
+	}
+	recipientDetails, err := json.Marshal(i.RecipientDetails)
+	if err != nil {
+		return err
+	}
+
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "invoices/next_sequence", i.IssuerID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for rows.Next() {
+		if err := rows.Scan(&i.Sequence); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	rows.Close()
+	i.Number = fmt.Sprintf("INV-%06d", i.Sequence)
+
+	rows, err = database.TxQuery(
+		ctx,
+		tx,
+		"invoices/create",
+		i.Number,
+		i.Sequence,
+		i.Type,
+		i.IssuerID,
+		i.RecipientID,
+		i.ContractID,
+		i.PaymentID,
+		i.Currency,
+		lineItems,
+		i.Subtotal,
+		i.TaxRate,
+		i.TaxAmount,
+		i.Total,
+		issuerDetails,
+		recipientDetails,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(i); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return database.Fetch(i, i.ID)
+}
+
+// IsParty returns whether the identity issued or received the invoice
+func (i Invoice) IsParty(identityID uuid.UUID) bool {
+	return i.IssuerID == identityID || i.RecipientID == identityID
+}
+
+func (i *Invoice) MarkEmailed(ctx context.Context) error {
+	rows, err := database.Query(ctx, "invoices/update_emailed", i.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func GetInvoice(id uuid.UUID) (*Invoice, error) {
+	i := new(Invoice)
+	if err := database.Fetch(i, id); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func GetInvoiceByPayment(paymentID uuid.UUID, invoiceType InvoiceType) (*Invoice, error) {
+	var row database.FetchList
+	if err := database.Get(&row, "invoices/get_by_payment", paymentID, invoiceType); err != nil {
+		return nil, err
+	}
+	return GetInvoice(row.ID)
+}
+
+// GetInvoices returns the invoices issued by or to the identity, the latest first
+func GetInvoices(identityID uuid.UUID, p database.Paginate) ([]Invoice, int, error) {
+	var (
+		invoices  = []Invoice{}
+		fetchList []database.FetchList
+		ids       []interface{}
+	)
+
+	if err := database.QuerySelect("invoices/get", &fetchList, identityID, p.Limit, p.Offet); err != nil {
+		return nil, 0, err
+	}
+	if len(fetchList) < 1 {
+		return invoices, 0, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+	if err := database.Fetch(&invoices, ids...); err != nil {
+		return nil, 0, err
+	}
+	return invoices, fetchList[0].TotalCount, nil
+}
+
+// GetNotEmailedInvoices returns the invoices not emailed yet, the older first
+func GetNotEmailedInvoices(limit int) ([]Invoice, error) {
+	var (
+		invoices  = []Invoice{}
+		fetchList []database.FetchList
+		ids       []interface{}
+	)
+
+	if err := database.QuerySelect("invoices/get_not_emailed", &fetchList, limit); err != nil {
+		return nil, err
+	}
+	if len(fetchList) < 1 {
+		return invoices, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+	if err := database.Fetch(&invoices, ids...); err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
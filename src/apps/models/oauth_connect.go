@@ -69,3 +69,14 @@ func (oc *OauthConnect) Upsert(ctx context.Context) error {
 	}
 	return nil
 }
+
+// UpdateStatus activates or deactivates the connected account.
+func (oc *OauthConnect) UpdateStatus(ctx context.Context, status UserStatus) error {
+	rows, err := database.Query(ctx, "oauth_connects/update_status", oc.ID, status)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	oc.Status = status
+	return nil
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/socious-io/gomq"
@@ -58,6 +59,7 @@ type Project struct {
 	Promoted              *bool                    `db:"promoted" json:"promoted"`
 	Kind                  ProjectKind              `db:"kind" json:"kind"`
 	WorkSamples           []WorkSampleDocuments    `db:"-" json:"work_samples"`
+	Questions             []Question               `db:"-" json:"questions"`
 	Identity              *Identity                `db:"-" json:"identity"`
 
 	CreatedAt time.Time  `db:"created_at" json:"created_at"`
@@ -66,6 +68,7 @@ type Project struct {
 	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at"`
 
 	WorkSamplesJson types.JSONText  `db:"work_samples" json:"-"`
+	QuestionsJson   types.JSONText  `db:"questions" json:"-"`
 	JobCategoryJson *types.JSONText `db:"job_category" json:"job_category"`
 	IdentityJson    types.JSONText  `db:"identity" json:"-"`
 }
@@ -95,7 +98,52 @@ func (JobCategory) FetchQuery() string {
 	return "projects/fetch_job_category"
 }
 
+// OwnerID returns the identity the project is owned by
+func (p Project) OwnerID() uuid.UUID {
+	return p.IdentityID
+}
+
+// Validate checks the enum fields of the project hold one of their allowed values
+func (p Project) Validate() error {
+	invalid := func(field string, value interface{}) error {
+		return fmt.Errorf("invalid %s %v", field, value)
+	}
+	if !p.Kind.Valid() {
+		return invalid("kind", p.Kind)
+	}
+	if p.Status != nil && !p.Status.Valid() {
+		return invalid("status", *p.Status)
+	}
+	if p.ProjectType != nil && !p.ProjectType.Valid() {
+		return invalid("project_type", *p.ProjectType)
+	}
+	if p.ProjectLength != nil && !p.ProjectLength.Valid() {
+		return invalid("project_length", *p.ProjectLength)
+	}
+	if p.PaymentType != nil && !p.PaymentType.Valid() {
+		return invalid("payment_type", *p.PaymentType)
+	}
+	if p.PaymentScheme != nil && !p.PaymentScheme.Valid() {
+		return invalid("payment_scheme", *p.PaymentScheme)
+	}
+	if p.PaymentMode != nil && !p.PaymentMode.Valid() {
+		return invalid("payment_mode", *p.PaymentMode)
+	}
+	if p.RemotePreference != nil && !p.RemotePreference.Valid() {
+		return invalid("remote_preference", *p.RemotePreference)
+	}
+	for _, cause := range p.CausesTags {
+		if !ValidSocialCause(cause) {
+			return invalid("causes_tags", cause)
+		}
+	}
+	return nil
+}
+
 func (p *Project) Create(ctx context.Context, workSamples []uuid.UUID) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
 	tx, err := database.GetDB().Beginx()
 	if err != nil {
 		return err
@@ -171,6 +219,10 @@ func (p *Project) Create(ctx context.Context, workSamples []uuid.UUID) error {
 }
 
 func (p *Project) Update(ctx context.Context, workSamples []uuid.UUID) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	p.UpdatedAt = time.Now()
 
 	tx, err := database.GetDB().Beginx()
 	if err != nil {
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+// ProjectMark is the project an identity saved or is not interested in, an identity has one mark per project.
+type ProjectMark struct {
+	ID         uuid.UUID       `db:"id" json:"id"`
+	IdentityID uuid.UUID       `db:"identity_id" json:"identity_id"`
+	ProjectID  uuid.UUID       `db:"project_id" json:"project_id"`
+	MarkedAs   ProjectMarkType `db:"marked_as" json:"marked_as"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+func (ProjectMark) TableName() string {
+	return "project_marks"
+}
+
+func (ProjectMark) FetchQuery() string {
+	return "project_marks/fetch"
+}
+
+// Create marks the project, marking it again replaces the previous mark.
+func (m *ProjectMark) Create(ctx context.Context) error {
+	rows, err := database.Query(ctx, "project_marks/upsert", m.IdentityID, m.ProjectID, m.MarkedAs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DeleteProjectMark(ctx context.Context, identityID, projectID uuid.UUID) error {
+	rows, err := database.Query(ctx, "project_marks/delete", identityID, projectID)
+	if err != nil {
+		return err
+	}
+	rows.Close()
+	return nil
+}
+
+// GetMarkedProjects returns the projects the identity marked, latest marked first
+func GetMarkedProjects(identityID uuid.UUID, markedAs string, p database.Paginate) ([]Project, int, error) {
+	var (
+		projects  = []Project{}
+		fetchList []database.FetchList
+		ids       []interface{}
+	)
+
+	if err := database.QuerySelect("projects/get_marked", &fetchList, identityID, p.Limit, p.Offet, markedAs); err != nil {
+		return nil, 0, err
+	}
+
+	if len(fetchList) < 1 {
+		return projects, 0, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&projects, ids...); err != nil {
+		return nil, 0, err
+	}
+	return sortProjects(projects, fetchList), fetchList[0].TotalCount, nil
+}
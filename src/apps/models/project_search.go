@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	database "github.com/socious-io/pkg_database"
+)
+
+// ProjectSearch is the full-text query and the filters active projects are searched with,
+// list filters match projects having any of their values.
+type ProjectSearch struct {
+	Query             string
+	Skills            []string
+	CausesTags        []string
+	Kinds             []string
+	RemotePreferences []string
+	ProjectTypes      []string
+	PaymentTypes      []string
+	ExperienceLevels  []int64
+	Country           string
+	City              string
+	GeonameID         *int
+	Radius            *float64
+	PaymentMin        *float64
+	PaymentMax        *float64
+	PaymentCurrency   string
+	// Projects the identity marked as not interested are left out
+	IdentityID *uuid.UUID
+}
+
+// ProjectFacet is the count of the searched projects having the value of a filter
+type ProjectFacet struct {
+	Facet string `db:"facet" json:"-"`
+	Value string `db:"value" json:"value"`
+	Count int    `db:"count" json:"count"`
+}
+
+// NewProjectSearch reads the search from the paginate filters, lists are comma separated
+// and the radius around the geoname is in kilometers.
+func NewProjectSearch(query string, filters []database.Filter) (*ProjectSearch, error) {
+	s := &ProjectSearch{Query: strings.TrimSpace(query)}
+	for _, filter := range filters {
+		value := strings.TrimSpace(filter.Value)
+		if value == "" {
+			continue
+		}
+		switch filter.Key {
+		case "skills":
+			s.Skills = splitFilter(value, false)
+		case "causes_tags":
+			s.CausesTags = splitFilter(value, true)
+		case "kind":
+			s.Kinds = splitFilter(value, true)
+		case "remote_preference":
+			s.RemotePreferences = splitFilter(value, true)
+		case "project_type":
+			s.ProjectTypes = splitFilter(value, true)
+		case "payment_type":
+			s.PaymentTypes = splitFilter(value, true)
+		case "experience_level":
+			for _, v := range splitFilter(value, false) {
+				level, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid experience_level %s", v)
+				}
+				s.ExperienceLevels = append(s.ExperienceLevels, level)
+			}
+		case "country":
+			s.Country = value
+		case "city":
+			s.City = value
+		case "payment_currency":
+			s.PaymentCurrency = value
+		case "geoname_id":
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid geoname_id %s", value)
+			}
+			s.GeonameID = &id
+		case "radius", "payment_min", "payment_max":
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("invalid %s %s", filter.Key, value)
+			}
+			switch filter.Key {
+			case "radius":
+				s.Radius = &v
+			case "payment_min":
+				s.PaymentMin = &v
+			case "payment_max":
+				s.PaymentMax = &v
+			}
+		}
+	}
+	if s.Radius != nil && s.GeonameID == nil {
+		return nil, fmt.Errorf("radius requires geoname_id")
+	}
+	return s, nil
+}
+
+func splitFilter(value string, upper bool) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		if upper {
+			v = strings.ToUpper(v)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func (s ProjectSearch) args() []interface{} {
+	return []interface{}{
+		s.Query,
+		pq.Array(s.Skills),
+		pq.Array(s.CausesTags),
+		pq.Array(s.Kinds),
+		pq.Array(s.RemotePreferences),
+		pq.Array(s.ProjectTypes),
+		pq.Array(s.PaymentTypes),
+		pq.Array(s.ExperienceLevels),
+		s.Country,
+		s.City,
+		s.GeonameID,
+		s.Radius,
+		s.PaymentMin,
+		s.PaymentMax,
+		s.PaymentCurrency,
+		s.IdentityID,
+	}
+}
+
+// SearchProjects returns the page of matching projects, best ranked for the query first
+func SearchProjects(s ProjectSearch, p database.Paginate) ([]Project, int, error) {
+	var (
+		projects  = []Project{}
+		fetchList []database.FetchList
+		ids       []interface{}
+	)
+
+	args := append(s.args(), p.Limit, p.Offet)
+	if err := database.QuerySelect("projects/search", &fetchList, args...); err != nil {
+		return nil, 0, err
+	}
+
+	if len(fetchList) < 1 {
+		return projects, 0, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&projects, ids...); err != nil {
+		return nil, 0, err
+	}
+	return sortProjects(projects, fetchList), fetchList[0].TotalCount, nil
+}
+
+// sortProjects puts the fetched projects back in the order of the list they are fetched for
+func sortProjects(projects []Project, fetchList []database.FetchList) []Project {
+	byID := map[uuid.UUID]Project{}
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+	sorted := []Project{}
+	for _, f := range fetchList {
+		if project, ok := byID[f.ID]; ok {
+			sorted = append(sorted, project)
+		}
+	}
+	return sorted
+}
+
+// GetProjectFacets counts the matching projects per value of the filters
+func GetProjectFacets(s ProjectSearch) (map[string][]ProjectFacet, error) {
+	rows := []ProjectFacet{}
+	if err := database.QuerySelect("projects/search_facets", &rows, s.args()...); err != nil {
+		return nil, err
+	}
+	facets := map[string][]ProjectFacet{}
+	for _, f := range []string{"skills", "causes_tags", "kind", "remote_preference", "project_type", "payment_type", "experience_level", "country"} {
+		facets[f] = []ProjectFacet{}
+	}
+	for _, row := range rows {
+		facets[row.Facet] = append(facets[row.Facet], row)
+	}
+	return facets, nil
+}
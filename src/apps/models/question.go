@@ -0,0 +1,182 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	database "github.com/socious-io/pkg_database"
+)
+
+// Question is a screening question applicants to the project answer, questions with options
+// are multiple choice and answered with the index of the selected option.
+type Question struct {
+	ID        uuid.UUID      `db:"id" json:"id"`
+	ProjectID uuid.UUID      `db:"project_id" json:"project_id"`
+	Question  string         `db:"question" json:"question"`
+	Required  bool           `db:"required" json:"required"`
+	Options   pq.StringArray `db:"options" json:"options"`
+	OldId     *int           `db:"old_id" json:"-"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (Question) TableName() string {
+	return "questions"
+}
+
+func (Question) FetchQuery() string {
+	return "questions/fetch"
+}
+
+func (q *Question) validate() error {
+	q.Question = strings.TrimSpace(q.Question)
+	if q.Question == "" {
+		return fmt.Errorf("question is required")
+	}
+	if len(q.Options) == 0 {
+		q.Options = nil
+		return nil
+	}
+	if len(q.Options) < 2 {
+		return fmt.Errorf("multiple choice question needs at least 2 options")
+	}
+	for i, option := range q.Options {
+		if q.Options[i] = strings.TrimSpace(option); q.Options[i] == "" {
+			return fmt.Errorf("option %d is empty", i)
+		}
+	}
+	return nil
+}
+
+func (q *Question) Create(ctx context.Context) error {
+	if err := q.validate(); err != nil {
+		return err
+	}
+	rows, err := database.Query(ctx, "questions/create", q.ProjectID, q.Question, q.Required, q.Options)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update saves the question, answers keep the index of the option they selected so the options
+// of an answered question can not be changed.
+func (q *Question) Update(ctx context.Context) error {
+	if err := q.validate(); err != nil {
+		return err
+	}
+	stored, err := GetQuestion(q.ID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(stored.Options, q.Options) {
+		answered, err := q.Answered()
+		if err != nil {
+			return err
+		}
+		if answered {
+			return fmt.Errorf("options of answered question can not be changed")
+		}
+	}
+	rows, err := database.Query(ctx, "questions/update", q.ID, q.Question, q.Required, q.Options)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes the question, answered questions are kept with their answers.
+func (q *Question) Delete(ctx context.Context) error {
+	answered, err := q.Answered()
+	if err != nil {
+		return err
+	}
+	if answered {
+		return fmt.Errorf("answered question can not be deleted")
+	}
+	rows, err := database.Query(ctx, "questions/delete", q.ID)
+	if err != nil {
+		return err
+	}
+	rows.Close()
+	return nil
+}
+
+// Answered reports whether any applicant answered the question
+func (q *Question) Answered() (bool, error) {
+	var answered bool
+	if err := database.Get(&answered, "questions/answered", q.ID); err != nil {
+		return false, err
+	}
+	return answered, nil
+}
+
+func GetQuestion(id uuid.UUID) (*Question, error) {
+	q := new(Question)
+	if err := database.Fetch(q, id); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func GetQuestions(projectID uuid.UUID) ([]Question, error) {
+	questions := []Question{}
+	if err := database.QuerySelect("questions/get", &questions, projectID); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// ValidateAnswers checks the answers are to the questions, each answered once, and every
+// required question is answered. Free text answers can not be blank and multiple choice
+// answers select one of the options.
+func ValidateAnswers(questions []Question, answers []Answer) error {
+	byID := map[uuid.UUID]Question{}
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+
+	answered := map[uuid.UUID]bool{}
+	for _, a := range answers {
+		q, ok := byID[a.QuestionID]
+		if !ok {
+			return fmt.Errorf("question %s is not on the project", a.QuestionID)
+		}
+		if answered[q.ID] {
+			return fmt.Errorf("question %s is answered more than once", q.ID)
+		}
+		if len(q.Options) > 0 {
+			if a.SelectedOption == nil || *a.SelectedOption < 0 || *a.SelectedOption >= len(q.Options) {
+				return fmt.Errorf("question %s needs one of its options selected", q.ID)
+			}
+		} else if a.Answer == nil || strings.TrimSpace(*a.Answer) == "" {
+			return fmt.Errorf("question %s needs an answer", q.ID)
+		}
+		answered[q.ID] = true
+	}
+
+	for _, q := range questions {
+		if q.Required && !answered[q.ID] {
+			return fmt.Errorf("question %s is required", q.ID)
+		}
+	}
+	return nil
+}
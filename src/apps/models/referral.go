@@ -0,0 +1,251 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	database "github.com/socious-io/pkg_database"
+)
+
+// ReferralReward is the share of the platform fee credited to a referrer once a payment of a contract
+// of the referred identity is released
+type ReferralReward struct {
+	ID                 uuid.UUID            `db:"id" json:"id"`
+	ReferrerID         uuid.UUID            `db:"referrer_id" json:"referrer_id"`
+	ReferredIdentityID uuid.UUID            `db:"referred_identity_id" json:"referred_identity_id"`
+	ContractID         uuid.UUID            `db:"contract_id" json:"contract_id"`
+	PaymentID          *uuid.UUID           `db:"payment_id" json:"payment_id"`
+	Amount             float64              `db:"amount" json:"amount"`
+	Currency           string               `db:"currency" json:"currency"`
+	CryptoNetwork      *WalletNetwork       `db:"crypto_network" json:"crypto_network"`
+	Status             ReferralRewardStatus `db:"status" json:"status"`
+	PayoutID           *uuid.UUID           `db:"payout_id" json:"payout_id"`
+	ScheduledAt        *time.Time           `db:"scheduled_at" json:"scheduled_at"`
+	CreatedAt          time.Time            `db:"created_at" json:"created_at"`
+}
+
+// ReferralPayout schedules the transfer of the pending rewards of a referrer in one currency, crypto rewards
+// are paid to their wallet and fiat ones to their Stripe account of the fiat service.
+type ReferralPayout struct {
+	ID          uuid.UUID            `db:"id" json:"id"`
+	ReferrerID  uuid.UUID            `db:"referrer_id" json:"referrer_id"`
+	WalletID    *uuid.UUID           `db:"wallet_id" json:"wallet_id"`
+	Address     string               `db:"address" json:"address"`
+	Network     *WalletNetwork       `db:"network" json:"network"`
+	FiatService *string              `db:"fiat_service" json:"fiat_service"`
+	Currency    string               `db:"currency" json:"currency"`
+	Amount      float64              `db:"amount" json:"amount"`
+	Status      ReferralPayoutStatus `db:"status" json:"status"`
+	TxID        *string              `db:"tx_id" json:"tx_id"`
+	PaidAt      *time.Time           `db:"paid_at" json:"paid_at"`
+	CreatedAt   time.Time            `db:"created_at" json:"created_at"`
+}
+
+// PendingReferralPayout is the sum of the pending rewards of a referrer and the wallet or Stripe account to pay them to
+type PendingReferralPayout struct {
+	ReferrerID    uuid.UUID      `db:"referrer_id"`
+	Currency      string         `db:"currency"`
+	CryptoNetwork *WalletNetwork `db:"crypto_network"`
+	WalletID      *uuid.UUID     `db:"wallet_id"`
+	Address       string         `db:"address"`
+	Network       *WalletNetwork `db:"network"`
+	FiatService   *string        `db:"fiat_service"`
+	Amount        float64        `db:"amount"`
+}
+
+type ReferralEarning struct {
+	Currency  string  `json:"currency"`
+	Pending   float64 `json:"pending"`
+	Scheduled float64 `json:"scheduled"`
+	PaidOut   float64 `json:"paid_out"`
+}
+
+// Referral is an identity referred by the user with what the user earned from it
+type Referral struct {
+	IdentityID uuid.UUID         `db:"referred_identity_id" json:"identity_id"`
+	Identity   *Identity         `db:"-" json:"identity"`
+	Earnings   []ReferralEarning `db:"-" json:"earnings"`
+	CreatedAt  time.Time         `db:"created_at" json:"created_at"`
+	TotalCount int               `db:"total_count" json:"-"`
+
+	IdentityJson types.JSONText `db:"identity" json:"-"`
+	EarningsJson types.JSONText `db:"earnings" json:"-"`
+}
+
+func (ReferralReward) TableName() string {
+	return "referral_rewards"
+}
+
+// Create credits the reward once per released payment and referred identity, it returns false when already credited.
+func (r *ReferralReward) Create(ctx context.Context) (bool, error) {
+	rows, err := database.Query(
+		ctx,
+		"referrals/create_reward",
+		r.ReferrerID,
+		r.ReferredIdentityID,
+		r.ContractID,
+		r.PaymentID,
+		r.Amount,
+		r.Currency,
+		r.CryptoNetwork,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	created := false
+	for rows.Next() {
+		if err := rows.StructScan(r); err != nil {
+			return false, err
+		}
+		created = true
+	}
+	return created, nil
+}
+
+func GetReferrals(referrerID uuid.UUID, p database.Paginate) ([]Referral, int, error) {
+	referrals := []Referral{}
+	if err := database.QuerySelect("referrals/get", &referrals, referrerID, p.Limit, p.Offet); err != nil {
+		return nil, 0, err
+	}
+	if len(referrals) < 1 {
+		return referrals, 0, nil
+	}
+	if err := database.UnmarshalJSONTextFields(&referrals); err != nil {
+		return nil, 0, err
+	}
+	return referrals, referrals[0].TotalCount, nil
+}
+
+// GetPendingReferralPayouts returns the pending rewards grouped per referrer and currency, crypto rewards only for
+// referrers with a registered wallet on the network of the rewards and fiat ones for referrers with a Stripe account.
+func GetPendingReferralPayouts(limit int) ([]PendingReferralPayout, error) {
+	payouts := []PendingReferralPayout{}
+	if err := database.QuerySelect("referrals/get_pending_payouts", &payouts, limit); err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
+
+// Settle records the payout to the wallet and marks its rewards as scheduled in one transaction.
+func (p PendingReferralPayout) Settle(ctx context.Context) (*ReferralPayout, error) {
+	payout := new(ReferralPayout)
+
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "referrals/create_payout", p.ReferrerID, p.WalletID, p.Address, p.Network, p.Currency, p.FiatService)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(payout); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	rows.Close()
+
+	rows, err = database.TxQuery(ctx, tx, "referrals/settle_rewards", payout.ID, p.ReferrerID, p.Currency, p.CryptoNetwork)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(payout); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return payout, nil
+}
+
+func GetReferralPayout(id uuid.UUID) (*ReferralPayout, error) {
+	p := new(ReferralPayout)
+	if err := database.Get(p, "referrals/get_payout", id); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetScheduledReferralPayouts returns the payouts not paid out yet, oldest first
+func GetScheduledReferralPayouts(limit int) ([]ReferralPayout, error) {
+	payouts := []ReferralPayout{}
+	if err := database.QuerySelect("referrals/get_scheduled_payouts", &payouts, limit); err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
+
+// Transferred records the transaction the crypto payout has been sent with from the platform wallet,
+// the payout is paid out once the transaction is confirmed. A wrong transaction can be replaced until then.
+func (p *ReferralPayout) Transferred(ctx context.Context, txID string) error {
+	rows, err := database.Query(ctx, "referrals/transfer_payout", p.ID, txID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	updated := false
+	for rows.Next() {
+		if err := rows.StructScan(p); err != nil {
+			return err
+		}
+		updated = true
+	}
+	if !updated {
+		return fmt.Errorf("payout is %s and can't be transferred", p.Status)
+	}
+	return nil
+}
+
+// PayOut marks the payout and its rewards paid out with the transfer reference in one transaction.
+func (p *ReferralPayout) PayOut(ctx context.Context, txID string) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "referrals/pay_payout", p.ID, txID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	paid := false
+	for rows.Next() {
+		if err := rows.StructScan(p); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		paid = true
+	}
+	rows.Close()
+	if !paid {
+		tx.Rollback()
+		return fmt.Errorf("payout is already %s", p.Status)
+	}
+
+	rows, err = database.TxQuery(ctx, tx, "referrals/pay_rewards", p.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rows.Close()
+
+	return tx.Commit()
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+type SubmittedWork struct {
+	ID          uuid.UUID           `db:"id" json:"id"`
+	ContractID  *uuid.UUID          `db:"contract_id" json:"contract_id"`
+	ProjectID   *uuid.UUID          `db:"project_id" json:"project_id"`
+	MissionID   *uuid.UUID          `db:"mission_id" json:"-"`
+	Description *string             `db:"description" json:"description"`
+	Status      SubmittedWorkStatus `db:"status" json:"status"`
+	TotalHours  float64             `db:"total_hours" json:"total_hours"`
+
+	Amount *float64 `db:"amount" json:"amount"`
+	Payout *float64 `db:"payout" json:"payout"`
+	AppFee *float64 `db:"app_fee" json:"app_fee"`
+
+	StartAt    time.Time  `db:"start_at" json:"start_at"`
+	EndAt      time.Time  `db:"end_at" json:"end_at"`
+	ReviewedAt *time.Time `db:"reviewed_at" json:"reviewed_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (SubmittedWork) TableName() string {
+	return "submitted_works"
+}
+
+func (SubmittedWork) FetchQuery() string {
+	return "submitted_works/fetch"
+}
+
+// Create logs the work period of the contract, periods can not overlap the other non rejected works.
+func (w *SubmittedWork) Create(ctx context.Context) error {
+	if !w.EndAt.After(w.StartAt) {
+		return fmt.Errorf("end_at should be after start_at")
+	}
+	if w.EndAt.After(time.Now()) {
+		return fmt.Errorf("work can't be submitted for the future")
+	}
+	w.TotalHours = w.EndAt.Sub(w.StartAt).Hours()
+
+	overlap := new(database.FetchList)
+	err := database.Get(overlap, "submitted_works/get_overlap", w.ContractID, w.StartAt, w.EndAt)
+	if err == nil {
+		return fmt.Errorf("work period overlaps with another submitted work")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	rows, err := database.Query(
+		ctx,
+		"submitted_works/create",
+		w.ContractID,
+		w.ProjectID,
+		w.Description,
+		w.StartAt,
+		w.EndAt,
+		w.TotalHours,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(w); err != nil {
+			return err
+		}
+	}
+
+	return database.Fetch(w, w.ID)
+}
+
+// Approve confirms the pending work with the amount it is paid for and its payout and fee. The contract
+// is locked while approving so concurrent approvals never take the approved works over its total amount.
+func (w *SubmittedWork) Approve(ctx context.Context, amount, payout, appFee float64) error {
+	if w.ContractID == nil {
+		return fmt.Errorf("work has no contract")
+	}
+
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "contracts/lock", *w.ContractID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rows.Close()
+
+	rows, err = database.TxQuery(ctx, tx, "submitted_works/approve", w.ID, amount, payout, appFee)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	approved := false
+	for rows.Next() {
+		if err := rows.StructScan(w); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		approved = true
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if err := database.Fetch(w, w.ID); err != nil {
+		return err
+	}
+	if !approved {
+		if w.Status == SubmittedWorkStatusPending {
+			return fmt.Errorf("approved works exceed contract total amount")
+		}
+		return fmt.Errorf("work is already %s", w.Status)
+	}
+	return nil
+}
+
+func (w *SubmittedWork) Reject(ctx context.Context) error {
+	return w.review(ctx, "submitted_works/reject")
+}
+
+func (w *SubmittedWork) review(ctx context.Context, queryName string, args ...interface{}) error {
+	rows, err := database.Query(ctx, queryName, append([]interface{}{w.ID}, args...)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	reviewed := false
+	for rows.Next() {
+		if err := rows.StructScan(w); err != nil {
+			return err
+		}
+		reviewed = true
+	}
+	if !reviewed {
+		return fmt.Errorf("work is already %s", w.Status)
+	}
+
+	return database.Fetch(w, w.ID)
+}
+
+func GetSubmittedWork(id uuid.UUID, contractID uuid.UUID) (*SubmittedWork, error) {
+	w := new(SubmittedWork)
+	if err := database.Fetch(w, id); err != nil {
+		return nil, err
+	}
+	if w.ContractID == nil || *w.ContractID != contractID {
+		return nil, fmt.Errorf("work not found")
+	}
+	return w, nil
+}
+
+func GetContractSubmittedWorks(contractID uuid.UUID, p database.Paginate) ([]SubmittedWork, int, error) {
+	var (
+		works     = []SubmittedWork{}
+		fetchList []database.FetchList
+		ids       []interface{}
+	)
+
+	if err := database.QuerySelect("submitted_works/get", &fetchList, contractID, p.Limit, p.Offet); err != nil {
+		return nil, 0, err
+	}
+
+	if len(fetchList) < 1 {
+		return works, 0, nil
+	}
+
+	for _, f := range fetchList {
+		ids = append(ids, f.ID)
+	}
+
+	if err := database.Fetch(&works, ids...); err != nil {
+		return nil, 0, err
+	}
+	return works, fetchList[0].TotalCount, nil
+}
+
+// HourlyRate is the contract amount paid for each hour of the committed work.
+func (c *Contract) HourlyRate() (float64, error) {
+	if c.Commitment < 1 {
+		return 0, fmt.Errorf("contract has no commitment hours")
+	}
+	return c.TotalAmount / float64(c.Commitment), nil
+}
+
+// IsHourly reports whether the contract is paid per submitted work of its hourly project.
+func (c *Contract) IsHourly() (bool, error) {
+	if c.ProjectID == nil {
+		return false, nil
+	}
+	project, err := GetProject(*c.ProjectID)
+	if err != nil {
+		return false, err
+	}
+	return project.PaymentScheme != nil && *project.PaymentScheme == PaymentSchemeHourly, nil
+}
@@ -10,14 +10,33 @@ import (
 	database "github.com/socious-io/pkg_database"
 )
 
+// Wallet is registered by a user for their own identity or an organization they're a member of,
+// the primary wallet of the identity on a network is the one it's paid to.
 type Wallet struct {
-	ID        uuid.UUID     `json:"id"`
-	UserID    uuid.UUID     `json:"user_id"`
-	Address   string        `json:"address"`
-	Network   WalletNetwork `json:"network"`
-	Testnet   bool          `json:"testnet"`
-	CreatedAt time.Time     `json:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at"`
+	ID         uuid.UUID     `db:"id" json:"id"`
+	UserID     uuid.UUID     `db:"user_id" json:"user_id"`
+	IdentityID uuid.UUID     `db:"identity_id" json:"identity_id"`
+	Address    string        `db:"address" json:"address"`
+	Network    WalletNetwork `db:"network" json:"network"`
+	Testnet    bool          `db:"testnet" json:"testnet"`
+	Primary    bool          `db:"is_primary" json:"primary"`
+	VerifiedAt *time.Time    `db:"verified_at" json:"verified_at"`
+	CreatedAt  time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// WalletChallenge is the message a user signs with the wallet to prove owning its address
+type WalletChallenge struct {
+	ID         uuid.UUID     `db:"id" json:"id"`
+	UserID     uuid.UUID     `db:"user_id" json:"-"`
+	IdentityID uuid.UUID     `db:"identity_id" json:"identity_id"`
+	Address    string        `db:"address" json:"address"`
+	Network    WalletNetwork `db:"network" json:"network"`
+	Testnet    bool          `db:"testnet" json:"testnet"`
+	Message    string        `db:"message" json:"message"`
+	ExpiresAt  time.Time     `db:"expires_at" json:"expires_at"`
+	UsedAt     *time.Time    `db:"used_at" json:"-"`
+	CreatedAt  time.Time     `db:"created_at" json:"created_at"`
 }
 
 type Wallets []Wallet
@@ -32,9 +51,11 @@ func (w *Wallet) Upsert(ctx context.Context) error {
 		"wallets/upsert",
 		w.ID,
 		w.UserID,
+		w.IdentityID,
 		w.Address,
 		w.Network,
 		w.Testnet,
+		w.VerifiedAt,
 	)
 	if err != nil {
 		return err
@@ -48,6 +69,81 @@ func (w *Wallet) Upsert(ctx context.Context) error {
 	return nil
 }
 
+// Delete removes the wallet, the mainnet wallet of the identity on the network becomes primary in place of a primary one.
+func (w *Wallet) Delete(ctx context.Context) error {
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "wallets/delete", w.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rows.Close()
+
+	if w.Primary {
+		rows, err = database.TxQuery(ctx, tx, "wallets/promote", w.IdentityID, w.Network)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		rows.Close()
+	}
+	return tx.Commit()
+}
+
+// SetPrimary makes the wallet the primary one of the identity on its network, contracts pay out to mainnet
+// so testnet wallets can't be primary.
+func (w *Wallet) SetPrimary(ctx context.Context) error {
+	if w.Testnet {
+		return fmt.Errorf("testnet wallets can't be primary")
+	}
+	tx, err := database.GetDB().Beginx()
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.TxQuery(ctx, tx, "wallets/unset_primary", w.IdentityID, w.Network)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rows.Close()
+
+	rows, err = database.TxQuery(ctx, tx, "wallets/set_primary", w.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for rows.Next() {
+		if err := rows.StructScan(w); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+	}
+	rows.Close()
+	return tx.Commit()
+}
+
+func GetWallet(id, identityID uuid.UUID) (*Wallet, error) {
+	w := new(Wallet)
+	if err := database.Get(w, "wallets/get_by_identity", id, identityID); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func GetWallets(identityID uuid.UUID) ([]Wallet, error) {
+	wallets := []Wallet{}
+	if err := database.QuerySelect("wallets/get", &wallets, identityID); err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
 func (w *Wallets) Scan(src interface{}) error {
 	if src == nil {
 		*w = []Wallet{}
@@ -59,3 +155,56 @@ func (w *Wallets) Scan(src interface{}) error {
 	}
 	return json.Unmarshal(bytes, w)
 }
+
+func (wc *WalletChallenge) Create(ctx context.Context) error {
+	rows, err := database.Query(
+		ctx,
+		"wallets/create_challenge",
+		wc.UserID,
+		wc.IdentityID,
+		wc.Address,
+		wc.Network,
+		wc.Testnet,
+		wc.Message,
+		wc.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(wc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Use consumes the challenge so its signature can't be replayed, it fails when the challenge is used or expired.
+func (wc *WalletChallenge) Use(ctx context.Context) error {
+	rows, err := database.Query(ctx, "wallets/use_challenge", wc.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	used := false
+	for rows.Next() {
+		if err := rows.StructScan(wc); err != nil {
+			return err
+		}
+		used = true
+	}
+	if !used {
+		return fmt.Errorf("challenge is expired or already used")
+	}
+	return nil
+}
+
+func GetWalletChallenge(id, userID uuid.UUID) (*WalletChallenge, error) {
+	wc := new(WalletChallenge)
+	if err := database.Get(wc, "wallets/get_challenge", id, userID); err != nil {
+		return nil, err
+	}
+	return wc, nil
+}
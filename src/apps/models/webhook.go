@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	database "github.com/socious-io/pkg_database"
+)
+
+// Webhook is a raw event received from a third party, stored once per event id
+type Webhook struct {
+	ID                 uuid.UUID       `db:"id" json:"id"`
+	Party              WebhookParty    `db:"party" json:"party"`
+	EventID            *string         `db:"event_id" json:"event_id"`
+	EventType          *string         `db:"event_type" json:"event_type"`
+	FiatServiceName    *string         `db:"fiat_service_name" json:"fiat_service_name"`
+	Content            types.JSONText  `db:"content" json:"content"`
+	Response           *types.JSONText `db:"response" json:"response"`
+	ResponseStatusCode *int            `db:"response_status_code" json:"response_status_code"`
+	ResponseAt         *time.Time      `db:"response_at" json:"response_at"`
+	ProcessedAt        *time.Time      `db:"processed_at" json:"processed_at"`
+	CreatedAt          time.Time       `db:"created_at" json:"created_at"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Create stores the event, it returns false when the event id has been received before.
+func (w *Webhook) Create(ctx context.Context) (bool, error) {
+	rows, err := database.Query(ctx, "webhooks/create", w.Party, w.EventID, w.EventType, w.FiatServiceName, w.Content)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	created := false
+	for rows.Next() {
+		if err := rows.StructScan(w); err != nil {
+			return false, err
+		}
+		created = true
+	}
+	return created, nil
+}
+
+// Respond records the handling result, only successful ones mark the event as processed so failures are retried.
+func (w *Webhook) Respond(ctx context.Context, statusCode int, response types.JSONText) error {
+	rows, err := database.Query(ctx, "webhooks/update_response", w.ID, response, statusCode)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.StructScan(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func GetWebhookByEvent(party WebhookParty, eventID string) (*Webhook, error) {
+	w := new(Webhook)
+	if err := database.Get(w, "webhooks/get_by_event", party, eventID); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
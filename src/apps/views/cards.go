@@ -0,0 +1,128 @@
+package views
+
+import (
+	"context"
+	"net/http"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func cardsGroup(router *gin.Engine) {
+	g := router.Group("cards")
+	g.Use(LoginRequired())
+
+	g.GET("", func(c *gin.Context) {
+		identity, err := memberIdentity(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		cards, err := models.GetCards(identity.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"results": cards, "total": len(cards)})
+	})
+
+	g.POST("", func(c *gin.Context) {
+		user := c.MustGet("user").(*models.User)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		identity, err := memberIdentity(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		form := new(CardForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		params := lib.SaveCardParams{
+			IdentityID: identity.ID,
+			Fiat:       lib.CardFiat(form.IsJp),
+			Email:      user.Email,
+		}
+		if form.SetupIntentID != nil {
+			params.SetupIntentID = *form.SetupIntentID
+		}
+		if form.PaymentMethodID != nil {
+			params.PaymentMethodID = *form.PaymentMethodID
+		}
+		if params.SetupIntentID == "" && params.PaymentMethodID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "setup_intent_id or payment_method_id is required"})
+			return
+		}
+		if email, ok := identity.MetaMap["email"].(string); ok && email != "" {
+			params.Email = email
+		}
+
+		card, err := lib.Cards().SaveCard(ctx, params)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		card.IdentityId = identity.ID
+		card.IsJp = form.IsJp
+		if err := card.Create(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, card)
+	})
+
+	g.POST("/:id/default", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		identity, err := memberIdentity(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		card, err := models.GetCard(uuid.MustParse(c.Param("id")), identity.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err := card.SetDefault(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, card)
+	})
+
+	g.DELETE("/:id", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		identity, err := memberIdentity(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		card, err := models.GetCard(uuid.MustParse(c.Param("id")), identity.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err := lib.Cards().DeleteCard(ctx, lib.CardFiat(card.IsJp), *card); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := card.Delete(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+}
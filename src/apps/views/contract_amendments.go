@@ -0,0 +1,152 @@
+package views
+
+import (
+	"context"
+	"net/http"
+	"socious/src/apps/models"
+	"socious/src/apps/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func contractAmendmentsGroup(router *gin.Engine) {
+	g := router.Group("contracts/:id/amendments")
+	g.Use(LoginRequired())
+
+	g.GET("", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		amendments, err := models.GetContractAmendments(contract.ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": amendments,
+			"total":   len(amendments),
+		})
+	})
+
+	g.POST("", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(ContractAmendmentForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		terms := models.ContractTerms{}
+		utils.Copy(form, &terms)
+		amendment, err := contract.ProposeAmendment(ctx, identity.ID, terms, form.Reason)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, amendment)
+	})
+
+	g.POST("/:amendment_id/accept", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		contract, amendment, err := contractAmendment(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil || amendment.ProposerID == identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just the other party can accept the amendment"})
+			return
+		}
+
+		if err := amendment.Accept(ctx, contract, identity.ID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"contract":  contract,
+			"amendment": amendment,
+		})
+	})
+
+	g.POST("/:amendment_id/reject", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		contract, amendment, err := contractAmendment(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil || amendment.ProposerID == identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just the other party can reject the amendment"})
+			return
+		}
+
+		if err := amendment.Reject(ctx, identity.ID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, amendment)
+	})
+
+	g.POST("/:amendment_id/withdraw", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		_, amendment, err := contractAmendment(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if amendment.ProposerID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just proposer can withdraw the amendment"})
+			return
+		}
+
+		if err := amendment.Withdraw(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, amendment)
+	})
+}
+
+func contractAmendment(c *gin.Context) (*models.Contract, *models.ContractAmendment, error) {
+	contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+	if err != nil {
+		return nil, nil, err
+	}
+	amendment, err := models.GetContractAmendment(uuid.MustParse(c.Param("amendment_id")), contract.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contract, amendment, nil
+}
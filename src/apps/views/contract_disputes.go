@@ -0,0 +1,315 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"socious/src/apps/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+func contractDisputesGroup(router *gin.Engine) {
+	g := router.Group("contracts/:id/disputes")
+	g.Use(LoginRequired())
+
+	g.GET("", paginate(), func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		page, _ := c.Get("paginate")
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		disputes, total, err := models.GetContractDisputes(contract.ID, page.(database.Paginate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": disputes,
+			"total":   total,
+		})
+	})
+
+	g.GET("/:dispute_id", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		contract, dispute, err := contractDispute(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil && !dispute.IsJuror(identity.ID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		c.JSON(http.StatusOK, dispute)
+	})
+
+	g.POST("", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(DisputeForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if form.Message == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "message is required"})
+			return
+		}
+		if form.Category == "" {
+			form.Category = models.DisputeCategoryOthers
+		}
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		party, err := contract.PartyOf(identity.ID)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		switch contract.Status {
+		case models.ContractStatusSinged, models.ContractStatusApplied, models.ContractStatusCompleted:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Can't open dispute on %s contract", contract.Status)})
+			return
+		}
+		if contract.PaymentID != nil {
+			escrow, err := contract.HoldEscrow(ctx)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if escrow.Settled() {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Contract payment has been already settled"})
+				return
+			}
+		}
+
+		dispute := &models.Dispute{
+			Title:        form.Title,
+			Category:     form.Category,
+			ClaimantID:   identity.ID,
+			RespondentID: contract.ClientID,
+			ContractID:   &contract.ID,
+		}
+		if party == models.ContractPartyClient {
+			dispute.RespondentID = contract.ProviderID
+		}
+
+		if err := dispute.Create(ctx, form.Message, form.Evidences); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, dispute)
+	})
+
+	g.POST("/:dispute_id/respond", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(DisputeResponseForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if form.Message == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "message is required"})
+			return
+		}
+
+		_, dispute, err := contractDispute(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if dispute.RespondentID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just respondent can respond to the dispute"})
+			return
+		}
+
+		if err := dispute.Respond(ctx, form.Message, form.Evidences); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, dispute)
+	})
+
+	g.POST("/:dispute_id/withdraw", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(DisputeWithdrawForm)
+		if err := c.ShouldBindJSON(form); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		_, dispute, err := contractDispute(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if dispute.ClaimantID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just claimant can withdraw the dispute"})
+			return
+		}
+
+		if err := dispute.Withdraw(ctx, form.Message); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, dispute)
+	})
+
+	g.POST("/:dispute_id/vote", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(DisputeVoteForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if form.Side != models.DisputeSideClaimant && form.Side != models.DisputeSideRespondent {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "side should be CLAIMANT or RESPONDENT"})
+			return
+		}
+
+		contract, dispute, err := contractDispute(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if !dispute.IsJuror(identity.ID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just jurors can vote on the dispute"})
+			return
+		}
+
+		winner, err := dispute.Vote(ctx, identity.ID, form.Side)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if winner != nil {
+			if err := applyDisputeOutcome(ctx, contract, dispute, *winner); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := dispute.Resolve(ctx, *winner); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.JSON(http.StatusAccepted, dispute)
+	})
+
+	g.POST("/:dispute_id/resolve", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		contract, dispute, err := contractDispute(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if dispute.ClaimantID != identity.ID && dispute.RespondentID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just dispute parties can resolve the dispute"})
+			return
+		}
+		if !dispute.Overdue() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dispute can be resolved after the voting deadline only"})
+			return
+		}
+
+		winner := dispute.OverdueWinner()
+		if err := applyDisputeOutcome(ctx, contract, dispute, winner); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := dispute.Resolve(ctx, winner); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, dispute)
+	})
+}
+
+func contractDispute(c *gin.Context) (*models.Contract, *models.Dispute, error) {
+	contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+	if err != nil {
+		return nil, nil, err
+	}
+	dispute, err := models.GetDispute(uuid.MustParse(c.Param("dispute_id")))
+	if err != nil {
+		return nil, nil, err
+	}
+	if dispute.ContractID == nil || *dispute.ContractID != contract.ID {
+		return nil, nil, fmt.Errorf("dispute not found")
+	}
+	return contract, dispute, nil
+}
+
+// applyDisputeOutcome releases the escrow to the client when the client wins the dispute
+// and refunds it to the provider otherwise, then moves the contract to its final status.
+func applyDisputeOutcome(ctx context.Context, contract *models.Contract, dispute *models.Dispute, winner models.DisputeSide) error {
+	key := fmt.Sprintf("dispute-%s", dispute.ID)
+	reason := fmt.Sprintf("dispute %s resolved", dispute.Code)
+
+	if dispute.SideIdentity(winner) == contract.ClientID {
+		if contract.PaymentID != nil {
+			if _, err := releaseContractEscrow(ctx, contract, key); err != nil {
+				return err
+			}
+		}
+		if err := releaseMilestoneEscrows(ctx, contract); err != nil {
+			return err
+		}
+		if contract.Status == models.ContractStatusCompleted {
+			return nil
+		}
+		if err := contract.TransitOnBehalf(ctx, models.ContractPartyProvider, models.ContractStatusCompleted, &reason); err != nil {
+			return err
+		}
+		//TODO: use Nats
+		go addContractImpactPoints(contract)
+		return nil
+	}
+
+	if contract.PaymentID != nil {
+		if _, err := refundContractEscrow(ctx, contract, key); err != nil {
+			return err
+		}
+	}
+	if _, err := refundMilestoneEscrows(ctx, contract, key); err != nil {
+		return err
+	}
+	if contract.IsTerminal() {
+		return nil
+	}
+	return contract.TransitOnBehalf(ctx, models.ContractPartyProvider, models.ContractStatusProviderCanceled, &reason)
+}
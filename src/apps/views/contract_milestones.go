@@ -0,0 +1,396 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"socious/src/apps/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/socious-io/gopay"
+)
+
+func contractMilestonesGroup(router *gin.Engine) {
+	g := router.Group("contracts/:id/milestones")
+	g.Use(LoginRequired())
+
+	g.GET("", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		milestones, err := models.GetContractMilestones(contract.ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		orgReferrer, _ := models.GetReferring(contract.ProviderID)
+		userReferrer, _ := models.GetReferring(contract.ClientID)
+		// Milestones are priced with the fee policy of the contract, it's resolved once
+		options := lib.AmountsOptionsFromContract(*contract, orgReferrer, userReferrer)
+		for i := range milestones {
+			options.Amount = milestones[i].Amount
+			milestones[i].Amounts = lib.CalculateAmounts(options)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"results": milestones,
+			"total":   len(milestones),
+		})
+	})
+
+	g.GET("/:milestone_id", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		milestone, err := models.GetContractMilestone(uuid.MustParse(c.Param("milestone_id")), contract.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		orgReferrer, _ := models.GetReferring(contract.ProviderID)
+		userReferrer, _ := models.GetReferring(contract.ClientID)
+		milestone.Amounts = lib.CalculateAmounts(lib.AmountsOptionsFromMilestone(*contract, *milestone, orgReferrer, userReferrer))
+
+		c.JSON(http.StatusOK, milestone)
+	})
+
+	g.POST("", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(ContractMilestoneForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if contract.ProviderID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+		if contract.IsTerminal() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "contract is not active"})
+			return
+		}
+		if contract.PaymentID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "deposited contracts can't be split into milestones"})
+			return
+		}
+		if err := contract.ValidateMilestoneAmount(nil, form.Amount); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		milestone := new(models.ContractMilestone)
+		utils.Copy(form, milestone)
+		milestone.ContractID = contract.ID
+		if err := milestone.Create(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, milestone)
+	})
+
+	g.PATCH("/:milestone_id", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(ContractMilestoneForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if contract.ProviderID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		milestone, err := models.GetContractMilestone(uuid.MustParse(c.Param("milestone_id")), contract.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err := contract.ValidateMilestoneAmount(&milestone.ID, form.Amount); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		utils.Copy(form, milestone)
+		if err := milestone.Update(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, milestone)
+	})
+
+	g.DELETE("/:milestone_id", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if contract.ProviderID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		milestone, err := models.GetContractMilestone(uuid.MustParse(c.Param("milestone_id")), contract.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err := milestone.Delete(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	g.POST("/:milestone_id/fund", func(c *gin.Context) {
+		user := c.MustGet("user").(*models.User)
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(ContractDepositForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if contract.ProviderID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just provider can fund the milestone"})
+			return
+		}
+		if contract.IsTerminal() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "contract is not active"})
+			return
+		}
+		// Contracts deposited in full are escrowed already, funding milestones would escrow the work twice
+		if contract.PaymentID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "contract is already deposited"})
+			return
+		}
+		if err := contract.ValidateMilestonesTotal(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		milestone, err := models.GetContractMilestone(uuid.MustParse(c.Param("milestone_id")), contract.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if milestone.Status != models.ContractMilestoneStatusPending {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "milestone is already funded"})
+			return
+		}
+		// A failed deposit can be retried, a pending one is funded once confirmed
+		if milestone.PaymentID != nil {
+			linked, err := gopay.Fetch(*milestone.PaymentID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if linked.Status == gopay.INITIATED || linked.Status == gopay.PENDING_DEPOSIT || linked.Status == gopay.ON_HOLD {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "milestone deposit is pending confirmation"})
+				return
+			}
+		}
+
+		description := milestone.Title
+		if milestone.Description != nil {
+			description = *milestone.Description
+		}
+
+		payment, err := depositContract(ctx, user, identity, contract, gopay.PaymentParams{
+			Tag:         milestone.Title,
+			Description: description,
+			Ref:         milestone.ID.String(),
+			TotalAmount: milestone.Amount,
+		}, form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Crypto deposits and fiat ones requiring an action fund the milestone once confirmed
+		if payment.Status == gopay.DEPOSITED {
+			err = milestone.Fund(ctx, payment.ID)
+		} else {
+			err = milestone.LinkPayment(ctx, payment.ID)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, milestone)
+	})
+
+	g.POST("/:milestone_id/approve", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if contract.ProviderID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just provider can approve the milestone"})
+			return
+		}
+		if contract.Status != models.ContractStatusSinged && contract.Status != models.ContractStatusApplied {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "milestones can be approved on signed contracts only"})
+			return
+		}
+		frozen, err := contract.HasOpenDispute()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if frozen {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Contract payout is frozen by an open dispute"})
+			return
+		}
+
+		milestone, err := models.GetContractMilestone(uuid.MustParse(c.Param("milestone_id")), contract.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if milestone.Status != models.ContractMilestoneStatusFunded {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("milestone is %s and can't be approved", milestone.Status)})
+			return
+		}
+		if err := releaseMilestoneEscrow(ctx, contract, milestone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := milestone.Approve(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		approved, err := contract.MilestonesApproved()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if approved {
+			reason := "all milestones approved"
+			if err := contract.Transit(ctx, identity.ID, models.ContractStatusCompleted, &reason); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			//TODO: use Nats
+			go addContractImpactPoints(contract)
+		}
+
+		c.JSON(http.StatusAccepted, milestone)
+	})
+}
+
+// releaseMilestoneEscrows releases every funded milestone of the contract not approved yet
+func releaseMilestoneEscrows(ctx context.Context, contract *models.Contract) error {
+	milestones, err := models.GetContractMilestones(contract.ID)
+	if err != nil {
+		return err
+	}
+	for i := range milestones {
+		if milestones[i].Status != models.ContractMilestoneStatusFunded {
+			continue
+		}
+		if err := releaseMilestoneEscrow(ctx, contract, &milestones[i]); err != nil {
+			return err
+		}
+		if err := milestones[i].Approve(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseMilestoneEscrow pays the milestone escrow out to the client with payout and fees of the milestone,
+// the release is keyed per milestone so retrying an approval never pays it twice.
+func releaseMilestoneEscrow(ctx context.Context, contract *models.Contract, milestone *models.ContractMilestone) error {
+	if err := checkChargeback(contract); err != nil {
+		return err
+	}
+	escrow, err := milestone.HoldEscrow(ctx, *contract)
+	if err != nil {
+		return err
+	}
+
+	orgReferrer, _ := models.GetReferring(contract.ProviderID)
+	userReferrer, _ := models.GetReferring(contract.ClientID)
+	amounts := lib.CalculateAmounts(lib.AmountsOptionsFromMilestone(*contract, *milestone, orgReferrer, userReferrer))
+	if err := amounts.Check(); err != nil {
+		return err
+	}
+	payout, appFee := amounts.Major(amounts.Payout), amounts.Major(amounts.AppFee)
+
+	key := fmt.Sprintf("milestone-%s", milestone.ID)
+	err = escrow.Release(ctx, key, payout, appFee, func() (string, error) {
+		return settleContractPayment(contract, *milestone.PaymentID, key, gopay.PAID_OUT, escrowPayout{
+			identityID: contract.ClientID,
+			tag:        "milestone",
+			amount:     payout,
+			fee:        appFee,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	creditReferralRewards(ctx, contract, *milestone.PaymentID, amounts, orgReferrer, userReferrer)
+	if _, err := lib.IssueInvoice(ctx, *contract, *milestone.PaymentID, models.InvoiceTypeRelease, amounts); err != nil {
+		fmt.Println(fmt.Errorf("failed to invoice release of milestone: %s; error: %v", milestone.ID, err))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package views
+
+import (
+	"net/http"
+	"socious/src/apps/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func contractSignaturesGroup(router *gin.Engine) {
+	g := router.Group("contracts/:id/signatures")
+	g.Use(LoginRequired())
+
+	g.GET("", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		signatures, err := models.GetContractSignatures(contract.ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": signatures,
+			"total":   len(signatures),
+		})
+	})
+
+	g.GET("/verify", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		signatures, err := models.GetContractSignatures(contract.ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		valid := len(signatures) > 0
+		results := []gin.H{}
+		for _, s := range signatures {
+			matched, hash, err := s.Verify(contract)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			valid = valid && matched
+			results = append(results, gin.H{
+				"signature_id": s.ID,
+				"identity_id":  s.IdentityID,
+				"signed_hash":  s.Hash,
+				"current_hash": hash,
+				"changed":      !matched,
+				"signed_at":    s.CreatedAt,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"valid":   valid,
+			"results": results,
+		})
+	})
+}
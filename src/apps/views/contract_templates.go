@@ -0,0 +1,106 @@
+package views
+
+import (
+	"context"
+	"net/http"
+	"socious/src/apps/models"
+	"socious/src/apps/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+func contractTemplatesGroup(router *gin.Engine) {
+	g := router.Group("contract-templates")
+	g.Use(LoginRequired())
+
+	g.GET("", paginate(), func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		page, _ := c.Get("paginate")
+
+		templates, total, err := models.GetContractTemplates(identity.ID, page.(database.Paginate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": templates,
+			"total":   total,
+		})
+	})
+
+	g.GET("/:id", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		template, err := models.GetContractTemplate(uuid.MustParse(c.Param("id")), identity.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, template)
+	})
+
+	g.POST("", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(ContractTemplateForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		template := new(models.ContractTemplate)
+		utils.Copy(form, template)
+		template.IdentityID = identity.ID
+		if err := template.Create(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, template)
+	})
+
+	g.PATCH("/:id", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(ContractTemplateForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		template, err := models.GetContractTemplate(uuid.MustParse(c.Param("id")), identity.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		utils.Copy(form, template)
+		if err := template.Update(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, template)
+	})
+
+	g.DELETE("/:id", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		template, err := models.GetContractTemplate(uuid.MustParse(c.Param("id")), identity.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err := template.Delete(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+}
@@ -0,0 +1,196 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+func contractWorksGroup(router *gin.Engine) {
+	g := router.Group("contracts/:id/works")
+	g.Use(LoginRequired())
+
+	g.GET("", paginate(), func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		page, _ := c.Get("paginate")
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		works, total, err := models.GetContractSubmittedWorks(contract.ID, page.(database.Paginate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results":         works,
+			"total":           total,
+			"approved_hours":  contract.ApprovedHours,
+			"approved_amount": contract.ApprovedAmount,
+		})
+	})
+
+	g.POST("", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		form := new(ContractWorkForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if contract.ClientID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just client can submit works"})
+			return
+		}
+		if contract.Status != models.ContractStatusSinged && contract.Status != models.ContractStatusApplied {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "works can be submitted on signed contracts only"})
+			return
+		}
+		if err := hourlyContract(contract); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		work := &models.SubmittedWork{
+			ContractID:  &contract.ID,
+			ProjectID:   contract.ProjectID,
+			Description: form.Description,
+			StartAt:     form.StartAt,
+			EndAt:       form.EndAt,
+		}
+		if err := work.Create(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, work)
+	})
+
+	g.POST("/:work_id/approve", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		contract, work, err := contractWork(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if contract.ProviderID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just provider can approve the work"})
+			return
+		}
+		frozen, err := contract.HasOpenDispute()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if frozen {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Contract payout is frozen by an open dispute"})
+			return
+		}
+		// Works approved once the escrow is released would never be paid out
+		if contract.Status != models.ContractStatusSinged && contract.Status != models.ContractStatusApplied {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "works can be approved on signed contracts only"})
+			return
+		}
+		if err := hourlyContract(contract); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rate, err := contract.HourlyRate()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		amount := rate * work.TotalHours
+		if contract.ApprovedAmount+amount > contract.TotalAmount {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("approved works total %v exceeds contract total amount %v", contract.ApprovedAmount+amount, contract.TotalAmount)})
+			return
+		}
+
+		orgReferrer, _ := models.GetReferring(contract.ProviderID)
+		userReferrer, _ := models.GetReferring(contract.ClientID)
+		amounts := lib.CalculateAmounts(lib.AmountsOptionsFromWork(*contract, amount, orgReferrer, userReferrer))
+		if err := amounts.Check(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := work.Approve(ctx, amount, amounts.Major(amounts.Payout), amounts.Major(amounts.AppFee)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		//TODO: use Nats
+		go addWorkImpactPoints(contract, work)
+
+		c.JSON(http.StatusAccepted, work)
+	})
+
+	g.POST("/:work_id/reject", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		contract, work, err := contractWork(c)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if contract.ProviderID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just provider can reject the work"})
+			return
+		}
+
+		if err := work.Reject(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, work)
+	})
+}
+
+// hourlyContract refuses contracts that are not paid per submitted work
+func hourlyContract(contract *models.Contract) error {
+	hourly, err := contract.IsHourly()
+	if err != nil {
+		return err
+	}
+	if !hourly {
+		return fmt.Errorf("works are submitted on hourly contracts only")
+	}
+	return nil
+}
+
+func contractWork(c *gin.Context) (*models.Contract, *models.SubmittedWork, error) {
+	contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+	if err != nil {
+		return nil, nil, err
+	}
+	work, err := models.GetSubmittedWork(uuid.MustParse(c.Param("work_id")), contract.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contract, work, nil
+}
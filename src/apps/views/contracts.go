@@ -2,14 +2,18 @@ package views
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"socious/src/apps/lib"
 	"socious/src/apps/models"
 	"socious/src/apps/utils"
+	"socious/src/config"
 
 	"github.com/socious-io/goaccount"
+	"github.com/socious-io/gomq"
 	"github.com/socious-io/gopay"
 	database "github.com/socious-io/pkg_database"
 
@@ -52,11 +56,91 @@ func contractsGroup(router *gin.Engine) {
 		c.JSON(http.StatusOK, contract)
 	})
 
+	g.GET("/:id/history", paginate(), func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		page, _ := c.Get("paginate")
+
+		id := c.Param("id")
+
+		contract, err := models.GetContract(uuid.MustParse(id))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		history, total, err := models.GetContractStatusHistory(contract.ID, page.(database.Paginate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": history,
+			"total":   total,
+		})
+	})
+
+	g.GET("/:id/pdf", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		contract, err := models.GetContract(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := contract.PartyOf(identity.ID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		signatures, err := models.GetContractSignatures(contract.ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		orgReferrer, _ := models.GetReferring(contract.ProviderID)
+		userReferrer, _ := models.GetReferring(contract.ClientID)
+		pdf, err := lib.ContractPdf(config.Config.Contracts.Template, lib.ContractPdfParams{
+			Contract:   *contract,
+			Amounts:    lib.CalculateAmounts(lib.AmountsOptionsFromContract(*contract, orgReferrer, userReferrer)),
+			Signatures: signatures,
+			VerifyURL:  fmt.Sprintf("%s/contracts/%s/signatures/verify", config.Config.Host, contract.ID),
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=contract-%s.pdf", contract.ID))
+		c.Data(http.StatusOK, "application/pdf", pdf)
+	})
+
 	g.POST("", func(c *gin.Context) {
 		identity := c.MustGet("identity").(*models.Identity)
 		ctx := c.MustGet("ctx").(context.Context)
 
 		form := new(ContractForm)
+
+		// Template fields pre-fill the form and the request body overrides them per field
+		if templateID := c.Query("template_id"); templateID != "" {
+			id, err := uuid.Parse(templateID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template_id"})
+				return
+			}
+			template, err := models.GetContractTemplate(id, identity.ID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			utils.Copy(template, form)
+		}
+
 		if err := c.ShouldBindJSON(form); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -66,15 +150,11 @@ func contractsGroup(router *gin.Engine) {
 		utils.Copy(form, contract)
 		contract.ProviderID = identity.ID
 		contract.ClientID = form.ClientID
-		if err := contract.Create(ctx); err != nil {
+		if err := createContract(ctx, contract); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		orgReferrer, _ := models.GetReferring(contract.ProviderID)
-		userReferrer, _ := models.GetReferring(contract.ClientID)
-		contract.Amounts = lib.CalculateAmounts(lib.AmountsOptionsFromContract(*contract, orgReferrer, userReferrer))
-
 		c.JSON(http.StatusCreated, contract)
 	})
 
@@ -84,7 +164,7 @@ func contractsGroup(router *gin.Engine) {
 
 		id := c.Param("id")
 
-		form := new(ContractForm)
+		form := new(ContractUpdateForm)
 		if err := c.ShouldBindJSON(form); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -100,6 +180,13 @@ func contractsGroup(router *gin.Engine) {
 			return
 		}
 
+		terms := models.ContractTerms{}
+		utils.Copy(form, &terms)
+		if _, _, changed := contract.DiffTerms(terms); changed && contract.TermsLocked() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Contract terms can be changed by accepted amendments only"})
+			return
+		}
+
 		utils.Copy(form, contract)
 
 		if err := contract.Update(ctx.(context.Context), nil); err != nil {
@@ -115,19 +202,20 @@ func contractsGroup(router *gin.Engine) {
 
 		id := c.Param("id")
 
-		contract, err := models.GetContract(uuid.MustParse(id))
-		if err != nil {
+		form := new(ContractTransitionForm)
+		if err := c.ShouldBindJSON(form); err != nil && err != io.EOF {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		if contract.ClientID != identity.ID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Just client can sign the contract"})
+		contract, err := models.GetContract(uuid.MustParse(id))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		contract.Status = models.ContractStatusSinged
 
-		if err := contract.Update(ctx.(context.Context), nil); err != nil {
+		ip, userAgent := c.ClientIP(), c.Request.UserAgent()
+		if _, err := contract.Sign(ctx.(context.Context), identity.ID, form.Reason, &ip, &userAgent); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -140,22 +228,30 @@ func contractsGroup(router *gin.Engine) {
 
 		id := c.Param("id")
 
+		form := new(ContractTransitionForm)
+		if err := c.ShouldBindJSON(form); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		contract, err := models.GetContract(uuid.MustParse(id))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		if contract.ProviderID == identity.ID {
-			contract.Status = models.ContractStatusProviderCanceled
-		} else if contract.ClientID == identity.ID {
-			contract.Status = models.ContractStatusClientCanceled
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Provider or Client don't match identity"})
+		party, err := contract.PartyOf(identity.ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		if err := contract.Update(ctx.(context.Context), nil); err != nil {
+		status := models.ContractStatusClientCanceled
+		if party == models.ContractPartyProvider {
+			status = models.ContractStatusProviderCanceled
+		}
+
+		if err := contract.Transit(ctx.(context.Context), identity.ID, status, form.Reason); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -168,19 +264,19 @@ func contractsGroup(router *gin.Engine) {
 
 		id := c.Param("id")
 
-		contract, err := models.GetContract(uuid.MustParse(id))
-		if err != nil {
+		form := new(ContractTransitionForm)
+		if err := c.ShouldBindJSON(form); err != nil && err != io.EOF {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		if contract.ClientID != identity.ID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Just client can set the contract to applied"})
+		contract, err := models.GetContract(uuid.MustParse(id))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		contract.Status = models.ContractStatusApplied
 
-		if err := contract.Update(ctx.(context.Context), nil); err != nil {
+		if err := contract.Transit(ctx.(context.Context), identity.ID, models.ContractStatusApplied, form.Reason); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -193,55 +289,25 @@ func contractsGroup(router *gin.Engine) {
 
 		id := c.Param("id")
 
-		contract, err := models.GetContract(uuid.MustParse(id))
-		if err != nil {
+		form := new(ContractTransitionForm)
+		if err := c.ShouldBindJSON(form); err != nil && err != io.EOF {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		if contract.ProviderID != identity.ID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Just provider can complete the contract"})
+		contract, err := models.GetContract(uuid.MustParse(id))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		contract.Status = models.ContractStatusCompleted
 
-		if err := contract.Update(ctx.(context.Context), nil); err != nil {
+		if err := contract.Transit(ctx.(context.Context), identity.ID, models.ContractStatusCompleted, form.Reason); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
 		//TODO: use Nats
-		go func() {
-			impactPoints, params, err := models.CalculateImpactPoints(contract)
-			if err != nil {
-				fmt.Println(fmt.Errorf("failed to calculate impact point for contract: %s; error: %v", contract.ID, err))
-				return
-			}
-
-			impactPointType := "WORKSUBMIT"
-			if contract.Type == models.ContractTypeVolunteer {
-				impactPointType = "VOLUNTEER"
-			}
-
-			ip := goaccount.ImpactPoint{
-				UserID:              contract.ClientID,
-				TotalPoints:         int(math.Floor(impactPoints)),
-				SocialCause:         params.Project.CausesTags[0],
-				SocialCauseCategory: string(utils.GetSDG(params.Project.CausesTags[0])),
-				Type:                impactPointType,
-				Meta: map[string]any{
-					"contract": contract,
-					"project":  params.Project,
-					"category": params.Category,
-				},
-				UniqueTag: contract.ID.String(),
-				Value:     float64(contract.Commitment),
-			}
-			err = ip.AddImpactPoint()
-			if err != nil {
-				fmt.Println(fmt.Errorf("failed to add impact point for contract: %s; error %v", contract.ID, err))
-			}
-		}()
+		go addContractImpactPoints(contract)
 
 		c.JSON(http.StatusAccepted, contract)
 	})
@@ -281,23 +347,28 @@ func contractsGroup(router *gin.Engine) {
 			return
 		}
 
-		// Fetching Client
-		provider, err := models.GetIdentity(contract.ProviderID)
+		if contract.ProviderID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just provider can deposit the contract"})
+			return
+		}
+		if contract.Status != models.ContractStatusSinged && contract.Status != models.ContractStatusApplied {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "contracts can be deposited once signed only"})
+			return
+		}
+		// The escrow of a deposited payment is released or refunded with the contract, it can't be replaced
+		if contract.PaymentID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "contract is already deposited"})
+			return
+		}
+		// Contracts split into milestones are escrowed per milestone, depositing them in full would fund the work twice
+		split, err := contract.HasMilestones()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("provider fetch error : %v", err)})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-
-		// Determine Currency
-		var currency gopay.Currency
-		if contract.Currency == nil && *contract.PaymentType == models.PaymentModeTypeFiat {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Currency is nil in Fiat payment : %v", err)})
+		if split {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "contract is funded per milestone"})
 			return
-		} else if contract.Currency == nil {
-			//Default payment is set not to prevent the runtime from crashing while its empty
-			currency = gopay.JPY
-		} else {
-			currency = gopay.Currency(*contract.Currency)
 		}
 
 		//TODO: Can't be more than 1000 Characters (Stripe limitation)
@@ -306,97 +377,169 @@ func contractsGroup(router *gin.Engine) {
 			return
 		}
 
-		//Start a payment session
-		payment, err := gopay.New(gopay.PaymentParams{
+		payment, err := depositContract(ctx, user, identity, contract, gopay.PaymentParams{
 			Tag:         contract.Name,
 			Description: *contract.Description,
 			Ref:         contract.ID.String(),
-			Type:        gopay.PaymentType(*contract.PaymentType),
-			Currency:    currency,
 			TotalAmount: contract.TotalAmount,
-		})
-
+		}, form)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		var sourceAccount, destinationAccount string
+		contract.PaymentID = &payment.ID
+		if err = contract.Update(ctx, nil); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-		if *contract.PaymentType == models.PaymentModeTypeFiat {
-			//Set Source account
-			card, err := models.GetCard(*form.CardID, provider.ID)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Couldn't find corresponding Stripe customer"})
-				return
-			}
-			if card.Customer != nil {
-				sourceAccount = *card.Customer
-			}
+		c.JSON(http.StatusOK, contract)
+	})
 
-			//Set Destination account
-			oauthConnect, err := models.GetOauthConnectByIdentityId(contract.ClientID, models.OauthConnectedProvidersStripeJp)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Couldn't find corresponding Stripe account"})
-				return
-			}
-			destinationAccount = oauthConnect.MatrixUniqueID
+	g.POST("/:id/release", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
 
-			payment.SetToFiatMode(string(oauthConnect.Provider))
-		} else {
-			if user.WalletAddress != nil {
-				sourceAccount = *user.WalletAddress
-			}
-			payment.SetToCryptoMode(*contract.CryptoCurrency, float64(contract.CurrencyRate))
+		id := c.Param("id")
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key header is required"})
+			return
 		}
 
-		//Add Payment Identities
-		if _, err := payment.AddIdentity(gopay.IdentityParams{
-			ID:       identity.ID,
-			RoleName: "assigner",
-			Account:  sourceAccount,
-			Amount:   0,
-		}); err != nil {
+		contract, err := models.GetContract(uuid.MustParse(id))
+		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		//Only fiat payment needs second payment identity
-		if *contract.PaymentType == models.PaymentModeTypeFiat {
-			if _, err := payment.AddIdentity(gopay.IdentityParams{
-				ID:       identity.ID,
-				RoleName: "assignee",
-				Account:  destinationAccount,
-				Amount:   float64(contract.TotalAmount),
-			}); err != nil {
+		if contract.ProviderID != identity.ID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Just provider can release the contract payment"})
+			return
+		}
+		frozen, err := contract.HasOpenDispute()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if frozen {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Contract payout is frozen by an open dispute"})
+			return
+		}
+		if contract.Status != models.ContractStatusCompleted {
+			if err := models.CanTransitContract(contract.Status, models.ContractStatusCompleted, models.ContractPartyProvider); err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
 		}
 
-		//Enroll the payment
-		if *contract.PaymentType == models.PaymentModeTypeFiat {
-			err = payment.Deposit()
-		} else {
-			TxID := ""
-			if form.TxID != nil {
-				TxID = *form.TxID
+		escrow, err := releaseContractEscrow(ctx, contract, key)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if contract.Status != models.ContractStatusCompleted {
+			reason := "payment released"
+			if err := contract.Transit(ctx, identity.ID, models.ContractStatusCompleted, &reason); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
 			}
-			err = payment.ConfirmDeposit(TxID, form.Meta)
+			//TODO: use Nats
+			go addContractImpactPoints(contract)
 		}
 
+		c.JSON(http.StatusAccepted, gin.H{
+			"contract": contract,
+			"escrow":   escrow,
+		})
+	})
+
+	g.POST("/:id/refund", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		id := c.Param("id")
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key header is required"})
+			return
+		}
+
+		form := new(ContractTransitionForm)
+		if err := c.ShouldBindJSON(form); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		contract, err := models.GetContract(uuid.MustParse(id))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		contract.PaymentID = &payment.ID
-		if err = contract.Update(ctx, nil); err != nil {
+		party, err := contract.PartyOf(identity.ID)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		frozen, err := contract.HasOpenDispute()
+		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if frozen {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Contract payout is frozen by an open dispute"})
+			return
+		}
 
-		c.JSON(http.StatusOK, contract)
+		if contract.Status == models.ContractStatusCompleted {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Completed contracts can't be refunded"})
+			return
+		}
+
+		// Provider can take the deposit back only until the client signs, after that the client has to agree on the refund
+		signed := contract.Status == models.ContractStatusSinged || contract.Status == models.ContractStatusApplied
+		if party == models.ContractPartyProvider && signed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Signed contracts can be refunded by client only"})
+			return
+		}
+
+		status := models.ContractStatusClientCanceled
+		if party == models.ContractPartyProvider {
+			status = models.ContractStatusProviderCanceled
+		}
+
+		var escrow *models.Escrow
+		if contract.PaymentID != nil {
+			if escrow, err = refundContractEscrow(ctx, contract, key); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		milestones, err := refundMilestoneEscrows(ctx, contract, key)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if escrow == nil && len(milestones) < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "contract has not been deposited"})
+			return
+		}
+
+		if !contract.IsTerminal() {
+			if err := contract.Transit(ctx, identity.ID, status, form.Reason); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"contract":   contract,
+			"escrow":     escrow,
+			"milestones": milestones,
+		})
 	})
 
 	g.PATCH("/:id/requirements", func(c *gin.Context) {
@@ -464,3 +607,524 @@ func contractsGroup(router *gin.Engine) {
 	})
 
 }
+
+// createContract creates the contract priced with the fee policies in effect at creation
+func createContract(ctx context.Context, contract *models.Contract) error {
+	if err := contract.Create(ctx); err != nil {
+		return err
+	}
+	return priceContract(ctx, contract)
+}
+
+// priceContract snapshots the fee policy the created contract is priced with and calculates its amounts
+func priceContract(ctx context.Context, contract *models.Contract) error {
+	orgReferrer, _ := models.GetReferring(contract.ProviderID)
+	userReferrer, _ := models.GetReferring(contract.ClientID)
+	options := lib.AmountsOptionsFromContract(*contract, orgReferrer, userReferrer)
+
+	// Later changes of the policies must not reprice the contract
+	if options.Policy != nil {
+		if err := contract.SnapshotFeePolicy(ctx, options.Policy.ID); err != nil {
+			return err
+		}
+	}
+	contract.Amounts = lib.CalculateAmounts(options)
+	return nil
+}
+
+// depositContract starts a gopay payment for the contract (or a portion of it) and enrolls the deposit,
+// crypto deposits are recorded pending until the worker confirms the transaction on chain.
+func depositContract(ctx context.Context, user *models.User, identity *models.Identity, contract *models.Contract, params gopay.PaymentParams, form *ContractDepositForm) (*gopay.Payment, error) {
+	// Fetching Client
+	provider, err := models.GetIdentity(contract.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("provider fetch error : %v", err)
+	}
+
+	// Determine Currency
+	var currency gopay.Currency
+	if contract.Currency == nil && *contract.PaymentType == models.PaymentModeTypeFiat {
+		return nil, fmt.Errorf("Currency is nil in Fiat payment")
+	} else if contract.Currency == nil {
+		//Default payment is set not to prevent the runtime from crashing while its empty
+		currency = gopay.JPY
+	} else {
+		currency = gopay.Currency(*contract.Currency)
+	}
+
+	// Payments are held by gopay in the currencies it supports only
+	if currency != gopay.USD && currency != gopay.JPY {
+		return nil, fmt.Errorf("deposits in %s are not supported", currency)
+	}
+
+	//Start a payment session
+	params.Type = gopay.PaymentType(*contract.PaymentType)
+	params.Currency = currency
+	payment, err := gopay.New(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sourceAccount, destinationAccount string
+		lockedRate                        float64
+	)
+
+	if *contract.PaymentType == models.PaymentModeTypeFiat {
+		//Set Source account, the default card is charged when the provider chooses no card
+		var card *models.Card
+		switch {
+		case form.CardID != nil:
+			card, err = models.GetCard(*form.CardID, provider.ID)
+		case identity.ID == contract.ProviderID:
+			card, err = models.GetDefaultCard(provider.ID)
+		default:
+			err = fmt.Errorf("card is required")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't find corresponding Stripe customer")
+		}
+		if card.Customer != nil {
+			sourceAccount = *card.Customer
+		}
+
+		//Set Destination account
+		oauthConnect, err := models.GetOauthConnectByIdentityId(contract.ClientID, models.OauthConnectedProvidersStripeJp)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't find corresponding Stripe account")
+		}
+		destinationAccount = oauthConnect.MatrixUniqueID
+
+		payment.SetToFiatMode(string(oauthConnect.Provider))
+	} else {
+		if contract.CryptoCurrency == nil {
+			return nil, fmt.Errorf("Crypto currency is nil in Crypto payment")
+		}
+		if form.TxID == nil || *form.TxID == "" {
+			return nil, fmt.Errorf("txid is required for crypto deposits")
+		}
+		// Escrow is paid out to the client wallet, it must be proven owned by the client
+		if contract.ClientWallet == nil {
+			return nil, fmt.Errorf("client has no verified wallet on the contract network")
+		}
+		if user.WalletAddress != nil {
+			sourceAccount = *user.WalletAddress
+		}
+		// The rate the contract is offered with is indicative, the server rate of the token is locked in
+		// on the first deposit and later deposits (of milestones) are paid with the same rate
+		if contract.LockedCurrencyRate == nil {
+			_, token, err := lib.FindChainToken(config.Config.Payment.Chains, *contract.CryptoCurrency)
+			if err != nil {
+				return nil, err
+			}
+			rate, err := lib.GetRate(ctx, token.Symbol, string(currency))
+			if err != nil {
+				return nil, fmt.Errorf("couldn't lock %s rate: %v", token.Symbol, err)
+			}
+			if err := contract.LockCurrencyRate(ctx, rate.Rate); err != nil {
+				return nil, err
+			}
+		}
+		lockedRate = *contract.LockedCurrencyRate
+		payment.SetToCryptoMode(*contract.CryptoCurrency, lockedRate)
+	}
+
+	//Add Payment Identities
+	if _, err := payment.AddIdentity(gopay.IdentityParams{
+		ID:       identity.ID,
+		RoleName: "assigner",
+		Account:  sourceAccount,
+		Amount:   0,
+	}); err != nil {
+		return nil, err
+	}
+
+	//Only fiat payment needs second payment identity
+	if *contract.PaymentType == models.PaymentModeTypeFiat {
+		if _, err := payment.AddIdentity(gopay.IdentityParams{
+			ID:       identity.ID,
+			RoleName: "assignee",
+			Account:  destinationAccount,
+			Amount:   params.TotalAmount,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	//Enroll the payment
+	if *contract.PaymentType == models.PaymentModeTypeFiat {
+		err = payment.Deposit()
+	} else {
+		err = enrollCryptoDeposit(ctx, identity, contract, payment, lockedRate, *form.TxID, form.Meta)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	// Crypto deposits and fiat ones requiring an action are invoiced once confirmed, the payment is not
+	// linked to the contract yet so the contract is invoiced as is
+	if payment.Status == gopay.DEPOSITED {
+		invoiceDeposit(ctx, contract, payment)
+	}
+	return payment, nil
+}
+
+// invoiceDeposit issues the invoice of the deposited payment of a contract or its milestone
+func invoiceDeposit(ctx context.Context, contract *models.Contract, payment *gopay.Payment) {
+	if _, err := lib.IssueDepositInvoice(ctx, *contract, payment); err != nil {
+		fmt.Println(fmt.Errorf("failed to invoice deposit of contract: %s; error: %v", contract.ID, err))
+	}
+}
+
+// enrollCryptoDeposit records the deposit transaction with the transfer expected on chain and queues its verification
+func enrollCryptoDeposit(ctx context.Context, identity *models.Identity, contract *models.Contract, payment *gopay.Payment, rate float64, txID string, meta interface{}) error {
+	chain, token, err := lib.FindChainToken(config.Config.Payment.Chains, *contract.CryptoCurrency)
+	if err != nil {
+		return err
+	}
+
+	orgReferrer, _ := models.GetReferring(contract.ProviderID)
+	userReferrer, _ := models.GetReferring(contract.ClientID)
+	options := lib.AmountsOptionsFromContract(*contract, orgReferrer, userReferrer)
+	options.Amount = payment.TotalAmount
+	amounts := lib.CalculateAmounts(options)
+
+	deposit := &models.CryptoDeposit{
+		ContractID:            contract.ID,
+		PaymentID:             payment.ID,
+		IdentityID:            identity.ID,
+		TxID:                  txID,
+		TokenAddress:          *contract.CryptoCurrency,
+		Recipient:             chain.ContractAddress,
+		ExpectedAmount:        lib.TokenAmount(amounts.Major(amounts.Total), rate, token.Decimals),
+		RequiredConfirmations: lib.DepositConfirmations(),
+	}
+	if err := deposit.Create(ctx, meta); err != nil {
+		return err
+	}
+
+	payment.Status = gopay.PENDING_DEPOSIT
+	if err := payment.Update(); err != nil {
+		return err
+	}
+
+	// Deposits not picked up from the queue are checked by the crypto-deposits job
+	gomq.Mq.SendJson("crypto_deposits", map[string]string{
+		"id": deposit.ID.String(),
+	})
+	return nil
+}
+
+// releaseContractEscrow pays the contract escrow out to the client with payout and fees of the contract
+func releaseContractEscrow(ctx context.Context, contract *models.Contract, key string) (*models.Escrow, error) {
+	if err := checkChargeback(contract); err != nil {
+		return nil, err
+	}
+	escrow, err := contract.HoldEscrow(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgReferrer, _ := models.GetReferring(contract.ProviderID)
+	userReferrer, _ := models.GetReferring(contract.ClientID)
+	options := lib.AmountsOptionsFromContract(*contract, orgReferrer, userReferrer)
+
+	// Hourly contracts pay out the approved works only
+	hourly, err := contract.IsHourly()
+	if err != nil {
+		return nil, err
+	}
+	if hourly {
+		if contract.ApprovedAmount <= 0 {
+			return nil, fmt.Errorf("hourly contract has no approved works to release")
+		}
+		options.Amount = contract.ApprovedAmount
+	}
+
+	amounts := lib.CalculateAmounts(options)
+	if err := amounts.Check(); err != nil {
+		return nil, err
+	}
+	payout, appFee := amounts.Major(amounts.Payout), amounts.Major(amounts.AppFee)
+
+	// The escrow is settled with the release, what's left of it over the approved works goes back to the provider
+	payouts := []escrowPayout{{
+		identityID: contract.ClientID,
+		tag:        "release",
+		amount:     payout,
+		fee:        appFee,
+	}}
+	if hourly && escrow.Amount != nil && *escrow.Amount > contract.ApprovedAmount {
+		payouts = append(payouts, escrowPayout{
+			identityID: contract.ProviderID,
+			tag:        "refund",
+			amount:     *escrow.Amount - contract.ApprovedAmount,
+		})
+	}
+
+	err = escrow.Release(ctx, key, payout, appFee, func() (string, error) {
+		return settleContractPayment(contract, *contract.PaymentID, key, gopay.PAID_OUT, payouts...)
+	})
+	if err != nil {
+		return escrow, err
+	}
+
+	creditReferralRewards(ctx, contract, *contract.PaymentID, amounts, orgReferrer, userReferrer)
+	if _, err := lib.IssueInvoice(ctx, *contract, *contract.PaymentID, models.InvoiceTypeRelease, amounts); err != nil {
+		fmt.Println(fmt.Errorf("failed to invoice release of contract: %s; error: %v", contract.ID, err))
+	}
+	return escrow, nil
+}
+
+// checkChargeback refuses paying out contracts with a payment being charged back on Stripe,
+// the flag is cleared once the dispute is won
+func checkChargeback(contract *models.Contract) error {
+	if contract.ChargebackID != nil {
+		return fmt.Errorf("contract payment is being charged back and can't be released")
+	}
+	return nil
+}
+
+// creditReferralRewards credits the referral shares of the amounts released from the payment to the referrers,
+// rewards are unique per payment so releasing it again doesn't credit twice.
+func creditReferralRewards(ctx context.Context, contract *models.Contract, paymentID uuid.UUID, amounts *models.AmountsBreakdown, orgReferrer, userReferrer *models.Referring) {
+	// Crypto rewards are in the token of the contract and paid out on its network, fiat ones have no network
+	var (
+		currency string
+		network  *models.WalletNetwork
+	)
+	if contract.Currency != nil {
+		currency = string(*contract.Currency)
+	}
+	if *contract.PaymentType == models.PaymentModeTypeCrypto && contract.CryptoCurrency != nil {
+		currency, network = *contract.CryptoCurrency, contract.CryptoNetwork
+	}
+
+	for _, item := range amounts.LineItems {
+		var (
+			referrer   *models.Referring
+			referredID uuid.UUID
+		)
+		switch item.Type {
+		case models.AmountLineItemOrgReferralShare:
+			referrer, referredID = orgReferrer, contract.ProviderID
+		case models.AmountLineItemUserReferralShare:
+			referrer, referredID = userReferrer, contract.ClientID
+		default:
+			continue
+		}
+		if referrer == nil || item.Amount < 1 {
+			continue
+		}
+
+		reward := &models.ReferralReward{
+			ReferrerID:         referrer.ReferredById,
+			ReferredIdentityID: referredID,
+			ContractID:         contract.ID,
+			PaymentID:          &paymentID,
+			Amount:             amounts.Major(item.Amount),
+			Currency:           currency,
+			CryptoNetwork:      network,
+		}
+		if _, err := reward.Create(ctx); err != nil {
+			fmt.Println(fmt.Errorf("failed to credit referral reward for contract: %s; error: %v", contract.ID, err))
+		}
+	}
+}
+
+// refundContractEscrow pays what's left of the contract escrow back to the provider
+func refundContractEscrow(ctx context.Context, contract *models.Contract, key string) (*models.Escrow, error) {
+	escrow, err := contract.HoldEscrow(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = escrow.Refund(ctx, key, func() (string, error) {
+		remaining, err := escrowRemaining(escrow, *contract.PaymentID)
+		if err != nil {
+			return "", err
+		}
+		return settleContractPayment(contract, *contract.PaymentID, key, gopay.REFUNDED, escrowPayout{
+			identityID: contract.ProviderID,
+			tag:        "refund",
+			amount:     remaining,
+		})
+	})
+	return escrow, err
+}
+
+// refundMilestoneEscrows pays the escrows of the funded milestones not released yet back to the provider,
+// each refund is keyed by the milestone so retrying the contract refund pays every milestone back once.
+func refundMilestoneEscrows(ctx context.Context, contract *models.Contract, key string) ([]models.Escrow, error) {
+	milestones, err := models.GetContractMilestones(contract.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	escrows := []models.Escrow{}
+	for i := range milestones {
+		milestone := &milestones[i]
+		if milestone.Status == models.ContractMilestoneStatusPending || milestone.Status == models.ContractMilestoneStatusApproved {
+			continue
+		}
+		escrow, err := milestone.HoldEscrow(ctx, *contract)
+		if err != nil {
+			return nil, err
+		}
+
+		milestoneKey := fmt.Sprintf("%s-%s", key, milestone.ID)
+		err = escrow.Refund(ctx, milestoneKey, func() (string, error) {
+			remaining, err := escrowRemaining(escrow, *milestone.PaymentID)
+			if err != nil {
+				return "", err
+			}
+			return settleContractPayment(contract, *milestone.PaymentID, milestoneKey, gopay.REFUNDED, escrowPayout{
+				identityID: contract.ProviderID,
+				tag:        "refund",
+				amount:     remaining,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		if milestone.Status == models.ContractMilestoneStatusFunded {
+			if err := milestone.Refund(ctx); err != nil {
+				return nil, err
+			}
+		}
+		escrows = append(escrows, *escrow)
+	}
+	return escrows, nil
+}
+
+// escrowRemaining is the escrowed amount not paid out of the payment yet, refunds are left out
+// so a retried refund pays back the same amount.
+func escrowRemaining(escrow *models.Escrow, paymentID uuid.UUID) (float64, error) {
+	payment, err := gopay.Fetch(paymentID)
+	if err != nil {
+		return 0, err
+	}
+	remaining := payment.TotalAmount
+	if escrow.Amount != nil {
+		remaining = *escrow.Amount
+	}
+	for _, t := range payment.Transactions {
+		if t.Type == gopay.PAYOUT && t.Tag != "refund" && t.CanceledAt == nil {
+			remaining -= t.Amount + t.Fee
+		}
+	}
+	return math.Max(remaining, 0), nil
+}
+
+// escrowPayout is paid out of the escrowed payment to one of the contract parties
+type escrowPayout struct {
+	identityID uuid.UUID
+	tag        string
+	amount     float64
+	fee        float64
+}
+
+// settleContractPayment records the payouts of the deposited contract (or milestone) payment and moves the gopay
+// payment to its final status, it returns the first payout transaction id. Each payout is recorded once per
+// payment so a settlement retried after a failure completes the recorded one instead of paying out again.
+func settleContractPayment(contract *models.Contract, paymentID uuid.UUID, key string, status gopay.PaymentStatus, payouts ...escrowPayout) (string, error) {
+	payment, err := gopay.Fetch(paymentID)
+	if err != nil {
+		return "", err
+	}
+
+	refs := []string{}
+	for _, p := range payouts {
+		if ref := payoutTransaction(payment, p.tag); ref != "" {
+			refs = append(refs, ref)
+			continue
+		}
+		if p.amount <= 0 && p.fee <= 0 {
+			continue
+		}
+		if payment.Status != gopay.DEPOSITED && payment.Status != gopay.ON_HOLD {
+			return "", fmt.Errorf("payment is %s and can't be settled", payment.Status)
+		}
+
+		meta, _ := json.Marshal(gin.H{"contract_id": contract.ID, "key": key})
+		transaction := gopay.Transaction{
+			PaymentID:  payment.ID,
+			IdentityID: p.identityID,
+			Tag:        p.tag,
+			Amount:     p.amount,
+			Fee:        p.fee,
+			Type:       gopay.PAYOUT,
+			Meta:       meta,
+		}
+		if err := transaction.Create(); err != nil {
+			return "", err
+		}
+		refs = append(refs, transaction.ID.String())
+	}
+	if len(refs) < 1 {
+		return "", fmt.Errorf("payment has nothing to settle")
+	}
+
+	if payment.Status != status {
+		payment.Status = status
+		if err := payment.Update(); err != nil {
+			return "", err
+		}
+	}
+	return refs[0], nil
+}
+
+// payoutTransaction returns the id of the payout transaction of the payment recorded with the tag
+func payoutTransaction(payment *gopay.Payment, tag string) string {
+	for _, t := range payment.Transactions {
+		if t.Type == gopay.PAYOUT && t.Tag == tag && t.CanceledAt == nil {
+			return t.ID.String()
+		}
+	}
+	return ""
+}
+
+func addContractImpactPoints(contract *models.Contract) {
+	impactPoints, params, err := models.CalculateImpactPoints(contract)
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to calculate impact point for contract: %s; error: %v", contract.ID, err))
+		return
+	}
+	addImpactPoints(contract, impactPoints, params, contract.ID.String())
+}
+
+// addWorkImpactPoints adds the impact points of an approved work on hourly contracts
+func addWorkImpactPoints(contract *models.Contract, work *models.SubmittedWork) {
+	impactPoints, params, err := models.CalculateWorkImpactPoints(contract, work)
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to calculate impact point for work: %s; error: %v", work.ID, err))
+		return
+	}
+	addImpactPoints(contract, impactPoints, params, work.ID.String())
+}
+
+func addImpactPoints(contract *models.Contract, impactPoints float64, params *models.CalculateImpactPointsParams, uniqueTag string) {
+	impactPointType := "WORKSUBMIT"
+	if contract.Type == models.ContractTypeVolunteer {
+		impactPointType = "VOLUNTEER"
+	}
+
+	ip := goaccount.ImpactPoint{
+		UserID:              contract.ClientID,
+		TotalPoints:         int(math.Floor(impactPoints)),
+		SocialCause:         params.Project.CausesTags[0],
+		SocialCauseCategory: string(utils.GetSDG(params.Project.CausesTags[0])),
+		Type:                impactPointType,
+		Meta: map[string]any{
+			"contract": contract,
+			"project":  params.Project,
+			"category": params.Category,
+		},
+		UniqueTag: uniqueTag,
+		Value:     params.TotalHours,
+	}
+	err := ip.AddImpactPoint()
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to add impact point for %s; error %v", uniqueTag, err))
+	}
+}
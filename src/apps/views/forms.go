@@ -2,6 +2,7 @@ package views
 
 import (
 	"socious/src/apps/models"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/socious-io/goaccount"
@@ -46,30 +47,97 @@ type ProjectForm struct {
 	CommitmentHoursHigher *string                         `json:"commitment_hours_higher"`
 	PaymentMode           *models.PaymentModeType         `json:"payment_mode"`
 	GeonameId             *int                            `json:"geoname_id"`
-	JobCategoryId         uuid.UUID                       `json:"job_category_id" validate:"required"`
+	JobCategoryId         *uuid.UUID                      `json:"job_category_id" validate:"required"`
 	Kind                  models.ProjectKind              `json:"kind"`
 	WorkSamples           []uuid.UUID                     `json:"work_samples" validate:"required"`
 }
 
+type ProjectMarkForm struct {
+	Type models.ProjectMarkType `json:"type"`
+}
+
+type ApplicantAnswerForm struct {
+	QuestionID     uuid.UUID `json:"question_id" validate:"required"`
+	Answer         *string   `json:"answer"`
+	SelectedOption *int      `json:"selected_option"`
+}
+
+type ApplicantForm struct {
+	CoverLetter      string                `json:"cover_letter" validate:"required"`
+	PaymentRate      *int                  `json:"payment_rate"`
+	PaymentType      *models.PaymentType   `json:"payment_type"`
+	CvLink           *string               `json:"cv_link"`
+	CvName           *string               `json:"cv_name"`
+	ShareContactInfo *bool                 `json:"share_contact_info"`
+	Attachments      []uuid.UUID           `json:"attachments"`
+	Answers          []ApplicantAnswerForm `json:"answers"`
+}
+
+type ApplicantStatusForm struct {
+	Feedback *string `json:"feedback"`
+}
+
+type QuestionForm struct {
+	Question string   `json:"question" validate:"required"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options"`
+}
+
 type ContractForm struct {
-	Name                  string                          `json:"name" validate:"required,min=3"`
-	Description           string                          `json:"description"`
-	Type                  models.ContractType             `json:"type" validate:"required"`
-	TotalAmount           float64                         `json:"total_amount"`
-	Currency              models.Currency                 `json:"currency"`
-	CryptoCurrency        string                          `json:"crypto_currency"`
-	CurrencyRate          float32                         `json:"currency_rate"`
-	Commitment            int                             `json:"commitment"`
-	CommitmentPeriod      models.ContractCommitmentPeriod `json:"commitment_period"`
-	CommitmentPeriodCount int                             `json:"commitment_period_count"`
-	PaymentType           *models.PaymentModeType         `json:"payment_type"`
-	ApplicantID           *uuid.UUID                      `json:"applicant_id"`
-	ProjectID             *uuid.UUID                      `json:"project_id"`
-	ClientID              uuid.UUID                       `json:"client_id" validate:"required"`
+	Name                   string                          `json:"name" validate:"required,min=3"`
+	Description            string                          `json:"description"`
+	Type                   models.ContractType             `json:"type" validate:"required"`
+	TotalAmount            float64                         `json:"total_amount"`
+	Currency               models.Currency                 `json:"currency"`
+	CryptoCurrency         string                          `json:"crypto_currency"`
+	CryptoNetwork          *models.WalletNetwork           `json:"crypto_network"`
+	CurrencyRate           float32                         `json:"currency_rate"`
+	Commitment             int                             `json:"commitment"`
+	CommitmentPeriod       models.ContractCommitmentPeriod `json:"commitment_period"`
+	CommitmentPeriodCount  int                             `json:"commitment_period_count"`
+	PaymentType            *models.PaymentModeType         `json:"payment_type"`
+	ApplicantID            *uuid.UUID                      `json:"applicant_id"`
+	ProjectID              *uuid.UUID                      `json:"project_id"`
+	ClientID               uuid.UUID                       `json:"client_id" validate:"required"`
+	RequirementDescription *string                         `json:"requirement_description,omitempty"`
+}
+
+// ContractUpdateForm patches the contract, fields left out of the request are kept as they are
+type ContractUpdateForm struct {
+	Name                   *string                          `json:"name,omitempty" validate:"omitempty,min=3"`
+	Description            *string                          `json:"description,omitempty"`
+	Type                   *models.ContractType             `json:"type,omitempty"`
+	TotalAmount            *float64                         `json:"total_amount,omitempty"`
+	Currency               *models.Currency                 `json:"currency,omitempty"`
+	CryptoCurrency         *string                          `json:"crypto_currency,omitempty"`
+	CryptoNetwork          *models.WalletNetwork            `json:"crypto_network,omitempty"`
+	CurrencyRate           *float32                         `json:"currency_rate,omitempty"`
+	Commitment             *int                             `json:"commitment,omitempty"`
+	CommitmentPeriod       *models.ContractCommitmentPeriod `json:"commitment_period,omitempty"`
+	CommitmentPeriodCount  *int                             `json:"commitment_period_count,omitempty"`
+	PaymentType            *models.PaymentModeType          `json:"payment_type,omitempty"`
+	ClientID               *uuid.UUID                       `json:"client_id,omitempty"`
+	RequirementDescription *string                          `json:"requirement_description,omitempty"`
+}
+
+type ContractTemplateForm struct {
+	Title                  string                          `json:"title" validate:"required,min=3"`
+	Name                   *string                         `json:"name"`
+	Description            *string                         `json:"description"`
+	Type                   models.ContractType             `json:"type" validate:"required"`
+	TotalAmount            *float64                        `json:"total_amount"`
+	Currency               *models.Currency                `json:"currency"`
+	CryptoCurrency         *string                         `json:"crypto_currency"`
+	CryptoNetwork          *models.WalletNetwork           `json:"crypto_network"`
+	Commitment             *int                            `json:"commitment"`
+	CommitmentPeriod       models.ContractCommitmentPeriod `json:"commitment_period" validate:"required"`
+	CommitmentPeriodCount  *int                            `json:"commitment_period_count"`
+	PaymentType            *models.PaymentModeType         `json:"payment_type"`
+	RequirementDescription *string                         `json:"requirement_description"`
 }
 
 type ContractDepositForm struct {
-	CardID *uuid.UUID  `json:"card_id" validate:"required"`
+	CardID *uuid.UUID  `json:"card_id"`
 	TxID   *string     `json:"txid" validate:"required"`
 	Meta   interface{} `json:"meta" validate:"required"`
 }
@@ -84,6 +152,56 @@ type ContractFeedbackForm struct {
 	Satisfied bool   `json:"satisfied" validate:"required"`
 }
 
+type ContractMilestoneForm struct {
+	Title       string     `json:"title" validate:"required,min=3"`
+	Description *string    `json:"description"`
+	Amount      float64    `json:"amount" validate:"required"`
+	DueDate     *time.Time `json:"due_date"`
+}
+
+type ContractWorkForm struct {
+	Description *string   `json:"description"`
+	StartAt     time.Time `json:"start_at" validate:"required"`
+	EndAt       time.Time `json:"end_at" validate:"required"`
+}
+
+type ContractAmendmentForm struct {
+	TotalAmount           *float64                         `json:"total_amount"`
+	Currency              *models.Currency                 `json:"currency"`
+	CryptoCurrency        *string                          `json:"crypto_currency"`
+	CryptoNetwork         *models.WalletNetwork            `json:"crypto_network"`
+	CurrencyRate          *float32                         `json:"currency_rate"`
+	Commitment            *int                             `json:"commitment"`
+	CommitmentPeriod      *models.ContractCommitmentPeriod `json:"commitment_period"`
+	CommitmentPeriodCount *int                             `json:"commitment_period_count"`
+	PaymentType           *models.PaymentModeType          `json:"payment_type"`
+	Reason                *string                          `json:"reason"`
+}
+
+type ContractTransitionForm struct {
+	Reason *string `json:"reason"`
+}
+
+type DisputeForm struct {
+	Title     string                 `json:"title" validate:"required,min=3"`
+	Category  models.DisputeCategory `json:"category" validate:"required"`
+	Message   string                 `json:"message" validate:"required"`
+	Evidences []uuid.UUID            `json:"evidences"`
+}
+
+type DisputeResponseForm struct {
+	Message   string      `json:"message" validate:"required"`
+	Evidences []uuid.UUID `json:"evidences"`
+}
+
+type DisputeWithdrawForm struct {
+	Message *string `json:"message"`
+}
+
+type DisputeVoteForm struct {
+	Side models.DisputeSide `json:"side" validate:"required"`
+}
+
 type UserUpdateForm struct {
 	Username  *string    `json:"username" validate:"required,min=3,max=32"`
 	Bio       *string    `json:"bio"`
@@ -128,7 +246,22 @@ type OrganizationUpdateForm struct {
 	Did               *string    `db:"did" json:"did"`
 }
 type WalletForm struct {
-	Address string               `json:"address"`
-	Network models.WalletNetwork `json:"network"`
+	Address     string               `json:"address"`
+	Network     models.WalletNetwork `json:"network"`
+	Testnet     bool                 `json:"testnet"`
+	ChallengeID uuid.UUID            `json:"challenge_id" validate:"required"`
+	Signature   string               `json:"signature" validate:"required"`
+	Key         *string              `json:"key"`
+}
+
+type CardForm struct {
+	SetupIntentID   *string `json:"setup_intent_id"`
+	PaymentMethodID *string `json:"payment_method_id"`
+	IsJp            bool    `json:"is_jp"`
+}
+
+type WalletChallengeForm struct {
+	Address string               `json:"address" validate:"required"`
+	Network models.WalletNetwork `json:"network" validate:"required"`
 	Testnet bool                 `json:"testnet"`
 }
@@ -0,0 +1,71 @@
+package views
+
+import (
+	"fmt"
+	"net/http"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"socious/src/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+func invoicesGroup(router *gin.Engine) {
+	g := router.Group("invoices")
+	g.Use(LoginRequired())
+
+	g.GET("", paginate(), func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+		page, _ := c.Get("paginate")
+
+		invoices, total, err := models.GetInvoices(identity.ID, page.(database.Paginate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": invoices,
+			"total":   total,
+		})
+	})
+
+	g.GET("/:id", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		invoice, err := models.GetInvoice(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if !invoice.IsParty(identity.ID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+		c.JSON(http.StatusOK, invoice)
+	})
+
+	g.GET("/:id/pdf", func(c *gin.Context) {
+		identity := c.MustGet("identity").(*models.Identity)
+
+		invoice, err := models.GetInvoice(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if !invoice.IsParty(identity.ID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allow"})
+			return
+		}
+
+		pdf, err := lib.InvoicePdf(config.Config.Invoices.Template, *invoice)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=invoice-%s.pdf", invoice.Number))
+		c.Data(http.StatusOK, "application/pdf", pdf)
+	})
+}
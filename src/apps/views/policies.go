@@ -0,0 +1,27 @@
+package views
+
+import (
+	"fmt"
+	"socious/src/apps/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Owned is implemented by the models owned by an identity
+type Owned interface {
+	OwnerID() uuid.UUID
+}
+
+// authorize returns the identity of the request when it owns the model, models owned by
+// an organization are managed by its members acting as the organization.
+func authorize(c *gin.Context, m Owned) (*models.Identity, error) {
+	identity, err := memberIdentity(c)
+	if err != nil {
+		return nil, err
+	}
+	if m.OwnerID() != identity.ID {
+		return nil, fmt.Errorf("not allow")
+	}
+	return identity, nil
+}
@@ -0,0 +1,285 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"socious/src/apps/models"
+	"socious/src/apps/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	database "github.com/socious-io/pkg_database"
+)
+
+func projectApplicantsGroup(router *gin.Engine) {
+	g := router.Group("projects/:id/applicants")
+	g.Use(LoginRequired())
+
+	g.POST("", func(c *gin.Context) {
+		user := c.MustGet("user").(*models.User)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		project, err := models.GetProject(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if project.Kind != models.ProjectKindJob || project.Status == nil || *project.Status != models.ProjectStatusActive {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "project is not open to applications"})
+			return
+		}
+		if project.IdentityID == user.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "can not apply to own project"})
+			return
+		}
+
+		form := new(ApplicantForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		answers := []models.Answer{}
+		for _, a := range form.Answers {
+			answers = append(answers, models.Answer{
+				QuestionID:     a.QuestionID,
+				Answer:         a.Answer,
+				SelectedOption: a.SelectedOption,
+			})
+		}
+		questions, err := models.GetQuestions(project.ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := models.ValidateAnswers(questions, answers); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		applicant := &models.Applicant{
+			ProjectID:        project.ID,
+			UserID:           user.ID,
+			CoverLetter:      &form.CoverLetter,
+			PaymentRate:      form.PaymentRate,
+			PaymentType:      form.PaymentType,
+			CvLink:           form.CvLink,
+			CvName:           form.CvName,
+			ShareContactInfo: form.ShareContactInfo,
+		}
+		if applicant.PaymentType == nil {
+			applicant.PaymentType = project.PaymentType
+		}
+		if err := applicant.Create(ctx, form.Attachments, answers); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, applicant)
+	})
+
+	g.GET("", paginate(), func(c *gin.Context) {
+		page, _ := c.Get("paginate")
+
+		project, err := ownedProject(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		applicants, total, err := models.GetApplicants(project.ID, page.(database.Paginate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": applicants,
+			"total":   total,
+		})
+	})
+
+	g.GET("/:applicant_id", func(c *gin.Context) {
+		user := c.MustGet("user").(*models.User)
+
+		applicant, err := models.GetApplicant(uuid.MustParse(c.Param("applicant_id")))
+		if err != nil || applicant.ProjectID.String() != c.Param("id") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "applicant not found"})
+			return
+		}
+		// Applicants see their own application
+		if applicant.UserID != user.ID {
+			if _, err := ownedProject(c); err != nil {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		c.JSON(http.StatusOK, applicant)
+	})
+
+	g.POST("/:applicant_id/shortlist", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		_, applicant, err := projectApplicant(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err := applicant.UpdateStatus(ctx, models.ApplicantStatusShortlisted, nil); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, applicant)
+	})
+
+	g.POST("/:applicant_id/reject", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		_, applicant, err := projectApplicant(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		form := new(ApplicantStatusForm)
+		if err := c.ShouldBindJSON(form); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := applicant.UpdateStatus(ctx, models.ApplicantStatusRejected, form.Feedback); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, applicant)
+	})
+
+	g.POST("/:applicant_id/hire", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		project, applicant, err := projectApplicant(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if applicant.Status != models.ApplicantStatusPending && applicant.Status != models.ApplicantStatusShortlisted {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s applicant can not be hired", applicant.Status)})
+			return
+		}
+
+		// The project and the applicant pre-fill the contract and the request body overrides them per field
+		form := applicantContractForm(*project, *applicant)
+		if err := c.ShouldBindJSON(form); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if form.PaymentType == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "payment_type is required"})
+			return
+		}
+
+		contract := new(models.Contract)
+		utils.Copy(form, contract)
+		contract.ProjectID = &project.ID
+		contract.ApplicantID = &applicant.ID
+		contract.ProviderID = project.IdentityID
+		contract.ClientID = applicant.UserID
+		// Volunteer projects may have no currency to pre-fill
+		if contract.Currency != nil && *contract.Currency == "" {
+			contract.Currency = nil
+		}
+		if err := applicant.Hire(ctx, contract); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := priceContract(ctx, contract); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"applicant": applicant,
+			"contract":  contract,
+		})
+	})
+}
+
+// ownedProject returns the project of the path when it's owned by the identity, organization projects
+// are managed by their members.
+func ownedProject(c *gin.Context) (*models.Project, error) {
+	project, err := models.GetProject(uuid.MustParse(c.Param("id")))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := authorize(c, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// projectApplicant returns the applicant of the path to the project owned by the identity
+func projectApplicant(c *gin.Context) (*models.Project, *models.Applicant, error) {
+	project, err := ownedProject(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	applicant, err := models.GetApplicant(uuid.MustParse(c.Param("applicant_id")))
+	if err != nil {
+		return nil, nil, err
+	}
+	if applicant.ProjectID != project.ID {
+		return nil, nil, fmt.Errorf("applicant not found")
+	}
+	return project, applicant, nil
+}
+
+// applicantContractForm pre-fills the contract of the hired applicant at the rate the applicant asked,
+// hourly projects are committed weekly for the hours of the project and the rate is paid per hour.
+// Projects with no payment mode are paid in fiat.
+func applicantContractForm(project models.Project, applicant models.Applicant) *ContractForm {
+	paymentMode := models.PaymentModeTypeFiat
+	if project.PaymentMode != nil {
+		paymentMode = *project.PaymentMode
+	}
+	form := &ContractForm{
+		Type:             models.ContractTypePaid,
+		CommitmentPeriod: models.ContractCommitmentMonthly,
+		Commitment:       1,
+		PaymentType:      &paymentMode,
+		ApplicantID:      &applicant.ID,
+		ProjectID:        &project.ID,
+		ClientID:         applicant.UserID,
+	}
+	if project.Title != nil {
+		form.Name = *project.Title
+	}
+	if project.Description != nil {
+		form.Description = *project.Description
+	}
+	if project.PaymentType != nil && *project.PaymentType == models.PaymentTypeVolunteer {
+		form.Type = models.ContractTypeVolunteer
+	}
+	if project.PaymentCurrency != nil {
+		form.Currency = models.Currency(*project.PaymentCurrency)
+	}
+	hourly := project.PaymentScheme != nil && *project.PaymentScheme == models.PaymentSchemeHourly
+	if hourly {
+		form.CommitmentPeriod = models.ContractCommitmentWeekly
+		if project.WeeklyHoursHigher != nil {
+			if hours, err := strconv.Atoi(*project.WeeklyHoursHigher); err == nil {
+				form.Commitment = hours
+			}
+		}
+	}
+	if applicant.PaymentRate != nil {
+		form.TotalAmount = float64(*applicant.PaymentRate)
+	} else if applicant.OfferRate != nil {
+		form.TotalAmount = *applicant.OfferRate
+	}
+	// Works of hourly contracts are paid at TotalAmount / Commitment per hour
+	if hourly {
+		form.TotalAmount *= float64(form.Commitment)
+	}
+	return form
+}
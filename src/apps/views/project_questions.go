@@ -0,0 +1,113 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"socious/src/apps/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func projectQuestionsGroup(router *gin.Engine) {
+	g := router.Group("projects/:id/questions")
+	g.Use(LoginRequired())
+
+	g.GET("", func(c *gin.Context) {
+		questions, err := models.GetQuestions(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": questions,
+			"total":   len(questions),
+		})
+	})
+
+	g.POST("", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		project, err := ownedProject(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		form := new(QuestionForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		q := &models.Question{
+			ProjectID: project.ID,
+			Question:  form.Question,
+			Required:  form.Required,
+			Options:   form.Options,
+		}
+		if err := q.Create(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, q)
+	})
+
+	g.PATCH("/:question_id", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		q, err := projectQuestion(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		form := new(QuestionForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		q.Question = form.Question
+		q.Required = form.Required
+		q.Options = form.Options
+		if err := q.Update(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, q)
+	})
+
+	g.DELETE("/:question_id", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		q, err := projectQuestion(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err := q.Delete(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "success",
+		})
+	})
+}
+
+// projectQuestion returns the question of the path to the project owned by the identity
+func projectQuestion(c *gin.Context) (*models.Question, error) {
+	project, err := ownedProject(c)
+	if err != nil {
+		return nil, err
+	}
+	q, err := models.GetQuestion(uuid.MustParse(c.Param("question_id")))
+	if err != nil {
+		return nil, err
+	}
+	if q.ProjectID != project.ID {
+		return nil, fmt.Errorf("question not found")
+	}
+	return q, nil
+}
@@ -2,9 +2,12 @@ package views
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"socious/src/apps/models"
 	"socious/src/apps/utils"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,9 +16,8 @@ import (
 
 func projectsGroup(router *gin.Engine) {
 	g := router.Group("projects")
-	g.Use(LoginRequired())
 
-	g.GET("", paginate(), func(c *gin.Context) {
+	g.GET("", LoginRequired(), paginate(), func(c *gin.Context) {
 		identity, _ := c.Get("identity")
 		page, _ := c.Get("paginate")
 
@@ -30,7 +32,57 @@ func projectsGroup(router *gin.Engine) {
 		})
 	})
 
-	g.GET("/:id", func(c *gin.Context) {
+	g.GET("/search", LoginOptional(), paginate(), func(c *gin.Context) {
+		page, _ := c.Get("paginate")
+
+		search, err := models.NewProjectSearch(c.Query("q"), page.(database.Paginate).Filters)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if identity, ok := c.Get("identity"); ok {
+			search.IdentityID = &identity.(*models.Identity).ID
+		}
+
+		projects, total, err := models.SearchProjects(*search, page.(database.Paginate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		facets, err := models.GetProjectFacets(*search)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": projects,
+			"total":   total,
+			"facets":  facets,
+		})
+	})
+
+	g.GET("/marked", LoginRequired(), paginate(), func(c *gin.Context) {
+		identity, _ := c.Get("identity")
+		page, _ := c.Get("paginate")
+
+		markedAs := strings.ToUpper(c.Query("type"))
+		if markedAs != "" && !models.ProjectMarkType(markedAs).Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid mark type %s", c.Query("type"))})
+			return
+		}
+
+		projects, total, err := models.GetMarkedProjects(identity.(*models.Identity).ID, markedAs, page.(database.Paginate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": projects,
+			"total":   total,
+		})
+	})
+
+	g.GET("/:id", LoginRequired(), func(c *gin.Context) {
 		id := c.Param("id")
 
 		p, err := models.GetProject(uuid.MustParse(id))
@@ -41,7 +93,7 @@ func projectsGroup(router *gin.Engine) {
 		c.JSON(http.StatusOK, p)
 	})
 
-	g.POST("", func(c *gin.Context) {
+	g.POST("", LoginRequired(), func(c *gin.Context) {
 		ctx, _ := c.Get("ctx")
 		identity, _ := c.Get("identity")
 
@@ -61,18 +113,26 @@ func projectsGroup(router *gin.Engine) {
 		c.JSON(http.StatusCreated, p)
 	})
 
-	g.PATCH("/:id", func(c *gin.Context) {
+	g.PATCH("/:id", LoginRequired(), func(c *gin.Context) {
 		ctx, _ := c.Get("ctx")
-		id := c.Param("id")
 
-		form := new(ProjectForm)
+		p, err := models.GetProject(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := authorize(c, p); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Fields missing in the request keep their current values
+		form := projectForm(*p)
 		if err := c.ShouldBindJSON(form); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		p := new(models.Project)
 		utils.Copy(form, p)
-		p.ID = uuid.MustParse(id)
 		if err := p.Update(ctx.(context.Context), form.WorkSamples); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -80,13 +140,16 @@ func projectsGroup(router *gin.Engine) {
 		c.JSON(http.StatusOK, p)
 	})
 
-	g.DELETE("/:id", func(c *gin.Context) {
+	g.DELETE("/:id", LoginRequired(), func(c *gin.Context) {
 		ctx, _ := c.Get("ctx")
-		id := c.Param("id")
 
-		p, err := models.GetProject(uuid.MustParse(id))
+		p, err := models.GetProject(uuid.MustParse(c.Param("id")))
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, err := authorize(c, p); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
 		if err := p.Delete(ctx.(context.Context)); err != nil {
@@ -97,4 +160,66 @@ func projectsGroup(router *gin.Engine) {
 			"message": "success",
 		})
 	})
+
+	g.POST("/:id/marks", LoginRequired(), func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+		identity, _ := c.Get("identity")
+
+		form := new(ProjectMarkForm)
+		if err := c.ShouldBindJSON(form); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if form.Type == "" {
+			form.Type = models.ProjectMarkTypeSave
+		}
+		if !form.Type.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid mark type %s", form.Type)})
+			return
+		}
+
+		p, err := models.GetProject(uuid.MustParse(c.Param("id")))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		mark := &models.ProjectMark{
+			IdentityID: identity.(*models.Identity).ID,
+			ProjectID:  p.ID,
+			MarkedAs:   form.Type,
+		}
+		if err := mark.Create(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, mark)
+	})
+
+	g.DELETE("/:id/marks", LoginRequired(), func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+		identity, _ := c.Get("identity")
+
+		if err := models.DeleteProjectMark(ctx, identity.(*models.Identity).ID, uuid.MustParse(c.Param("id"))); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "success",
+		})
+	})
+}
+
+// projectForm pre-fills the form with the project for the request to override the fields it has
+func projectForm(p models.Project) *ProjectForm {
+	form := new(ProjectForm)
+	workSamples := p.WorkSamples
+	p.WorkSamples = nil
+	utils.Copy(p, form)
+	for _, sample := range workSamples {
+		if id, err := uuid.Parse(sample.Id); err == nil {
+			form.WorkSamples = append(form.WorkSamples, id)
+		}
+	}
+	return form
 }
@@ -0,0 +1,53 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ratesGroup(router *gin.Engine) {
+	g := router.Group("rates")
+
+	// Rates of the quotes (comma separated, all by default) for one unit of the base
+	g.GET("", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+		base := strings.ToUpper(c.DefaultQuery("base", string(models.USD)))
+		// Unknown bases are refused before reaching the source, they have no rates to cache
+		if !lib.ValidRateBase(base) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown base %s", base)})
+			return
+		}
+
+		rates, err := lib.GetRates(ctx, base)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if quotes := c.Query("quotes"); quotes != "" {
+			wanted := map[string]bool{}
+			for _, q := range strings.Split(quotes, ",") {
+				wanted[strings.ToUpper(strings.TrimSpace(q))] = true
+			}
+			filtered := []models.CurrencyRate{}
+			for _, r := range rates {
+				if wanted[r.Quote] {
+					filtered = append(filtered, r)
+				}
+			}
+			rates = filtered
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"base":    base,
+			"results": rates,
+			"total":   len(rates),
+		})
+	})
+}
@@ -0,0 +1,30 @@
+package views
+
+import (
+	"net/http"
+	"socious/src/apps/models"
+
+	"github.com/gin-gonic/gin"
+	database "github.com/socious-io/pkg_database"
+)
+
+func referralsGroup(router *gin.Engine) {
+	g := router.Group("referrals")
+	g.Use(LoginRequired())
+
+	// Referrers are users, rewards are credited to the user whichever identity is active
+	g.GET("", paginate(), func(c *gin.Context) {
+		user := c.MustGet("user").(*models.User)
+		page, _ := c.Get("paginate")
+
+		referrals, total, err := models.GetReferrals(user.ID, page.(database.Paginate))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"results": referrals,
+			"total":   total,
+		})
+	})
+}
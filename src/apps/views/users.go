@@ -2,11 +2,15 @@ package views
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"socious/src/apps/lib"
 	"socious/src/apps/models"
 	"socious/src/apps/utils"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func usersGroup(router *gin.Engine) {
@@ -32,25 +36,165 @@ func usersGroup(router *gin.Engine) {
 		c.JSON(http.StatusOK, u)
 	})
 
+	g.GET("/wallets", LoginRequired(), func(c *gin.Context) {
+		identity, err := memberIdentity(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		wallets, err := models.GetWallets(identity.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"results": wallets, "total": len(wallets)})
+	})
+
+	g.POST("/wallets/challenge", LoginRequired(), func(c *gin.Context) {
+		user := c.MustGet("user").(*models.User)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		identity, err := memberIdentity(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		form := new(WalletChallengeForm)
+		if err := c.ShouldBindJSON(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		challenge, err := lib.NewWalletChallenge(models.Wallet{
+			UserID:     user.ID,
+			IdentityID: identity.ID,
+			Address:    form.Address,
+			Network:    form.Network,
+			Testnet:    form.Testnet,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := challenge.Create(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, challenge)
+	})
+
 	g.PUT("/wallets", LoginRequired(), func(c *gin.Context) {
 		user := c.MustGet("user").(*models.User)
+		ctx := c.MustGet("ctx").(context.Context)
+
+		identity, err := memberIdentity(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
 		form := new(WalletForm)
 		if err := c.ShouldBindJSON(form); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+
+		challenge, err := models.GetWalletChallenge(form.ChallengeID, user.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if challenge.IdentityID != identity.ID || challenge.Address != form.Address || challenge.Network != form.Network || challenge.Testnet != form.Testnet {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "challenge is issued for another wallet"})
+			return
+		}
+		if err := lib.VerifyWalletSignature(form.Network, form.Address, challenge.Message, form.Signature, form.Key); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := challenge.Use(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		now := time.Now()
 		wallet := &models.Wallet{
-			Network: form.Network,
-			Address: form.Address,
-			Testnet: form.Testnet,
-			UserID:  user.ID,
+			Network:    form.Network,
+			Address:    form.Address,
+			Testnet:    form.Testnet,
+			UserID:     user.ID,
+			IdentityID: identity.ID,
+			VerifiedAt: &now,
 		}
 
-		if err := wallet.Upsert(context.Background()); err != nil {
+		if err := wallet.Upsert(ctx); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
 		c.JSON(http.StatusOK, wallet)
 	})
+
+	g.POST("/wallets/:id/primary", LoginRequired(), func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		identity, err := memberIdentity(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		wallet, err := models.GetWallet(uuid.MustParse(c.Param("id")), identity.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err := wallet.SetPrimary(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, wallet)
+	})
+
+	g.DELETE("/wallets/:id", LoginRequired(), func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		identity, err := memberIdentity(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		wallet, err := models.GetWallet(uuid.MustParse(c.Param("id")), identity.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err := wallet.Delete(ctx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+}
+
+// memberIdentity returns the identity the wallets and cards are managed for, organization ones are managed by their members
+func memberIdentity(c *gin.Context) (*models.Identity, error) {
+	user := c.MustGet("user").(*models.User)
+	identity, _ := c.Get("identity")
+	if identity == nil || identity.(*models.Identity) == nil {
+		return nil, fmt.Errorf("Identity not found")
+	}
+	i := identity.(*models.Identity)
+	if i.Type == models.IdentityTypeOrganizations {
+		if _, err := models.Member(i.ID, user.ID); err != nil {
+			return nil, fmt.Errorf("Identity not allowed")
+		}
+	}
+	return i, nil
 }
@@ -5,7 +5,20 @@ import "github.com/gin-gonic/gin"
 func Init(r *gin.Engine) {
 	authGroup(r)
 	projectsGroup(r)
+	projectApplicantsGroup(r)
+	projectQuestionsGroup(r)
 	contractsGroup(r)
+	contractMilestonesGroup(r)
+	contractDisputesGroup(r)
+	contractWorksGroup(r)
+	contractAmendmentsGroup(r)
+	contractSignaturesGroup(r)
+	contractTemplatesGroup(r)
+	referralsGroup(r)
+	ratesGroup(r)
+	invoicesGroup(r)
+	cardsGroup(r)
+	webhooksGroup(r)
 	usersGroup(r)
 	organizationsGroup(r)
 	identitiesGroup(r)
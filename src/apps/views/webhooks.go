@@ -0,0 +1,214 @@
+package views
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"socious/src/apps/models"
+	"socious/src/config"
+
+	"github.com/socious-io/gopay"
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+func webhooksGroup(router *gin.Engine) {
+	g := router.Group("webhooks")
+
+	g.POST("/stripe", func(c *gin.Context) {
+		ctx := c.MustGet("ctx").(context.Context)
+
+		payload, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		fiat, event, err := verifyStripeEvent(payload, c.GetHeader("Stripe-Signature"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		eventType := string(event.Type)
+		w := &models.Webhook{
+			Party:           models.WebhookPartyStripe,
+			EventID:         &event.ID,
+			EventType:       &eventType,
+			FiatServiceName: &fiat.Name,
+			Content:         payload,
+		}
+		created, err := w.Create(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Stripe delivers events at least once, processed ones are acknowledged without handling again
+		if !created {
+			w, err = models.GetWebhookByEvent(models.WebhookPartyStripe, event.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if w.ProcessedAt != nil {
+				c.JSON(http.StatusOK, gin.H{"received": true, "duplicate": true})
+				return
+			}
+		}
+
+		status, response := http.StatusOK, gin.H{"received": true}
+		if err := handleStripeEvent(ctx, fiat, event); err != nil {
+			status, response = http.StatusInternalServerError, gin.H{"error": err.Error()}
+		}
+
+		body, _ := json.Marshal(response)
+		if err := w.Respond(ctx, status, body); err != nil {
+			log.Printf("failed to record stripe event %s response: %v\n", event.ID, err)
+		}
+		c.JSON(status, response)
+	})
+}
+
+// verifyStripeEvent checks the signature against the webhook secret of every fiat service and returns the one signed it
+func verifyStripeEvent(payload []byte, signature string) (*config.Fiat, stripe.Event, error) {
+	for i, fiat := range config.Config.Payment.Fiats {
+		if fiat.WebhookSecret == "" {
+			continue
+		}
+		event, err := webhook.ConstructEventWithOptions(payload, signature, fiat.WebhookSecret, webhook.ConstructEventOptions{
+			IgnoreAPIVersionMismatch: true,
+		})
+		if err == nil {
+			return &config.Config.Payment.Fiats[i], event, nil
+		}
+	}
+	return nil, stripe.Event{}, fmt.Errorf("invalid stripe signature")
+}
+
+// handleStripeEvent applies the event, events of payments and accounts unknown to us are ignored
+func handleStripeEvent(ctx context.Context, fiat *config.Fiat, event stripe.Event) error {
+	switch event.Type {
+	case "payment_intent.succeeded", "payment_intent.payment_failed", "payment_intent.canceled":
+		intent := new(stripe.PaymentIntent)
+		if err := json.Unmarshal(event.Data.Raw, intent); err != nil {
+			return err
+		}
+		return updateIntentPayment(ctx, intent.ID, event.Type == "payment_intent.succeeded")
+
+	case "charge.dispute.created", "charge.dispute.closed":
+		dispute := new(stripe.Dispute)
+		if err := json.Unmarshal(event.Data.Raw, dispute); err != nil {
+			return err
+		}
+		return flagDisputeChargeback(ctx, dispute)
+
+	case "account.updated":
+		account := new(stripe.Account)
+		if err := json.Unmarshal(event.Data.Raw, account); err != nil {
+			return err
+		}
+		status := models.UserStatusInactive
+		if account.ChargesEnabled && account.PayoutsEnabled {
+			status = models.UserStatusActive
+		}
+		return updateConnectedAccount(ctx, fiat, account.ID, status)
+
+	case "payout.failed":
+		// Connected accounts failing to pay out to their bank can't receive released escrows until
+		// Stripe updates the account again, payouts of the platform account itself are ignored
+		if event.Account == "" {
+			return nil
+		}
+		return updateConnectedAccount(ctx, fiat, event.Account, models.UserStatusInactive)
+
+	case "account.application.deauthorized":
+		return updateConnectedAccount(ctx, fiat, event.Account, models.UserStatusInactive)
+	}
+	return nil
+}
+
+// updateIntentPayment moves the deposit of the payment intent to its final status, succeeded deposits fund
+// their milestones and are invoiced
+func updateIntentPayment(ctx context.Context, intentID string, succeeded bool) error {
+	paymentID, err := models.GetPaymentIDByIntent(intentID)
+	if err != nil || paymentID == nil {
+		return err
+	}
+	payment, err := gopay.Fetch(*paymentID)
+	if err != nil {
+		return err
+	}
+
+	transactionStatus := gopay.CANCELED
+	switch {
+	case payment.Status != gopay.INITIATED && payment.Status != gopay.PENDING_DEPOSIT && payment.Status != gopay.ON_HOLD:
+		// Already settled by the synchronous flow
+		return nil
+	case succeeded:
+		transactionStatus = gopay.VERIFIED
+		payment.Status = gopay.DEPOSITED
+	default:
+		payment.Status = gopay.CANCLED
+	}
+	payment.TransactionStatus = &transactionStatus
+	if err := payment.Update(); err != nil {
+		return err
+	}
+	if succeeded {
+		if err := models.FundMilestonesByPayment(ctx, payment.ID); err != nil {
+			return err
+		}
+		contract, err := models.GetContractByPayment(payment.ID)
+		if err != nil {
+			return err
+		}
+		invoiceDeposit(ctx, contract, payment)
+	}
+	return nil
+}
+
+// flagDisputeChargeback flags the contract of the disputed payment, won disputes clear the flag
+func flagDisputeChargeback(ctx context.Context, dispute *stripe.Dispute) error {
+	if dispute.PaymentIntent == nil {
+		return nil
+	}
+	paymentID, err := models.GetPaymentIDByIntent(dispute.PaymentIntent.ID)
+	if err != nil || paymentID == nil {
+		return err
+	}
+	contract, err := models.GetContractByPayment(*paymentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	disputeID := &dispute.ID
+	if dispute.Status == stripe.DisputeStatusWon {
+		disputeID = nil
+	}
+	return contract.FlagChargeback(ctx, disputeID)
+}
+
+func updateConnectedAccount(ctx context.Context, fiat *config.Fiat, accountID string, status models.UserStatus) error {
+	oc, err := models.GetOauthConnectByMUI(accountID, models.OauthConnectedProviders(fiat.Name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if oc.Status == status {
+		return nil
+	}
+	return oc.UpdateStatus(ctx, status)
+}
@@ -0,0 +1,170 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"socious/src/config"
+
+	"github.com/socious-io/gopay"
+)
+
+// Checks of a pending deposit before it fails when the deposits config is missing
+const depositsAttempts = 48
+
+// VerifyCryptoDeposit checks the queued deposit once, deposits still pending are checked again
+// by the crypto-deposits job on its interval.
+func VerifyCryptoDeposit(form CryptoDepositForm) error {
+	deposit, err := models.GetCryptoDeposit(form.ID)
+	if err != nil {
+		log.Printf("VerifyCryptoDeposit: Error fetching deposit %s: %v\n", form.ID, err)
+		return err
+	}
+	if deposit.Status != models.CryptoDepositStatusPending {
+		return nil
+	}
+
+	client := lib.NewChainClient(config.Config.Payment.Chains)
+	if err := CheckCryptoDeposit(context.Background(), client, deposit); err != nil {
+		log.Printf("VerifyCryptoDeposit: Error checking deposit %s: %v\n", deposit.ID, err)
+		return err
+	}
+	return nil
+}
+
+// CheckPendingCryptoDeposits checks the pending deposits once each.
+func CheckPendingCryptoDeposits(ctx context.Context) error {
+	deposits, err := models.GetPendingCryptoDeposits(jobsBatchSize)
+	if err != nil {
+		return err
+	}
+
+	client := lib.NewChainClient(config.Config.Payment.Chains)
+	for _, d := range deposits {
+		if err := CheckCryptoDeposit(ctx, client, &d); err != nil {
+			log.Printf("CheckPendingCryptoDeposits: Error checking deposit %s: %v\n", d.ID, err)
+		}
+	}
+	return nil
+}
+
+// CheckCryptoDeposit looks the deposit transaction up once. A transfer not matching the deposit fails it
+// right away, a matching one confirms it with enough confirmations and the payment is deposited.
+// Deposits not confirmed within the attempts fail.
+func CheckCryptoDeposit(ctx context.Context, client lib.ChainClient, deposit *models.CryptoDeposit) error {
+	var (
+		confirmations int
+		reason        *string
+	)
+
+	receipt, err := client.Receipt(ctx, deposit.TxID, deposit.TokenAddress)
+	switch {
+	case err != nil:
+		msg := err.Error()
+		reason = &msg
+	case receipt == nil:
+		msg := "transaction not found"
+		reason = &msg
+	default:
+		confirmations = receipt.Confirmations
+		if err := lib.MatchReceipt(*deposit, *receipt); err != nil {
+			return failCryptoDeposit(ctx, deposit, confirmations, err)
+		}
+		if confirmations >= deposit.RequiredConfirmations {
+			return confirmCryptoDeposit(ctx, deposit, *receipt)
+		}
+	}
+
+	attempts := configOr(config.Config.Jobs.Deposits.Attempts, depositsAttempts)
+	if deposit.Attempts+1 >= attempts {
+		cause := fmt.Errorf("not confirmed after %d attempts", attempts)
+		if reason != nil {
+			cause = fmt.Errorf("%w: %s", cause, *reason)
+		}
+		return failCryptoDeposit(ctx, deposit, confirmations, cause)
+	}
+	return deposit.Check(ctx, models.CryptoDepositStatusPending, confirmations, reason)
+}
+
+// confirmCryptoDeposit deposits the payment and funds its milestones before the deposit is confirmed,
+// a retry after a failure reuses the deposit transaction recorded already.
+func confirmCryptoDeposit(ctx context.Context, deposit *models.CryptoDeposit, receipt lib.TxReceipt) error {
+	var payment *gopay.Payment
+	err := deposit.Confirm(ctx, receipt.Confirmations, func() error {
+		var err error
+		if payment, err = gopay.Fetch(deposit.PaymentID); err != nil {
+			return err
+		}
+
+		if !depositTransactionRecorded(payment, deposit.TxID) {
+			t := &gopay.Transaction{
+				PaymentID:  payment.ID,
+				TXID:       deposit.TxID,
+				IdentityID: deposit.IdentityID,
+				Tag:        string(gopay.DEPOSIT),
+				Amount:     payment.TotalAmount,
+				Type:       gopay.DEPOSIT,
+			}
+			t.Meta, _ = json.Marshal(map[string]interface{}{"info": receipt, "meta": deposit.Meta})
+			if err := t.Create(); err != nil {
+				return err
+			}
+			if err := t.Verify(); err != nil {
+				return err
+			}
+		}
+
+		if payment.Status != gopay.DEPOSITED {
+			status := gopay.VERIFIED
+			payment.Status = gopay.DEPOSITED
+			payment.TransactionStatus = &status
+			payment.Meta = deposit.Meta
+			if err := payment.Update(); err != nil {
+				return err
+			}
+		}
+		return models.FundMilestonesByPayment(ctx, payment.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	contract, err := models.GetContract(deposit.ContractID)
+	if err != nil {
+		return err
+	}
+	if _, err := lib.IssueDepositInvoice(ctx, *contract, payment); err != nil {
+		log.Printf("CheckCryptoDeposit: Error invoicing deposit %s: %v\n", deposit.ID, err)
+	}
+	return nil
+}
+
+// depositTransactionRecorded reports whether the deposit transaction has been recorded on the payment already
+func depositTransactionRecorded(payment *gopay.Payment, txID string) bool {
+	for _, t := range payment.Transactions {
+		if t.Type == gopay.DEPOSIT && t.TXID == txID && t.CanceledAt == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func failCryptoDeposit(ctx context.Context, deposit *models.CryptoDeposit, confirmations int, cause error) error {
+	reason := cause.Error()
+	if err := deposit.Check(ctx, models.CryptoDepositStatusFailed, confirmations, &reason); err != nil {
+		return err
+	}
+	log.Printf("CheckCryptoDeposit: Deposit %s failed: %s\n", deposit.ID, reason)
+
+	payment, err := gopay.Fetch(deposit.PaymentID)
+	if err != nil {
+		return err
+	}
+	status := gopay.CANCELED
+	payment.Status = gopay.CANCLED
+	payment.TransactionStatus = &status
+	return payment.Update()
+}
@@ -1,6 +1,9 @@
 package workers
 
-import "github.com/socious-io/goaccount"
+import (
+	"github.com/google/uuid"
+	"github.com/socious-io/goaccount"
+)
 
 type SyncForm struct {
 	Organizations []goaccount.Organization `json:"organizations"`
@@ -11,3 +14,16 @@ type DeleteUserForm struct {
 	User   goaccount.User `json:"user" validate:"required"`
 	Reason string         `json:"reason" validate:"required"`
 }
+
+type CryptoDepositForm struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
+type InvoiceForm struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
+type ReferralPayoutForm struct {
+	ID   uuid.UUID `json:"id" validate:"required"`
+	TxID string    `json:"tx_id" validate:"required"`
+}
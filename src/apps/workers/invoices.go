@@ -0,0 +1,63 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"socious/src/apps/models"
+	"socious/src/config"
+
+	"github.com/socious-io/gomail"
+)
+
+// EmailInvoice emails the queued invoice to both parties unless it's been emailed already
+func EmailInvoice(form InvoiceForm) error {
+	invoice, err := models.GetInvoice(form.ID)
+	if err != nil {
+		log.Printf("EmailInvoice: Error fetching invoice %s: %v\n", form.ID, err)
+		return err
+	}
+	if invoice.EmailedAt != nil {
+		return nil
+	}
+	return sendInvoiceEmail(context.Background(), invoice)
+}
+
+// SendInvoiceEmails emails the invoices missed by the queue
+func SendInvoiceEmails(ctx context.Context) error {
+	invoices, err := models.GetNotEmailedInvoices(jobsBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, i := range invoices {
+		if err := sendInvoiceEmail(ctx, &i); err != nil {
+			log.Printf("SendInvoiceEmails: Error emailing invoice %s: %v\n", i.ID, err)
+		}
+	}
+	return nil
+}
+
+func sendInvoiceEmail(ctx context.Context, invoice *models.Invoice) error {
+	for _, details := range []*models.BillingDetails{invoice.RecipientDetails, invoice.IssuerDetails} {
+		if details == nil || details.Email == nil {
+			continue
+		}
+		err := gomail.SendEmail(gomail.EmailConfig{
+			Approach:    gomail.EmailApproachTemplate,
+			Destination: *details.Email,
+			Title:       fmt.Sprintf("Invoice %s", invoice.Number),
+			Template:    "invoice",
+			Args: map[string]string{
+				"name":     details.Name,
+				"number":   invoice.Number,
+				"total":    fmt.Sprintf("%v", invoice.Total),
+				"currency": invoice.Currency,
+				"url":      fmt.Sprintf("%s/invoices/%s/pdf", config.Config.Host, invoice.ID),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return invoice.MarkEmailed(ctx)
+}
@@ -0,0 +1,151 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"socious/src/apps/models"
+	"socious/src/config"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/socious-io/gomail"
+)
+
+// Defaults used when the jobs section is missing from the config
+const (
+	jobsInterval         = 60 // minutes
+	unsignedContractsTTL = 14 // days
+	expiryReminderDays   = 3  // days
+	jobsBatchSize        = 100
+)
+
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+var jobs = []Job{
+	{Name: "expire-projects", Run: ExpireProjects},
+	{Name: "cancel-unsigned-contracts", Run: CancelUnsignedContracts},
+	{Name: "expiry-reminders", Run: SendExpiryReminders},
+	{Name: "referral-payouts", Run: PayoutReferralRewards},
+	{Name: "crypto-deposits", Run: CheckPendingCryptoDeposits},
+	{Name: "invoice-emails", Run: SendInvoiceEmails},
+}
+
+// ScheduleJobs runs the periodic jobs on every interval, it blocks so call it on its own goroutine.
+func ScheduleJobs() {
+	interval := configOr(config.Config.Jobs.Interval, jobsInterval)
+	ticker := time.NewTicker(time.Duration(interval) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		RunJobs(context.Background())
+		<-ticker.C
+	}
+}
+
+// RunJobs runs every job once, a failing job doesn't stop the others.
+func RunJobs(ctx context.Context) {
+	for _, job := range jobs {
+		if err := job.Run(ctx); err != nil {
+			log.Printf("Job %s failed: %v\n", job.Name, err)
+		}
+	}
+}
+
+func ExpireProjects(ctx context.Context) error {
+	ids, err := models.ExpireProjects(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		log.Printf("ExpireProjects: %d projects expired\n", len(ids))
+	}
+	return nil
+}
+
+// CancelUnsignedContracts cancels on behalf of the provider the contracts not signed within the TTL.
+func CancelUnsignedContracts(ctx context.Context) error {
+	ttl := configOr(config.Config.Jobs.Contracts.Ttl, unsignedContractsTTL)
+	contracts, err := models.GetUnsignedContracts(ttl, jobsBatchSize)
+	if err != nil {
+		return err
+	}
+
+	reason := fmt.Sprintf("not signed within %d days", ttl)
+	for _, contract := range contracts {
+		if err := contract.TransitOnBehalf(ctx, models.ContractPartyProvider, models.ContractStatusProviderCanceled, &reason); err != nil {
+			log.Printf("CancelUnsignedContracts: Error canceling contract %s: %v\n", contract.ID, err)
+		}
+	}
+	return nil
+}
+
+// SendExpiryReminders emails the owners of projects and the clients of unsigned contracts before they expire.
+func SendExpiryReminders(ctx context.Context) error {
+	ttl := configOr(config.Config.Jobs.Contracts.Ttl, unsignedContractsTTL)
+
+	projects, err := models.GetExpiringProjects(configOr(config.Config.Jobs.Projects.Reminder, expiryReminderDays), jobsBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, p := range projects {
+		id := p.ID
+		sendExpiryReminder(ctx, p, &id, nil, "project-expiry-reminder", "Your project is about to expire")
+	}
+
+	contracts, err := models.GetExpiringContracts(ttl, configOr(config.Config.Jobs.Contracts.Reminder, expiryReminderDays), jobsBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, c := range contracts {
+		id := c.ID
+		sendExpiryReminder(ctx, c, nil, &id, "contract-expiry-reminder", "Your contract is waiting for your signature")
+	}
+	return nil
+}
+
+func sendExpiryReminder(ctx context.Context, r models.ExpiryReminder, projectID, contractID *uuid.UUID, template, title string) {
+	if r.Email == nil {
+		return
+	}
+	claimed, err := models.ClaimExpiryReminder(ctx, projectID, contractID)
+	if err != nil {
+		log.Printf("SendExpiryReminders: Error claiming reminder %s: %v\n", r.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	name := ""
+	if r.Name != nil {
+		name = *r.Name
+	}
+	err = gomail.SendEmail(gomail.EmailConfig{
+		Approach:    gomail.EmailApproachTemplate,
+		Destination: *r.Email,
+		Title:       title,
+		Template:    template,
+		Args: map[string]string{
+			"name":       name,
+			"title":      r.Title,
+			"expires_at": r.ExpiresAt.Format("2006-01-02"),
+		},
+	})
+	if err != nil {
+		log.Printf("SendExpiryReminders: Error sending reminder %s: %v\n", r.ID, err)
+		if err := models.ReleaseExpiryReminder(ctx, projectID, contractID); err != nil {
+			log.Printf("SendExpiryReminders: Error releasing reminder %s: %v\n", r.ID, err)
+		}
+	}
+}
+
+func configOr(value, fallback int) int {
+	if value < 1 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,103 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"socious/src/config"
+
+	"github.com/socious-io/gomq"
+)
+
+// PayoutReferralRewards schedules the pending referral rewards of each referrer to their registered wallet on the
+// token network or their Stripe account, then pays the scheduled payouts out. Rewards of referrers without one
+// stay pending until they register it, rewards in a token unknown on the wallet network are left pending.
+func PayoutReferralRewards(ctx context.Context) error {
+	pending, err := models.GetPendingReferralPayouts(jobsBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pending {
+		if p.Network != nil {
+			if _, err := lib.FindNetworkToken(config.Config.Payment.Chains, *p.Network, p.Currency); err != nil {
+				log.Printf("PayoutReferralRewards: Skipping rewards of %s: %v\n", p.ReferrerID, err)
+				continue
+			}
+		}
+		payout, err := p.Settle(ctx)
+		if err != nil {
+			log.Printf("PayoutReferralRewards: Error scheduling rewards of %s: %v\n", p.ReferrerID, err)
+			continue
+		}
+		log.Printf("PayoutReferralRewards: %v %s scheduled to %s\n", payout.Amount, payout.Currency, payout.Address)
+
+		// Crypto payouts are sent from the platform wallet, the transaction is reported back on referral_payouts_transferred
+		if payout.Network != nil {
+			gomq.Mq.SendJson("referral_payouts", map[string]interface{}{
+				"id":       payout.ID,
+				"address":  payout.Address,
+				"network":  payout.Network,
+				"currency": payout.Currency,
+				"amount":   payout.Amount,
+			})
+		}
+	}
+
+	payouts, err := models.GetScheduledReferralPayouts(jobsBatchSize)
+	if err != nil {
+		return err
+	}
+	client := lib.NewChainClient(config.Config.Payment.Chains)
+	for _, p := range payouts {
+		if err := PayReferralPayout(ctx, client, &p); err != nil {
+			log.Printf("PayoutReferralRewards: Error paying out %s: %v\n", p.ID, err)
+		}
+	}
+	return nil
+}
+
+// TransferredReferralPayout records the transaction the queued crypto payout has been sent with and checks it once,
+// payouts not confirmed yet are checked again by the referral-payouts job.
+func TransferredReferralPayout(form ReferralPayoutForm) error {
+	ctx := context.Background()
+	payout, err := models.GetReferralPayout(form.ID)
+	if err != nil {
+		log.Printf("TransferredReferralPayout: Error fetching payout %s: %v\n", form.ID, err)
+		return err
+	}
+	if err := payout.Transferred(ctx, form.TxID); err != nil {
+		log.Printf("TransferredReferralPayout: Error recording transfer of %s: %v\n", payout.ID, err)
+		return err
+	}
+	return PayReferralPayout(ctx, lib.NewChainClient(config.Config.Payment.Chains), payout)
+}
+
+// PayReferralPayout pays the scheduled payout out. Fiat payouts are transferred to the Stripe account of the referrer,
+// crypto ones are paid out once the transaction they're sent with is confirmed on chain to the referrer wallet.
+func PayReferralPayout(ctx context.Context, client lib.ChainClient, payout *models.ReferralPayout) error {
+	if payout.FiatService != nil {
+		transferID, err := lib.TransferReferralPayout(*payout)
+		if err != nil {
+			return err
+		}
+		return payout.PayOut(ctx, transferID)
+	}
+
+	// Waiting for the transfer from the platform wallet
+	if payout.TxID == nil {
+		return nil
+	}
+	receipt, err := client.Receipt(ctx, *payout.TxID, payout.Currency)
+	if err != nil || receipt == nil {
+		return err
+	}
+	if err := lib.MatchPayoutReceipt(*payout, *receipt); err != nil {
+		return err
+	}
+	if receipt.Confirmations < lib.DepositConfirmations() {
+		return nil
+	}
+	return payout.PayOut(ctx, *payout.TxID)
+}
@@ -22,6 +22,21 @@ func RegisterConsumers() {
 			Consumer:      gomq.NewConsumer(SyncIdentities),
 			IsCategorized: false,
 		},
+		{
+			Channel:       "crypto_deposits",
+			Consumer:      gomq.NewConsumer(VerifyCryptoDeposit),
+			IsCategorized: false,
+		},
+		{
+			Channel:       "invoice_emails",
+			Consumer:      gomq.NewConsumer(EmailInvoice),
+			IsCategorized: false,
+		},
+		{
+			Channel:       "referral_payouts_transferred",
+			Consumer:      gomq.NewConsumer(TransferredReferralPayout),
+			IsCategorized: false,
+		},
 	}
 
 	for _, consumer := range consumers {
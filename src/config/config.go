@@ -42,12 +42,63 @@ type ConfigType struct {
 	} `mapstructure:"nats"`
 	Payment struct {
 		Chains gopay.Chains `mapstructure:"chains"`
-		Fiats  gopay.Fiats  `mapstructure:"fiats"`
+		Fiats  Fiats        `mapstructure:"fiats"`
+		// Blocks on top of a crypto deposit before it's confirmed
+		Confirmations int `mapstructure:"confirmations"`
 	} `mapstructure:"payment"`
+	Contracts struct {
+		Template string `mapstructure:"template"`
+	} `mapstructure:"contracts"`
+	Invoices struct {
+		Template string `mapstructure:"template"`
+		// Tax included in the invoiced totals
+		TaxRate float64 `mapstructure:"taxrate"`
+	} `mapstructure:"invoices"`
+	Rates struct {
+		// file or http
+		Source string `mapstructure:"source"`
+		File   string `mapstructure:"file"`
+		Url    string `mapstructure:"url"`
+		ApiKey string `mapstructure:"apikey"`
+		Ttl    int    `mapstructure:"ttl"` // minutes
+	} `mapstructure:"rates"`
+	Referrals struct {
+		Share float64 `mapstructure:"share"`
+	} `mapstructure:"referrals"`
+	Jobs struct {
+		Interval  int `mapstructure:"interval"`
+		Contracts struct {
+			Ttl      int `mapstructure:"ttl"`
+			Reminder int `mapstructure:"reminder"`
+		} `mapstructure:"contracts"`
+		Projects struct {
+			Reminder int `mapstructure:"reminder"`
+		} `mapstructure:"projects"`
+		Deposits struct {
+			Attempts int `mapstructure:"attempts"`
+		} `mapstructure:"deposits"`
+	} `mapstructure:"jobs"`
 	GoAccounts     goaccount.Config `mapstructure:"goaccounts"`
 	SendgridApiKey string           `mapstructure:"sendgrid_api_key"`
 }
 
+// Fiat is a gopay fiat service with the secret its webhook events are signed with
+type Fiat struct {
+	gopay.Fiat    `yaml:",inline"`
+	WebhookSecret string `mapstructure:"webhooksecret"`
+}
+
+type Fiats []Fiat
+
+// Gopay returns the fiat services as gopay expects them
+func (fiats Fiats) Gopay() gopay.Fiats {
+	services := gopay.Fiats{}
+	for _, f := range fiats {
+		services = append(services, f.Fiat)
+	}
+	return services
+}
+
 func Init(filename string) (*ConfigType, error) {
 	file, err := os.Open(filename)
 	if err != nil {
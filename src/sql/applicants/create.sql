@@ -0,0 +1,3 @@
+INSERT INTO applicants (project_id, user_id, cover_letter, payment_rate, payment_type, cv_link, cv_name, share_contact_info)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *
@@ -0,0 +1,6 @@
+INSERT INTO answers(
+    project_id, question_id, applicant_id, answer, selected_option
+)
+VALUES (
+    :project_id, :question_id, :applicant_id, :answer, :selected_option
+)
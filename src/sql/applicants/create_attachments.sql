@@ -0,0 +1,6 @@
+INSERT INTO applicant_attachments(
+    applicant_id, media_id
+)
+VALUES (
+    :applicant_id, :media_id
+)
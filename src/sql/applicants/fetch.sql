@@ -0,0 +1,27 @@
+SELECT a.*,
+  row_to_json(i.*) AS user,
+  COALESCE(
+    (SELECT jsonb_agg(json_build_object('id', m.id, 'url', m.url, 'filename', m.filename) ORDER BY aa.created_at)
+    FROM applicant_attachments aa
+    JOIN media m ON m.id=aa.media_id
+    WHERE aa.applicant_id=a.id),
+    '[]'
+  ) AS attachments,
+  COALESCE(
+    (SELECT jsonb_agg(json_build_object(
+      'id', an.id,
+      'question_id', an.question_id,
+      'question', q.question,
+      'answer', an.answer,
+      'selected_option', an.selected_option,
+      'option', q.options[an.selected_option + 1]
+    ) ORDER BY q.created_at)
+    FROM answers an
+    JOIN questions q ON q.id=an.question_id
+    WHERE an.applicant_id=a.id),
+    '[]'
+  ) AS answers
+FROM applicants a
+JOIN identities i ON i.id=a.user_id
+WHERE a.id IN (?)
+ORDER BY a.created_at DESC
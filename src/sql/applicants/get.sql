@@ -0,0 +1,6 @@
+SELECT id, COUNT(*) OVER () as total_count
+FROM applicants a
+WHERE a.project_id=$1 AND a.deleted_at IS NULL
+  AND (COALESCE(cardinality($4::text[]), 0)=0 OR a.status::text=ANY($4::text[]))
+ORDER BY a.created_at DESC
+LIMIT $2 OFFSET $3
@@ -0,0 +1,3 @@
+UPDATE applicants SET status='HIRED', closed_at=NOW(), updated_at=NOW()
+WHERE id=$1 AND status IN ('PENDING', 'SHORTLISTED')
+RETURNING *
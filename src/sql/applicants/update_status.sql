@@ -0,0 +1,3 @@
+UPDATE applicants SET status=$2, feedback=COALESCE($3, feedback), closed_at=$4, updated_at=NOW()
+WHERE id=$1
+RETURNING *
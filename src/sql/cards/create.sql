@@ -0,0 +1,4 @@
+INSERT INTO cards (identity_id, holder_name, brand, meta, customer, is_jp, is_default)
+VALUES ($1, $2, $3, $4, $5, $6,
+  NOT EXISTS (SELECT 1 FROM cards WHERE identity_id=$1 AND is_default))
+RETURNING *
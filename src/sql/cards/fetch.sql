@@ -0,0 +1 @@
+SELECT * FROM cards WHERE id IN (?)
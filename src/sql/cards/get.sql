@@ -0,0 +1,3 @@
+SELECT * FROM cards
+WHERE identity_id=$1
+ORDER BY is_default DESC, created_at DESC
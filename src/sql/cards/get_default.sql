@@ -0,0 +1 @@
+SELECT * FROM cards WHERE identity_id=$1 AND is_default
@@ -0,0 +1,8 @@
+UPDATE cards SET is_default=true, updated_at=NOW()
+WHERE id=(
+  SELECT id FROM cards
+  WHERE identity_id=$1
+  ORDER BY created_at DESC
+  LIMIT 1
+)
+RETURNING *
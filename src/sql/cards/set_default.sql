@@ -0,0 +1,3 @@
+UPDATE cards SET is_default=true, updated_at=NOW()
+WHERE id=$1
+RETURNING *
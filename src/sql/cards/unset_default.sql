@@ -0,0 +1,2 @@
+UPDATE cards SET is_default=false, updated_at=NOW()
+WHERE identity_id=$1 AND is_default
@@ -0,0 +1,6 @@
+UPDATE contract_milestones SET
+  status='APPROVED',
+  released_at=NOW(),
+  updated_at=NOW()
+WHERE id=$1 AND status='FUNDED'
+RETURNING *
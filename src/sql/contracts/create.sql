@@ -14,7 +14,8 @@ INSERT INTO contracts (
   applicant_id,
   provider_id,
   client_id,
-  crypto_network
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+  crypto_network,
+  requirement_description
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 ON CONFLICT (client_id, provider_id, project_id) WHERE status='CREATED' DO UPDATE SET id=contracts.id
 RETURNING *
\ No newline at end of file
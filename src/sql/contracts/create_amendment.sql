@@ -0,0 +1,3 @@
+INSERT INTO contract_amendments (contract_id, proposer_id, old_terms, new_terms, reason)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *
@@ -0,0 +1,3 @@
+INSERT INTO contract_milestones (contract_id, title, description, amount, due_date)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *
@@ -0,0 +1,3 @@
+INSERT INTO contract_signatures (contract_id, identity_id, document, hash, ip_address, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *
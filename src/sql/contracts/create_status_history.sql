@@ -0,0 +1,8 @@
+INSERT INTO contract_status_history (
+  contract_id,
+  identity_id,
+  old_status,
+  new_status,
+  reason
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING *
\ No newline at end of file
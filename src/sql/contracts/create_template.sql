@@ -0,0 +1,17 @@
+INSERT INTO contract_templates (
+  identity_id,
+  title,
+  name,
+  description,
+  type,
+  total_amount,
+  currency,
+  crypto_currency,
+  crypto_network,
+  commitment,
+  commitment_period,
+  commitment_period_count,
+  payment_type,
+  requirement_description
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING *
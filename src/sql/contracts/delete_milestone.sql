@@ -0,0 +1 @@
+DELETE FROM contract_milestones WHERE id=$1 AND status='PENDING'
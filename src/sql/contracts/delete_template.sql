@@ -0,0 +1 @@
+DELETE FROM contract_templates WHERE id=$1
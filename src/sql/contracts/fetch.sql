@@ -1,10 +1,11 @@
 SELECT c.*,
   row_to_json(id1.*) as provider,
   row_to_json(id2.*) as client,
-  (SELECT row_to_json(w.*) FROM wallets w WHERE w.user_id=c.client_id AND w.network=c.crypto_network AND w.testnet=false LIMIT 1) AS client_wallet,
+  (SELECT row_to_json(w.*) FROM wallets w WHERE w.identity_id=c.client_id AND w.network=c.crypto_network AND w.is_primary AND w.verified_at IS NOT NULL) AS client_wallet,
   row_to_json(a.*) as applicant,
   row_to_json(p.*) as project,
   row_to_json(pay.*) as payment,
+  row_to_json(fp.*) as fee_policy,
   EXISTS(SELECT f.id FROM feedbacks f WHERE f.contract_id=c.id AND f.identity_id=c.provider_id) AS provider_feedback,
   EXISTS(SELECT f.id FROM feedbacks f WHERE f.contract_id=c.id AND f.identity_id=c.client_id) AS client_feedback,
   (
@@ -23,12 +24,27 @@ SELECT c.*,
       ),
       '[]'
     )
-  ) AS requirement_files
+  ) AS requirement_files,
+  COALESCE(
+    (SELECT jsonb_agg(to_jsonb(cm.*) ORDER BY cm.due_date ASC NULLS LAST, cm.created_at ASC)
+    FROM contract_milestones cm
+    WHERE cm.contract_id=c.id),
+    '[]'
+  ) AS milestones,
+  COALESCE(
+    (SELECT jsonb_agg(to_jsonb(ca.*) ORDER BY ca.created_at DESC)
+    FROM contract_amendments ca
+    WHERE ca.contract_id=c.id),
+    '[]'
+  ) AS amendments,
+  (SELECT COALESCE(SUM(sw.total_hours), 0) FROM submitted_works sw WHERE sw.contract_id=c.id AND sw.status='CONFIRMED') AS approved_hours,
+  (SELECT COALESCE(SUM(sw.amount), 0) FROM submitted_works sw WHERE sw.contract_id=c.id AND sw.status='CONFIRMED') AS approved_amount
 FROM contracts c
 JOIN identities id1 ON id1.id = c.provider_id
 JOIN identities id2 ON id2.id = c.client_id
 LEFT JOIN projects p ON p.id = c.project_id
 LEFT JOIN applicants a ON a.id = c.applicant_id
 LEFT JOIN gopay_payments pay ON pay.id = c.payment_id
+LEFT JOIN fee_policies fp ON fp.id = c.fee_policy_id
 WHERE c.id IN (?)
 ORDER BY c.created_at DESC
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT ca.* FROM contract_amendments ca
+WHERE ca.id IN (?)
+ORDER BY ca.created_at DESC
@@ -0,0 +1,6 @@
+SELECT cm.*,
+  row_to_json(pay.*) as payment
+FROM contract_milestones cm
+LEFT JOIN gopay_payments pay ON pay.id = cm.payment_id
+WHERE cm.id IN (?)
+ORDER BY cm.due_date ASC NULLS LAST, cm.created_at ASC
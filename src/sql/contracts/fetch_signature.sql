@@ -0,0 +1,6 @@
+SELECT cs.*,
+  row_to_json(i.*) as identity
+FROM contract_signatures cs
+JOIN identities i ON i.id = cs.identity_id
+WHERE cs.id IN (?)
+ORDER BY cs.created_at DESC
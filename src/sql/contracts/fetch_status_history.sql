@@ -0,0 +1,6 @@
+SELECT h.*,
+  row_to_json(i.*) AS identity
+FROM contract_status_history h
+LEFT JOIN identities i ON i.id = h.identity_id
+WHERE h.id IN (?)
+ORDER BY h.created_at DESC
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT ct.* FROM contract_templates ct
+WHERE ct.id IN (?)
+ORDER BY ct.created_at DESC
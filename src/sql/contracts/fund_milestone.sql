@@ -0,0 +1,7 @@
+UPDATE contract_milestones SET
+  status='FUNDED',
+  payment_id=$2,
+  funded_at=NOW(),
+  updated_at=NOW()
+WHERE id=$1 AND status='PENDING'
+RETURNING *
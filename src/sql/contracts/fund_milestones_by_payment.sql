@@ -0,0 +1,6 @@
+UPDATE contract_milestones SET
+  status='FUNDED',
+  funded_at=NOW(),
+  updated_at=NOW()
+WHERE payment_id=$1 AND status='PENDING'
+RETURNING id
@@ -0,0 +1,4 @@
+SELECT ca.id, COUNT(*) OVER () as total_count
+FROM contract_amendments ca
+WHERE ca.contract_id = $1
+ORDER BY ca.created_at DESC
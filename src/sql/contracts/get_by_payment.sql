@@ -0,0 +1,4 @@
+SELECT c.id FROM contracts c
+WHERE c.payment_id=$1
+  OR c.id IN (SELECT cm.contract_id FROM contract_milestones cm WHERE cm.payment_id=$1)
+LIMIT 1
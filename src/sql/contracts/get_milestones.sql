@@ -0,0 +1,4 @@
+SELECT cm.id, COUNT(*) OVER () as total_count
+FROM contract_milestones cm
+WHERE cm.contract_id = $1
+ORDER BY cm.due_date ASC NULLS LAST, cm.created_at ASC
@@ -0,0 +1,4 @@
+SELECT t.payment_id AS id FROM gopay_transactions t
+WHERE t.type='DEPOSIT' AND t.meta->'info'->>'tx_id'=$1
+ORDER BY t.created_at DESC
+LIMIT 1
@@ -0,0 +1,4 @@
+SELECT cs.id, COUNT(*) OVER () as total_count
+FROM contract_signatures cs
+WHERE cs.contract_id = $1
+ORDER BY cs.created_at DESC
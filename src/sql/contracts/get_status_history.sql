@@ -0,0 +1,5 @@
+SELECT h.id, COUNT(*) OVER () as total_count
+FROM contract_status_history h
+WHERE h.contract_id = $1
+ORDER BY h.created_at DESC
+LIMIT $2 OFFSET $3
\ No newline at end of file
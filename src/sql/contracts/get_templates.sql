@@ -0,0 +1,5 @@
+SELECT ct.id, COUNT(*) OVER () as total_count
+FROM contract_templates ct
+WHERE ct.identity_id=$1
+ORDER BY ct.created_at DESC
+LIMIT $2 OFFSET $3
@@ -0,0 +1,5 @@
+UPDATE contract_milestones SET
+  payment_id=$2,
+  updated_at=NOW()
+WHERE id=$1 AND status='PENDING'
+RETURNING *
@@ -0,0 +1 @@
+SELECT id FROM contracts WHERE id=$1 FOR UPDATE
@@ -0,0 +1,4 @@
+UPDATE contracts
+SET locked_currency_rate=$2, currency_rate_locked_at=NOW(), updated_at=NOW()
+WHERE id=$1 AND currency_rate_locked_at IS NULL
+RETURNING *
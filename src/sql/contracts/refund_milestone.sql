@@ -0,0 +1,5 @@
+UPDATE contract_milestones SET
+  status='REFUNDED',
+  updated_at=NOW()
+WHERE id=$1 AND status='FUNDED'
+RETURNING *
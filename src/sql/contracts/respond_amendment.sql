@@ -0,0 +1,7 @@
+UPDATE contract_amendments SET
+  status=$3,
+  responder_id=$2,
+  responded_at=NOW(),
+  updated_at=NOW()
+WHERE id=$1 AND status='PENDING'
+RETURNING *
@@ -9,10 +9,9 @@ UPDATE contracts SET
   commitment_period=$9,
   commitment_period_count=$10,
   payment_type=$11,
-  status=COALESCE($12, status),
-  payment_id=COALESCE($13, payment_id),
-  requirement_description=$14,
-  crypto_network=$15,
+  payment_id=COALESCE($12, payment_id),
+  requirement_description=$13,
+  crypto_network=$14,
   updated_at=NOW()
 WHERE id=$1
 RETURNING *
\ No newline at end of file
@@ -0,0 +1,6 @@
+UPDATE contracts
+SET chargeback_id=$2,
+  chargeback_at=CASE WHEN $2::text IS NULL THEN NULL ELSE NOW() END,
+  updated_at=NOW()
+WHERE id=$1
+RETURNING *
@@ -0,0 +1,3 @@
+UPDATE contracts SET fee_policy_id=$2
+WHERE id=$1 AND fee_policy_id IS NULL
+RETURNING *
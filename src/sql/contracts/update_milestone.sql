@@ -0,0 +1,8 @@
+UPDATE contract_milestones SET
+  title=$2,
+  description=$3,
+  amount=$4,
+  due_date=$5,
+  updated_at=NOW()
+WHERE id=$1 AND status='PENDING'
+RETURNING *
@@ -0,0 +1,5 @@
+UPDATE contracts SET
+  status=$3,
+  updated_at=NOW()
+WHERE id=$1 AND status=$2
+RETURNING *
\ No newline at end of file
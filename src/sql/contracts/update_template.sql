@@ -0,0 +1,17 @@
+UPDATE contract_templates SET
+  title=$2,
+  name=$3,
+  description=$4,
+  type=$5,
+  total_amount=$6,
+  currency=$7,
+  crypto_currency=$8,
+  crypto_network=$9,
+  commitment=$10,
+  commitment_period=$11,
+  commitment_period_count=$12,
+  payment_type=$13,
+  requirement_description=$14,
+  updated_at=NOW()
+WHERE id=$1
+RETURNING *
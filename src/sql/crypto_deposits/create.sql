@@ -0,0 +1,3 @@
+INSERT INTO crypto_deposits (contract_id, payment_id, identity_id, tx_id, token_address, recipient, expected_amount, required_confirmations, meta)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *
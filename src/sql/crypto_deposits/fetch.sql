@@ -0,0 +1,2 @@
+SELECT cd.* FROM crypto_deposits cd
+WHERE cd.id IN (?)
@@ -0,0 +1,4 @@
+SELECT * FROM crypto_deposits
+WHERE status='PENDING'
+ORDER BY checked_at ASC NULLS FIRST
+LIMIT $1
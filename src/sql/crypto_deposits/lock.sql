@@ -0,0 +1 @@
+SELECT * FROM crypto_deposits WHERE id=$1 FOR UPDATE
@@ -0,0 +1,10 @@
+UPDATE crypto_deposits SET
+  status=$2,
+  confirmations=$3,
+  error=$4,
+  attempts=attempts+1,
+  checked_at=NOW(),
+  confirmed_at=CASE WHEN $2='CONFIRMED' THEN NOW() ELSE confirmed_at END,
+  updated_at=NOW()
+WHERE id=$1 AND status='PENDING'
+RETURNING *
@@ -0,0 +1,3 @@
+SELECT * FROM currency_rates
+WHERE base=$1
+ORDER BY quote
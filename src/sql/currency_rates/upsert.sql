@@ -0,0 +1,7 @@
+INSERT INTO currency_rates (base, quote, rate, source, fetched_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (base, quote) DO UPDATE SET
+    rate = EXCLUDED.rate,
+    source = EXCLUDED.source,
+    fetched_at = EXCLUDED.fetched_at
+RETURNING *
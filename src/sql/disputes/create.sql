@@ -0,0 +1,3 @@
+INSERT INTO disputes (title, category, claimant_id, respondent_id, contract_id, response_deadline)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *
@@ -0,0 +1,3 @@
+INSERT INTO dispute_events (dispute_id, identity_id, type, message)
+VALUES ($1, $2, $3, $4)
+RETURNING id
@@ -0,0 +1,6 @@
+INSERT INTO dispute_evidences(
+    dispute_id, dispute_event_id, identity_id, media_id
+)
+VALUES (
+    :dispute_id, :dispute_event_id, :identity_id, :media_id
+)
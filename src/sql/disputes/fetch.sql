@@ -0,0 +1,40 @@
+SELECT d.*,
+  row_to_json(cl.*) AS claimant,
+  row_to_json(re.*) AS respondent,
+  COALESCE(
+    (SELECT jsonb_agg(
+      json_build_object(
+        'id', e.id,
+        'type', e.type,
+        'message', e.message,
+        'identity_id', e.identity_id,
+        'created_at', e.created_at,
+        'evidences', COALESCE(
+          (SELECT jsonb_agg(json_build_object('id', m.id, 'url', m.url, 'filename', m.filename))
+          FROM dispute_evidences de
+          JOIN media m ON m.id=de.media_id
+          WHERE de.dispute_event_id=e.id),
+          '[]'
+        )
+      ) ORDER BY e.created_at ASC)
+    FROM dispute_events e
+    WHERE e.dispute_id=d.id),
+    '[]'
+  ) AS events,
+  COALESCE(
+    (SELECT jsonb_agg(
+      json_build_object(
+        'juror_id', j.juror_id,
+        'voted', j.vote_side IS NOT NULL
+      ))
+    FROM dispute_jourors j
+    WHERE j.dispute_id=d.id),
+    '[]'
+  ) AS jurors,
+  (SELECT COUNT(*) FROM dispute_jourors j WHERE j.dispute_id=d.id AND j.vote_side='CLAIMANT') AS claimant_votes,
+  (SELECT COUNT(*) FROM dispute_jourors j WHERE j.dispute_id=d.id AND j.vote_side='RESPONDENT') AS respondent_votes
+FROM disputes d
+JOIN identities cl ON cl.id=d.claimant_id
+JOIN identities re ON re.id=d.respondent_id
+WHERE d.id IN (?)
+ORDER BY d.created_at DESC
@@ -0,0 +1,5 @@
+SELECT d.id, COUNT(*) OVER () as total_count
+FROM disputes d
+WHERE d.contract_id=$1
+ORDER BY d.created_at DESC
+LIMIT $2 OFFSET $3
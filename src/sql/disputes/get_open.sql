@@ -0,0 +1,3 @@
+SELECT d.id FROM disputes d
+WHERE d.contract_id=$1 AND d.state NOT IN ('WITHDRAWN', 'CLOSED')
+LIMIT 1
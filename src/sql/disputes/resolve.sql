@@ -0,0 +1,7 @@
+UPDATE disputes SET
+  state='CLOSED',
+  winner_party=$2,
+  resolved_at=NOW(),
+  updated_at=NOW()
+WHERE id=$1 AND state NOT IN ('WITHDRAWN', 'CLOSED')
+RETURNING *
@@ -0,0 +1,9 @@
+INSERT INTO dispute_jourors (dispute_id, juror_id)
+SELECT $1, u.id
+FROM users u
+WHERE u.identity_verified=true AND (u.is_contributor=true OR u.is_admin=true) AND u.id NOT IN ($2, $3)
+  AND NOT EXISTS (SELECT 1 FROM org_members om WHERE om.user_id=u.id AND om.org_id IN ($2, $3))
+ORDER BY COALESCE(u.is_contributor, false) DESC, random()
+LIMIT $4
+ON CONFLICT (dispute_id, juror_id) DO NOTHING
+RETURNING juror_id
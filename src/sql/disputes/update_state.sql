@@ -0,0 +1,5 @@
+UPDATE disputes SET
+  state=$3,
+  updated_at=NOW()
+WHERE id=$1 AND state=$2
+RETURNING *
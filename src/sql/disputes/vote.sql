@@ -0,0 +1,5 @@
+UPDATE dispute_jourors SET
+  vote_side=$3,
+  updated_at=NOW()
+WHERE dispute_id=$1 AND juror_id=$2 AND vote_side IS NULL
+RETURNING *
@@ -0,0 +1,2 @@
+SELECT e.* FROM escrows e
+WHERE e.id IN (?)
@@ -0,0 +1 @@
+SELECT * FROM escrows WHERE id=$1 FOR UPDATE
@@ -0,0 +1,6 @@
+UPDATE escrows SET
+  refound_at=NOW(),
+  refund_key=$2,
+  refund_id=$3
+WHERE id=$1 AND released_at IS NULL AND refound_at IS NULL
+RETURNING *
@@ -0,0 +1,8 @@
+UPDATE escrows SET
+  released_at=NOW(),
+  release_key=$2,
+  release_id=$3,
+  payout=$4,
+  app_fee=$5
+WHERE id=$1 AND released_at IS NULL AND refound_at IS NULL
+RETURNING *
@@ -0,0 +1,4 @@
+INSERT INTO escrows (contract_id, project_id, payment_id, amount, currency)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (contract_id, payment_id) WHERE contract_id IS NOT NULL DO UPDATE SET id=escrows.id
+RETURNING *
@@ -0,0 +1,21 @@
+INSERT INTO fee_policies (
+  name,
+  version,
+  identity_id,
+  verified,
+  service,
+  currency,
+  crypto_network,
+  org_fee,
+  user_fee,
+  stripe_fee,
+  referred_org_fee_discount,
+  referred_user_fee_discount,
+  effective_from,
+  effective_to
+) VALUES (
+  $1,
+  (SELECT COALESCE(MAX(version), 0) + 1 FROM fee_policies WHERE name=$1),
+  $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE($12, NOW()), $13
+)
+RETURNING *
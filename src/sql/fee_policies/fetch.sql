@@ -0,0 +1,3 @@
+SELECT fp.* FROM fee_policies fp
+WHERE fp.id IN (?)
+ORDER BY fp.name, fp.version DESC
@@ -0,0 +1,14 @@
+SELECT fp.* FROM fee_policies fp
+WHERE fp.effective_from <= NOW()
+  AND (fp.effective_to IS NULL OR fp.effective_to > NOW())
+  AND (fp.identity_id IS NULL OR fp.identity_id=$1)
+  AND (fp.verified IS NULL OR fp.verified=$2)
+  AND (fp.service IS NULL OR fp.service=$3)
+  AND (fp.currency IS NULL OR fp.currency=$4)
+  AND (fp.crypto_network IS NULL OR fp.crypto_network=$5)
+ORDER BY
+  (fp.identity_id IS NOT NULL) DESC,
+  ((fp.verified IS NOT NULL)::int + (fp.service IS NOT NULL)::int + (fp.currency IS NOT NULL)::int + (fp.crypto_network IS NOT NULL)::int) DESC,
+  fp.effective_from DESC,
+  fp.version DESC
+LIMIT 1
@@ -0,0 +1,19 @@
+INSERT INTO invoices (
+  number,
+  sequence,
+  type,
+  issuer_id,
+  recipient_id,
+  contract_id,
+  payment_id,
+  currency,
+  line_items,
+  subtotal,
+  tax_rate,
+  tax_amount,
+  total,
+  issuer_details,
+  recipient_details
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING *
@@ -0,0 +1,2 @@
+SELECT i.* FROM invoices i
+WHERE i.id IN (?)
@@ -0,0 +1,5 @@
+SELECT i.id, COUNT(*) OVER () as total_count
+FROM invoices i
+WHERE i.issuer_id=$1 OR i.recipient_id=$1
+ORDER BY i.issued_at DESC, i.sequence DESC
+LIMIT $2 OFFSET $3
@@ -0,0 +1,2 @@
+SELECT i.id FROM invoices i
+WHERE i.payment_id=$1 AND i.type=$2
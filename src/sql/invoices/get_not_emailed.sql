@@ -0,0 +1,4 @@
+SELECT i.id FROM invoices i
+WHERE i.emailed_at IS NULL
+ORDER BY i.created_at
+LIMIT $1
@@ -0,0 +1,4 @@
+INSERT INTO invoice_sequences (issuer_id, last_number)
+VALUES ($1, 1)
+ON CONFLICT (issuer_id) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+RETURNING last_number
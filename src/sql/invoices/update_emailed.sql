@@ -0,0 +1,3 @@
+UPDATE invoices SET emailed_at=NOW()
+WHERE id=$1
+RETURNING *
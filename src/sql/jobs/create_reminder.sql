@@ -0,0 +1,4 @@
+INSERT INTO expiry_reminders (project_id, contract_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+RETURNING id
@@ -0,0 +1,2 @@
+DELETE FROM expiry_reminders
+WHERE project_id=$1 OR contract_id=$2
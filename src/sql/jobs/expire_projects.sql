@@ -0,0 +1,5 @@
+UPDATE projects SET
+  status='EXPIRE',
+  updated_at=NOW()
+WHERE status='ACTIVE' AND expires_at IS NOT NULL AND expires_at < NOW()
+RETURNING id
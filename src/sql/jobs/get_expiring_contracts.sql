@@ -0,0 +1,15 @@
+SELECT
+  c.id,
+  c.name AS title,
+  c.created_at + make_interval(days => $1) AS expires_at,
+  COALESCE(u.email, o.email) AS email,
+  COALESCE(u.first_name, o.name, u.username) AS name
+FROM contracts c
+JOIN identities i ON i.id=c.client_id
+LEFT JOIN users u ON u.id=i.id
+LEFT JOIN organizations o ON o.id=i.id
+WHERE c.status IN ('CREATED', 'CLIENT_APPROVED')
+  AND c.created_at + make_interval(days => $1) BETWEEN NOW() AND NOW() + make_interval(days => $2)
+  AND NOT EXISTS (SELECT r.id FROM expiry_reminders r WHERE r.contract_id=c.id)
+ORDER BY c.created_at ASC
+LIMIT $3
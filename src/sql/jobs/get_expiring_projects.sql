@@ -0,0 +1,15 @@
+SELECT
+  p.id,
+  p.title,
+  p.expires_at,
+  COALESCE(u.email, o.email) AS email,
+  COALESCE(u.first_name, o.name, u.username) AS name
+FROM projects p
+JOIN identities i ON i.id=p.identity_id
+LEFT JOIN users u ON u.id=i.id
+LEFT JOIN organizations o ON o.id=i.id
+WHERE p.status='ACTIVE'
+  AND p.expires_at BETWEEN NOW() AND NOW() + make_interval(days => $1)
+  AND NOT EXISTS (SELECT r.id FROM expiry_reminders r WHERE r.project_id=p.id)
+ORDER BY p.expires_at ASC
+LIMIT $2
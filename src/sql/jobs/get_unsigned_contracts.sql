@@ -0,0 +1,5 @@
+SELECT c.id, COUNT(*) OVER () as total_count
+FROM contracts c
+WHERE c.status IN ('CREATED', 'CLIENT_APPROVED') AND c.created_at < NOW() - make_interval(days => $1)
+ORDER BY c.created_at ASC
+LIMIT $2
@@ -0,0 +1,13 @@
+CREATE TABLE contract_status_history (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  contract_id UUID NOT NULL,
+  identity_id UUID,
+  old_status contract_status NOT NULL,
+  new_status contract_status NOT NULL,
+  reason TEXT,
+  created_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_identity FOREIGN KEY (identity_id) REFERENCES identities(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_contract_status_history_contract ON contract_status_history (contract_id, created_at);
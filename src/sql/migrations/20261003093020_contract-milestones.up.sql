@@ -0,0 +1,23 @@
+CREATE TYPE contract_milestone_status AS ENUM (
+  'PENDING',
+  'FUNDED',
+  'APPROVED'
+);
+
+CREATE TABLE contract_milestones (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  contract_id UUID NOT NULL,
+  title VARCHAR(128) NOT NULL,
+  description TEXT,
+  amount FLOAT NOT NULL DEFAULT 0,
+  due_date TIMESTAMP,
+  status contract_milestone_status DEFAULT 'PENDING',
+  payment_id UUID,
+  funded_at TIMESTAMP,
+  released_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_contract_milestones_contract ON contract_milestones (contract_id, due_date);
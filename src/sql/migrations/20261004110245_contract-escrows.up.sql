@@ -0,0 +1,15 @@
+-- v3 contracts keep their escrow on gopay payments instead of legacy payments
+ALTER TABLE escrows
+  ALTER COLUMN project_id DROP NOT NULL,
+  DROP CONSTRAINT IF EXISTS fk_payment,
+  ADD COLUMN contract_id UUID,
+  ADD COLUMN payout FLOAT,
+  ADD COLUMN app_fee FLOAT,
+  ADD COLUMN release_key TEXT,
+  ADD COLUMN refund_key TEXT,
+  ADD COLUMN refund_id TEXT,
+  ADD CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX idx_escrows_contract_payment ON escrows (contract_id, payment_id) WHERE contract_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_escrows_release_key ON escrows (release_key);
+CREATE UNIQUE INDEX idx_escrows_refund_key ON escrows (refund_key);
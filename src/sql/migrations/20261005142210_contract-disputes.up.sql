@@ -0,0 +1,10 @@
+ALTER TABLE disputes
+  ALTER COLUMN mission_id DROP NOT NULL,
+  ADD COLUMN contract_id UUID,
+  ADD COLUMN response_deadline TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN resolved_at TIMESTAMP WITH TIME ZONE,
+  ADD CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE;
+
+-- A contract can have only one dispute in progress at a time
+CREATE UNIQUE INDEX idx_disputes_contract_open ON disputes (contract_id)
+  WHERE contract_id IS NOT NULL AND state NOT IN ('WITHDRAWN', 'CLOSED');
@@ -0,0 +1,15 @@
+ALTER TYPE submit_works_status_type ADD VALUE IF NOT EXISTS 'REJECTED';
+
+ALTER TABLE submitted_works
+  ALTER COLUMN project_id DROP NOT NULL,
+  ALTER COLUMN mission_id DROP NOT NULL,
+  ALTER COLUMN total_hours TYPE FLOAT,
+  ADD COLUMN contract_id UUID,
+  ADD COLUMN description TEXT,
+  ADD COLUMN amount FLOAT,
+  ADD COLUMN payout FLOAT,
+  ADD COLUMN app_fee FLOAT,
+  ADD COLUMN reviewed_at TIMESTAMP WITH TIME ZONE,
+  ADD CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_submitted_works_contract ON submitted_works (contract_id, start_at);
@@ -0,0 +1,26 @@
+CREATE TYPE contract_amendment_status AS ENUM (
+  'PENDING',
+  'ACCEPTED',
+  'REJECTED',
+  'WITHDRAWN'
+);
+
+CREATE TABLE contract_amendments (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  contract_id UUID NOT NULL,
+  proposer_id UUID NOT NULL,
+  responder_id UUID,
+  status contract_amendment_status NOT NULL DEFAULT 'PENDING',
+  old_terms JSONB NOT NULL DEFAULT '{}',
+  new_terms JSONB NOT NULL DEFAULT '{}',
+  reason TEXT,
+  responded_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_proposer FOREIGN KEY (proposer_id) REFERENCES identities(id) ON DELETE CASCADE,
+  CONSTRAINT fk_responder FOREIGN KEY (responder_id) REFERENCES identities(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_contract_amendments_contract ON contract_amendments (contract_id, created_at);
+CREATE UNIQUE INDEX idx_contract_amendments_pending ON contract_amendments (contract_id) WHERE status='PENDING';
@@ -0,0 +1,14 @@
+CREATE TABLE contract_signatures (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  contract_id UUID NOT NULL,
+  identity_id UUID NOT NULL,
+  document TEXT NOT NULL,
+  hash VARCHAR(64) NOT NULL,
+  ip_address VARCHAR(64),
+  user_agent TEXT,
+  created_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_identity FOREIGN KEY (identity_id) REFERENCES identities(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_contract_signatures_contract ON contract_signatures (contract_id, created_at);
@@ -0,0 +1,22 @@
+CREATE TABLE contract_templates (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  identity_id UUID NOT NULL,
+  title VARCHAR(128) NOT NULL,
+  name VARCHAR(128),
+  description TEXT,
+  type contract_type NOT NULL,
+  total_amount FLOAT,
+  currency payment_currency,
+  crypto_currency TEXT,
+  crypto_network network_type,
+  commitment integer,
+  commitment_period contract_commitment_period NOT NULL,
+  commitment_period_count integer,
+  payment_type payment_mode_type,
+  requirement_description TEXT,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_identity FOREIGN KEY (identity_id) REFERENCES identities(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_contract_templates_identity ON contract_templates (identity_id, created_at);
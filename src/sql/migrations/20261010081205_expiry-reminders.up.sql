@@ -0,0 +1,12 @@
+CREATE TABLE expiry_reminders (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  project_id UUID,
+  contract_id UUID,
+  created_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_project FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+  CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_expiry_reminders_project ON expiry_reminders (project_id) WHERE project_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_expiry_reminders_contract ON expiry_reminders (contract_id) WHERE contract_id IS NOT NULL;
+CREATE INDEX idx_projects_expires_at ON projects (expires_at) WHERE status='ACTIVE';
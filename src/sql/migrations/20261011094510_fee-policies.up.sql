@@ -0,0 +1,31 @@
+CREATE TABLE fee_policies (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  name VARCHAR(128) NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  identity_id UUID,
+  verified BOOLEAN,
+  service payment_service,
+  currency payment_currency,
+  crypto_network network_type,
+  org_fee FLOAT NOT NULL,
+  user_fee FLOAT NOT NULL,
+  stripe_fee FLOAT NOT NULL DEFAULT 0,
+  referred_org_fee_discount FLOAT NOT NULL DEFAULT 1,
+  referred_user_fee_discount FLOAT NOT NULL DEFAULT 1,
+  effective_from TIMESTAMP NOT NULL DEFAULT NOW(),
+  effective_to TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_identity FOREIGN KEY (identity_id) REFERENCES identities(id) ON DELETE CASCADE,
+  CONSTRAINT uq_fee_policy_version UNIQUE (name, version)
+);
+
+CREATE INDEX idx_fee_policies_effective ON fee_policies (effective_from, effective_to);
+
+-- Same rates the platform used before policies were stored
+INSERT INTO fee_policies (name, version, verified, org_fee, user_fee, stripe_fee, referred_org_fee_discount, referred_user_fee_discount, effective_from)
+VALUES
+  ('default', 1, NULL, 0.03, 0.1, 0.036, 0.5, 0.5, '2020-01-01'),
+  ('verified-impact', 1, true, 0.02, 0.05, 0.036, 0.5, 0.5, '2020-01-01');
+
+ALTER TABLE contracts ADD COLUMN fee_policy_id UUID;
+ALTER TABLE contracts ADD CONSTRAINT fk_fee_policy FOREIGN KEY (fee_policy_id) REFERENCES fee_policies(id) ON DELETE SET NULL;
@@ -0,0 +1,35 @@
+CREATE TYPE referral_reward_status AS ENUM ('PENDING', 'PAID_OUT');
+
+CREATE TABLE referral_payouts (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  referrer_id UUID NOT NULL,
+  wallet_id UUID NOT NULL,
+  address VARCHAR(255) NOT NULL,
+  network network_type NOT NULL,
+  currency VARCHAR(16) NOT NULL,
+  amount FLOAT NOT NULL,
+  created_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_referrer FOREIGN KEY (referrer_id) REFERENCES identities(id) ON DELETE CASCADE,
+  CONSTRAINT fk_wallet FOREIGN KEY (wallet_id) REFERENCES wallets(id) ON DELETE RESTRICT
+);
+
+CREATE TABLE referral_rewards (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  referrer_id UUID NOT NULL,
+  referred_identity_id UUID NOT NULL,
+  contract_id UUID NOT NULL,
+  amount FLOAT NOT NULL,
+  currency VARCHAR(16) NOT NULL,
+  crypto_network network_type,
+  status referral_reward_status NOT NULL DEFAULT 'PENDING',
+  payout_id UUID,
+  paid_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_referrer FOREIGN KEY (referrer_id) REFERENCES identities(id) ON DELETE CASCADE,
+  CONSTRAINT fk_referred_identity FOREIGN KEY (referred_identity_id) REFERENCES identities(id) ON DELETE CASCADE,
+  CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_payout FOREIGN KEY (payout_id) REFERENCES referral_payouts(id) ON DELETE SET NULL,
+  CONSTRAINT uq_referral_reward UNIQUE (contract_id, referred_identity_id)
+);
+
+CREATE INDEX idx_referral_rewards_referrer ON referral_rewards (referrer_id, status);
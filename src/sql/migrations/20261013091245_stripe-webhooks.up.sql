@@ -0,0 +1,13 @@
+ALTER TYPE webhook_party_type ADD VALUE IF NOT EXISTS 'STRIPE';
+
+ALTER TABLE webhooks
+  ADD COLUMN event_id VARCHAR(255),
+  ADD COLUMN event_type VARCHAR(128),
+  ADD COLUMN fiat_service_name VARCHAR(64),
+  ADD COLUMN processed_at TIMESTAMP;
+
+CREATE UNIQUE INDEX idx_webhooks_event ON webhooks (party, event_id) WHERE event_id IS NOT NULL;
+
+ALTER TABLE contracts
+  ADD COLUMN chargeback_id VARCHAR(255),
+  ADD COLUMN chargeback_at TIMESTAMP;
@@ -0,0 +1,28 @@
+CREATE TYPE crypto_deposit_status AS ENUM ('PENDING', 'CONFIRMED', 'FAILED');
+
+CREATE TABLE crypto_deposits (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  contract_id UUID NOT NULL,
+  payment_id UUID NOT NULL,
+  identity_id UUID NOT NULL,
+  tx_id VARCHAR(255) NOT NULL,
+  token_address VARCHAR(255) NOT NULL,
+  recipient VARCHAR(255) NOT NULL,
+  expected_amount FLOAT NOT NULL,
+  status crypto_deposit_status NOT NULL DEFAULT 'PENDING',
+  confirmations INT NOT NULL DEFAULT 0,
+  required_confirmations INT NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  error TEXT,
+  meta JSONB,
+  checked_at TIMESTAMP,
+  confirmed_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_identity FOREIGN KEY (identity_id) REFERENCES identities(id) ON DELETE CASCADE
+);
+
+-- A transaction can fund one deposit only
+CREATE UNIQUE INDEX idx_crypto_deposits_tx ON crypto_deposits (LOWER(tx_id));
+CREATE INDEX idx_crypto_deposits_pending ON crypto_deposits (checked_at) WHERE status='PENDING';
@@ -0,0 +1,16 @@
+ALTER TABLE wallets ADD COLUMN verified_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE wallet_challenges (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  user_id UUID NOT NULL,
+  address TEXT NOT NULL,
+  network network_type NOT NULL,
+  testnet BOOLEAN NOT NULL DEFAULT false,
+  message TEXT NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_wallet_challenges_user ON wallet_challenges (user_id);
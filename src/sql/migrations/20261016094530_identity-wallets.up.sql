@@ -0,0 +1,23 @@
+ALTER TABLE wallets
+  ADD COLUMN identity_id UUID,
+  ADD COLUMN is_primary BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE wallets SET identity_id=user_id;
+
+ALTER TABLE wallets
+  ALTER COLUMN identity_id SET NOT NULL,
+  ADD CONSTRAINT fk_identity FOREIGN KEY (identity_id) REFERENCES identities(id) ON DELETE CASCADE;
+
+-- Mainnet wallets become primary on their network, testnet ones when there is no mainnet wallet
+UPDATE wallets w SET is_primary=true
+WHERE w.testnet=false OR NOT EXISTS (
+  SELECT 1 FROM wallets o WHERE o.identity_id=w.identity_id AND o.network=w.network AND o.testnet=false
+);
+
+DROP INDEX idx_wallets_user_id_network;
+CREATE UNIQUE INDEX idx_wallets_identity_network ON wallets (identity_id, network, testnet);
+CREATE UNIQUE INDEX idx_wallets_primary ON wallets (identity_id, network) WHERE is_primary;
+
+ALTER TABLE wallet_challenges ADD COLUMN identity_id UUID;
+UPDATE wallet_challenges SET identity_id=user_id;
+ALTER TABLE wallet_challenges ALTER COLUMN identity_id SET NOT NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE currency_rates (
+  base VARCHAR(16) NOT NULL,
+  quote VARCHAR(16) NOT NULL,
+  rate FLOAT NOT NULL,
+  source VARCHAR(32) NOT NULL,
+  fetched_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (base, quote)
+);
+
+ALTER TABLE contracts ADD COLUMN currency_rate_locked_at TIMESTAMP;
@@ -0,0 +1,39 @@
+CREATE TYPE invoice_type AS ENUM ('DEPOSIT', 'RELEASE');
+
+-- Last number issued per issuer, invoices are numbered without gaps
+CREATE TABLE invoice_sequences (
+  issuer_id UUID NOT NULL PRIMARY KEY,
+  last_number INT NOT NULL DEFAULT 0,
+  CONSTRAINT fk_issuer FOREIGN KEY (issuer_id) REFERENCES identities(id) ON DELETE CASCADE
+);
+
+CREATE TABLE invoices (
+  id UUID NOT NULL DEFAULT public.uuid_generate_v4() PRIMARY KEY,
+  number VARCHAR(32) NOT NULL,
+  sequence INT NOT NULL,
+  type invoice_type NOT NULL,
+  issuer_id UUID NOT NULL,
+  recipient_id UUID NOT NULL,
+  contract_id UUID NOT NULL,
+  payment_id UUID NOT NULL,
+  currency VARCHAR(16) NOT NULL,
+  line_items JSONB NOT NULL DEFAULT '[]',
+  subtotal FLOAT NOT NULL,
+  tax_rate FLOAT NOT NULL DEFAULT 0,
+  tax_amount FLOAT NOT NULL DEFAULT 0,
+  total FLOAT NOT NULL,
+  issuer_details JSONB NOT NULL DEFAULT '{}',
+  recipient_details JSONB NOT NULL DEFAULT '{}',
+  emailed_at TIMESTAMP,
+  issued_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT fk_issuer FOREIGN KEY (issuer_id) REFERENCES identities(id) ON DELETE CASCADE,
+  CONSTRAINT fk_recipient FOREIGN KEY (recipient_id) REFERENCES identities(id) ON DELETE CASCADE,
+  CONSTRAINT fk_contract FOREIGN KEY (contract_id) REFERENCES contracts(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_invoices_issuer_sequence ON invoices (issuer_id, sequence);
+-- A payment is invoiced once on deposit and once on release
+CREATE UNIQUE INDEX idx_invoices_payment_type ON invoices (payment_id, type);
+CREATE INDEX idx_invoices_recipient ON invoices (recipient_id);
+CREATE INDEX idx_invoices_not_emailed ON invoices (created_at) WHERE emailed_at IS NULL;
@@ -0,0 +1,13 @@
+ALTER TABLE cards ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT false;
+
+-- The latest card of the identity is the one its deposits have been charged on
+UPDATE cards c SET is_default=true
+WHERE c.id=(
+  SELECT o.id FROM cards o
+  WHERE o.identity_id=c.identity_id
+  ORDER BY o.created_at DESC
+  LIMIT 1
+);
+
+CREATE INDEX idx_cards_identity_id ON cards (identity_id);
+CREATE UNIQUE INDEX idx_cards_default ON cards (identity_id) WHERE is_default;
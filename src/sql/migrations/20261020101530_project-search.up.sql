@@ -0,0 +1,20 @@
+-- Great-circle distance in kilometers between geonames (latitude, longitude) points
+CREATE OR REPLACE FUNCTION geo_distance_km(a point, b point) RETURNS double precision
+LANGUAGE sql IMMUTABLE AS $$
+  SELECT 6371 * 2 * asin(sqrt(LEAST(1,
+    power(sin(radians(b[0] - a[0]) / 2), 2) +
+    cos(radians(a[0])) * cos(radians(b[0])) * power(sin(radians(b[1] - a[1]) / 2), 2)
+  )))
+$$;
+
+-- Payment ranges are free text, only plain amounts are compared
+CREATE OR REPLACE FUNCTION payment_amount(amount text) RETURNS numeric
+LANGUAGE sql IMMUTABLE AS $$
+  SELECT CASE WHEN replace(btrim(amount), ',', '') ~ '^[0-9]+(\.[0-9]+)?$'
+    THEN replace(btrim(amount), ',', '')::numeric
+  END
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_projects_geoname_id ON projects (geoname_id);
+CREATE INDEX IF NOT EXISTS idx_projects_skills ON projects USING gin (skills);
+CREATE INDEX IF NOT EXISTS idx_projects_causes_tags ON projects USING gin (causes_tags);
@@ -0,0 +1,13 @@
+ALTER TYPE applicants_status_type ADD VALUE IF NOT EXISTS 'SHORTLISTED' AFTER 'PENDING';
+
+CREATE TABLE applicant_attachments (
+  applicant_id UUID NOT NULL,
+  media_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (applicant_id, media_id),
+  CONSTRAINT fk_applicant FOREIGN KEY (applicant_id) REFERENCES applicants(id) ON DELETE CASCADE,
+  CONSTRAINT fk_media FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_applicants_project_id ON applicants (project_id);
+CREATE INDEX IF NOT EXISTS idx_answers_applicant_id ON answers (applicant_id);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_questions_project_id ON questions (project_id);
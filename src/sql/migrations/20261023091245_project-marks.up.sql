@@ -0,0 +1,4 @@
+-- Marks are upserted, changing a saved project to not interested deactivates its recommendation too
+DROP TRIGGER IF EXISTS not_interested ON project_marks;
+CREATE TRIGGER not_interested AFTER INSERT OR UPDATE OF marked_as ON project_marks
+FOR EACH ROW EXECUTE FUNCTION not_interest();
@@ -0,0 +1,3 @@
+-- Payouts record the transfer to schedule to the referrer wallet, the rewards are not paid out by then
+ALTER TYPE referral_reward_status RENAME VALUE 'PAID_OUT' TO 'SCHEDULED';
+ALTER TABLE referral_rewards RENAME COLUMN paid_at TO scheduled_at;
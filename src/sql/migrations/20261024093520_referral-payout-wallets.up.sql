@@ -0,0 +1,5 @@
+-- Payouts keep the address and network they were made to, removing the wallet doesn't drop them
+ALTER TABLE referral_payouts
+  ALTER COLUMN wallet_id DROP NOT NULL,
+  DROP CONSTRAINT fk_wallet,
+  ADD CONSTRAINT fk_wallet FOREIGN KEY (wallet_id) REFERENCES wallets(id) ON DELETE SET NULL;
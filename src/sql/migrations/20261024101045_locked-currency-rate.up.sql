@@ -0,0 +1,4 @@
+-- The locked rate is kept apart from the offered one, the offered rate is part of the signed terms
+ALTER TABLE contracts ADD COLUMN locked_currency_rate FLOAT;
+
+UPDATE contracts SET locked_currency_rate=currency_rate WHERE currency_rate_locked_at IS NOT NULL;
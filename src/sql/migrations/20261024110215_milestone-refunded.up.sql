@@ -0,0 +1 @@
+ALTER TYPE contract_milestone_status ADD VALUE IF NOT EXISTS 'REFUNDED';
@@ -0,0 +1,10 @@
+-- Rewards are credited per released payment, milestones of a contract are released with payments of their own
+ALTER TABLE referral_rewards ADD COLUMN payment_id UUID;
+
+UPDATE referral_rewards rr SET payment_id=c.payment_id
+FROM contracts c
+WHERE c.id=rr.contract_id;
+
+ALTER TABLE referral_rewards
+  DROP CONSTRAINT uq_referral_reward,
+  ADD CONSTRAINT uq_referral_reward UNIQUE (payment_id, referred_identity_id);
@@ -0,0 +1,17 @@
+-- Scheduled payouts are paid out by a Stripe transfer for fiat rewards, crypto ones once the transfer
+-- from the platform wallet is confirmed on chain
+ALTER TYPE referral_reward_status ADD VALUE IF NOT EXISTS 'PAID_OUT';
+
+CREATE TYPE referral_payout_status AS ENUM ('SCHEDULED', 'PAID_OUT');
+
+ALTER TABLE referral_payouts
+  ALTER COLUMN network DROP NOT NULL,
+  ADD COLUMN fiat_service VARCHAR(32),
+  ADD COLUMN status referral_payout_status NOT NULL DEFAULT 'SCHEDULED',
+  ADD COLUMN tx_id VARCHAR(255),
+  ADD COLUMN paid_at TIMESTAMP;
+
+CREATE UNIQUE INDEX idx_referral_payouts_tx ON referral_payouts (tx_id) WHERE tx_id IS NOT NULL;
+CREATE INDEX idx_referral_payouts_status ON referral_payouts (status, created_at);
+
+ALTER TABLE referral_rewards ADD COLUMN paid_at TIMESTAMP;
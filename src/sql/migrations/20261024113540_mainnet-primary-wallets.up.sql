@@ -0,0 +1,7 @@
+-- Contracts pay out to the primary wallet, testnet wallets can't be primary
+UPDATE wallets SET is_primary=false WHERE testnet AND is_primary;
+
+UPDATE wallets w SET is_primary=true
+WHERE w.testnet=false AND NOT EXISTS (
+  SELECT 1 FROM wallets o WHERE o.identity_id=w.identity_id AND o.network=w.network AND o.is_primary
+);
@@ -0,0 +1 @@
+DELETE FROM project_marks WHERE identity_id=$1 AND project_id=$2
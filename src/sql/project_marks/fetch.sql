@@ -0,0 +1 @@
+SELECT * FROM project_marks WHERE id IN (?)
@@ -0,0 +1,4 @@
+INSERT INTO project_marks (identity_id, project_id, marked_as)
+VALUES ($1, $2, $3)
+ON CONFLICT (identity_id, project_id) DO UPDATE SET marked_as=EXCLUDED.marked_as, created_at=NOW()
+RETURNING *
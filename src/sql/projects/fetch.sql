@@ -17,7 +17,13 @@ row_to_json(i.*) AS identity,
 		),
 		'[]'
 	)
-) AS work_samples
+) AS work_samples,
+COALESCE(
+	(SELECT jsonb_agg(row_to_json(q.*) ORDER BY q.created_at)
+	FROM questions q
+	WHERE q.project_id=p.id),
+	'[]'
+) AS questions
 FROM projects p
 JOIN identities i ON i.id=p.identity_id
 LEFT JOIN job_categories jc ON jc.id=p.job_category_id
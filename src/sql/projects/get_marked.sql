@@ -0,0 +1,7 @@
+SELECT p.id, COUNT(*) OVER () as total_count
+FROM project_marks pm
+JOIN projects p ON p.id=pm.project_id
+WHERE pm.identity_id=$1 AND p.deleted_at IS NULL
+  AND ($4::text='' OR pm.marked_as::text=$4::text)
+ORDER BY pm.created_at DESC
+LIMIT $2 OFFSET $3
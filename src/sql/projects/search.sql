@@ -0,0 +1,34 @@
+WITH center AS (
+  SELECT latlong FROM geonames WHERE id=$11
+), matched AS (
+  SELECT p.*,
+    CASE WHEN $1::text='' THEN 0 ELSE ts_rank(p.search_tsv, websearch_to_tsquery($1::text)) END AS rank
+  FROM projects p
+  LEFT JOIN geonames g ON g.id=p.geoname_id
+  WHERE p.status='ACTIVE' AND p.deleted_at IS NULL AND (p.expires_at IS NULL OR p.expires_at > NOW())
+    AND ($1::text='' OR p.search_tsv @@ websearch_to_tsquery($1::text))
+    AND (COALESCE(cardinality($2::text[]), 0)=0 OR p.skills && $2::text[])
+    AND (COALESCE(cardinality($3::text[]), 0)=0 OR p.causes_tags::text[] && $3::text[])
+    AND (COALESCE(cardinality($4::text[]), 0)=0 OR p.kind::text=ANY($4::text[]))
+    AND (COALESCE(cardinality($5::text[]), 0)=0 OR p.remote_preference::text=ANY($5::text[]))
+    AND (COALESCE(cardinality($6::text[]), 0)=0 OR p.project_type::text=ANY($6::text[]))
+    AND (COALESCE(cardinality($7::text[]), 0)=0 OR p.payment_type::text=ANY($7::text[]))
+    AND (COALESCE(cardinality($8::int[]), 0)=0 OR p.experience_level=ANY($8::int[]))
+    AND ($9::text='' OR p.country ILIKE $9::text)
+    AND ($10::text='' OR p.city ILIKE $10::text)
+    AND ($11::int IS NULL OR p.geoname_id=$11::int
+      OR geo_distance_km(g.latlong, (SELECT latlong FROM center)) <= $12::float)
+    AND ($13::numeric IS NULL
+      OR COALESCE(payment_amount(p.payment_range_higher), payment_amount(p.payment_range_lower)) >= $13::numeric)
+    AND ($14::numeric IS NULL
+      OR COALESCE(payment_amount(p.payment_range_lower), payment_amount(p.payment_range_higher)) <= $14::numeric)
+    AND ($15::text='' OR p.payment_currency ILIKE $15::text)
+    AND ($16::uuid IS NULL OR NOT EXISTS (
+      SELECT 1 FROM project_marks pm
+      WHERE pm.project_id=p.id AND pm.identity_id=$16::uuid AND pm.marked_as='NOT_INTERESTED'
+    ))
+)
+SELECT id, COUNT(*) OVER () as total_count
+FROM matched
+ORDER BY rank DESC, promoted DESC NULLS LAST, created_at DESC
+LIMIT $17 OFFSET $18
@@ -0,0 +1,52 @@
+WITH center AS (
+  SELECT latlong FROM geonames WHERE id=$11
+), matched AS (
+  SELECT p.*,
+    CASE WHEN $1::text='' THEN 0 ELSE ts_rank(p.search_tsv, websearch_to_tsquery($1::text)) END AS rank
+  FROM projects p
+  LEFT JOIN geonames g ON g.id=p.geoname_id
+  WHERE p.status='ACTIVE' AND p.deleted_at IS NULL AND (p.expires_at IS NULL OR p.expires_at > NOW())
+    AND ($1::text='' OR p.search_tsv @@ websearch_to_tsquery($1::text))
+    AND (COALESCE(cardinality($2::text[]), 0)=0 OR p.skills && $2::text[])
+    AND (COALESCE(cardinality($3::text[]), 0)=0 OR p.causes_tags::text[] && $3::text[])
+    AND (COALESCE(cardinality($4::text[]), 0)=0 OR p.kind::text=ANY($4::text[]))
+    AND (COALESCE(cardinality($5::text[]), 0)=0 OR p.remote_preference::text=ANY($5::text[]))
+    AND (COALESCE(cardinality($6::text[]), 0)=0 OR p.project_type::text=ANY($6::text[]))
+    AND (COALESCE(cardinality($7::text[]), 0)=0 OR p.payment_type::text=ANY($7::text[]))
+    AND (COALESCE(cardinality($8::int[]), 0)=0 OR p.experience_level=ANY($8::int[]))
+    AND ($9::text='' OR p.country ILIKE $9::text)
+    AND ($10::text='' OR p.city ILIKE $10::text)
+    AND ($11::int IS NULL OR p.geoname_id=$11::int
+      OR geo_distance_km(g.latlong, (SELECT latlong FROM center)) <= $12::float)
+    AND ($13::numeric IS NULL
+      OR COALESCE(payment_amount(p.payment_range_higher), payment_amount(p.payment_range_lower)) >= $13::numeric)
+    AND ($14::numeric IS NULL
+      OR COALESCE(payment_amount(p.payment_range_lower), payment_amount(p.payment_range_higher)) <= $14::numeric)
+    AND ($15::text='' OR p.payment_currency ILIKE $15::text)
+    AND ($16::uuid IS NULL OR NOT EXISTS (
+      SELECT 1 FROM project_marks pm
+      WHERE pm.project_id=p.id AND pm.identity_id=$16::uuid AND pm.marked_as='NOT_INTERESTED'
+    ))
+)
+SELECT 'skills' AS facet, s AS value, COUNT(*) AS count
+FROM matched, unnest(skills) s GROUP BY s
+UNION ALL
+SELECT 'causes_tags', c::text, COUNT(*) FROM matched, unnest(causes_tags) c GROUP BY c
+UNION ALL
+SELECT 'kind', kind::text, COUNT(*) FROM matched GROUP BY kind
+UNION ALL
+SELECT 'remote_preference', remote_preference::text, COUNT(*) FROM matched
+WHERE remote_preference IS NOT NULL GROUP BY remote_preference
+UNION ALL
+SELECT 'project_type', project_type::text, COUNT(*) FROM matched
+WHERE project_type IS NOT NULL GROUP BY project_type
+UNION ALL
+SELECT 'payment_type', payment_type::text, COUNT(*) FROM matched
+WHERE payment_type IS NOT NULL GROUP BY payment_type
+UNION ALL
+SELECT 'experience_level', experience_level::text, COUNT(*) FROM matched
+WHERE experience_level IS NOT NULL GROUP BY experience_level
+UNION ALL
+SELECT 'country', country, COUNT(*) FROM matched
+WHERE country IS NOT NULL GROUP BY country
+ORDER BY facet, count DESC, value
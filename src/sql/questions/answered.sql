@@ -0,0 +1 @@
+SELECT EXISTS (SELECT 1 FROM answers WHERE question_id=$1)
@@ -0,0 +1,3 @@
+INSERT INTO questions (project_id, question, required, options)
+VALUES ($1, $2, $3, $4)
+RETURNING *
@@ -0,0 +1 @@
+SELECT * FROM questions WHERE id IN (?)
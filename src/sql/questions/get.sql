@@ -0,0 +1,3 @@
+SELECT * FROM questions
+WHERE project_id=$1
+ORDER BY created_at
@@ -0,0 +1,7 @@
+UPDATE questions SET
+  question=$2,
+  required=$3,
+  options=$4,
+  updated_at=NOW()
+WHERE id=$1
+RETURNING *
@@ -0,0 +1,3 @@
+INSERT INTO referral_payouts (referrer_id, wallet_id, address, network, currency, fiat_service, amount)
+VALUES ($1, $2, $3, $4, $5, $6, 0)
+RETURNING *
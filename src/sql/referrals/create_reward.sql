@@ -0,0 +1,4 @@
+INSERT INTO referral_rewards (referrer_id, referred_identity_id, contract_id, payment_id, amount, currency, crypto_network)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (payment_id, referred_identity_id) DO NOTHING
+RETURNING *
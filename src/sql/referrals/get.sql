@@ -0,0 +1,23 @@
+SELECT
+  r.referred_identity_id,
+  r.created_at,
+  row_to_json(i.*) AS identity,
+  COALESCE(
+    (SELECT jsonb_agg(e.*)
+    FROM (
+      SELECT rr.currency,
+        COALESCE(SUM(rr.amount) FILTER (WHERE rr.status='PENDING'), 0) AS pending,
+        COALESCE(SUM(rr.amount) FILTER (WHERE rr.status='SCHEDULED'), 0) AS scheduled,
+        COALESCE(SUM(rr.amount) FILTER (WHERE rr.status='PAID_OUT'), 0) AS paid_out
+      FROM referral_rewards rr
+      WHERE rr.referrer_id=r.referred_by_id AND rr.referred_identity_id=r.referred_identity_id
+      GROUP BY rr.currency
+    ) e),
+    '[]'
+  ) AS earnings,
+  COUNT(*) OVER () as total_count
+FROM referrings r
+JOIN identities i ON i.id=r.referred_identity_id
+WHERE r.referred_by_id=$1
+ORDER BY r.created_at DESC
+LIMIT $2 OFFSET $3
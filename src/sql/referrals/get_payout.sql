@@ -0,0 +1 @@
+SELECT * FROM referral_payouts WHERE id=$1
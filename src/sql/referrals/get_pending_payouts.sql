@@ -0,0 +1,41 @@
+SELECT
+  rr.referrer_id,
+  rr.currency,
+  rr.crypto_network,
+  w.id AS wallet_id,
+  w.address,
+  w.network,
+  NULL AS fiat_service,
+  SUM(rr.amount) AS amount
+FROM referral_rewards rr
+JOIN LATERAL (
+  SELECT * FROM wallets w
+  WHERE w.identity_id=rr.referrer_id AND w.testnet=false
+    AND w.network=rr.crypto_network
+    AND w.verified_at IS NOT NULL
+  ORDER BY w.is_primary DESC, w.updated_at DESC
+  LIMIT 1
+) w ON true
+WHERE rr.status='PENDING' AND rr.crypto_network IS NOT NULL
+GROUP BY rr.referrer_id, rr.currency, rr.crypto_network, w.id, w.address, w.network
+UNION ALL
+SELECT
+  rr.referrer_id,
+  rr.currency,
+  rr.crypto_network,
+  NULL AS wallet_id,
+  oc.matrix_unique_id AS address,
+  NULL AS network,
+  oc.provider::text AS fiat_service,
+  SUM(rr.amount) AS amount
+FROM referral_rewards rr
+JOIN LATERAL (
+  SELECT * FROM oauth_connects oc
+  WHERE oc.identity_id=rr.referrer_id AND oc.provider IN ('STRIPE', 'STRIPE_JP')
+    AND oc.status='ACTIVE'
+  ORDER BY oc.updated_at DESC
+  LIMIT 1
+) oc ON true
+WHERE rr.status='PENDING' AND rr.crypto_network IS NULL
+GROUP BY rr.referrer_id, rr.currency, rr.crypto_network, oc.matrix_unique_id, oc.provider
+LIMIT $1
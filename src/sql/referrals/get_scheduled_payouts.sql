@@ -0,0 +1,4 @@
+SELECT * FROM referral_payouts
+WHERE status='SCHEDULED'
+ORDER BY created_at
+LIMIT $1
@@ -0,0 +1,6 @@
+UPDATE referral_payouts SET
+  status='PAID_OUT',
+  tx_id=$2,
+  paid_at=NOW()
+WHERE id=$1 AND status='SCHEDULED'
+RETURNING *
@@ -0,0 +1,5 @@
+UPDATE referral_rewards SET
+  status='PAID_OUT',
+  paid_at=NOW()
+WHERE payout_id=$1 AND status='SCHEDULED'
+RETURNING id
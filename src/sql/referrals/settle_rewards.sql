@@ -0,0 +1,10 @@
+WITH rewards AS (
+  UPDATE referral_rewards
+  SET status='SCHEDULED', payout_id=$1, scheduled_at=NOW()
+  WHERE referrer_id=$2 AND currency=$3 AND crypto_network IS NOT DISTINCT FROM $4 AND status='PENDING'
+  RETURNING amount
+)
+UPDATE referral_payouts
+SET amount=(SELECT COALESCE(SUM(amount), 0) FROM rewards)
+WHERE id=$1
+RETURNING *
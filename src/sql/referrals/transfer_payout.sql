@@ -0,0 +1,3 @@
+UPDATE referral_payouts SET tx_id=$2
+WHERE id=$1 AND status='SCHEDULED' AND fiat_service IS NULL
+RETURNING *
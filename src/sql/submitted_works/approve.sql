@@ -0,0 +1,12 @@
+UPDATE submitted_works SET
+  status='CONFIRMED',
+  amount=$2,
+  payout=$3,
+  app_fee=$4,
+  reviewed_at=NOW()
+WHERE id=$1 AND status='PENDING' AND
+  $2 + (
+    SELECT COALESCE(SUM(sw.amount), 0) FROM submitted_works sw
+    WHERE sw.contract_id=submitted_works.contract_id AND sw.status='CONFIRMED'
+  ) <= (SELECT c.total_amount FROM contracts c WHERE c.id=submitted_works.contract_id)
+RETURNING *
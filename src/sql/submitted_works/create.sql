@@ -0,0 +1,3 @@
+INSERT INTO submitted_works (contract_id, project_id, description, start_at, end_at, total_hours)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *
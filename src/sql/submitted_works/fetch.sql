@@ -0,0 +1,3 @@
+SELECT sw.* FROM submitted_works sw
+WHERE sw.id IN (?)
+ORDER BY sw.start_at DESC
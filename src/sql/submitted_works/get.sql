@@ -0,0 +1,5 @@
+SELECT sw.id, COUNT(*) OVER () as total_count
+FROM submitted_works sw
+WHERE sw.contract_id=$1
+ORDER BY sw.start_at DESC
+LIMIT $2 OFFSET $3
@@ -0,0 +1,3 @@
+SELECT sw.id FROM submitted_works sw
+WHERE sw.contract_id=$1 AND sw.status!='REJECTED' AND sw.start_at < $3 AND sw.end_at > $2
+LIMIT 1
@@ -0,0 +1,5 @@
+UPDATE submitted_works SET
+  status='REJECTED',
+  reviewed_at=NOW()
+WHERE id=$1 AND status='PENDING'
+RETURNING *
@@ -6,7 +6,7 @@ SELECT
         (
             SELECT json_agg(w)
             FROM wallets w
-            WHERE w.user_id = u.id
+            WHERE w.identity_id = u.id
         ),
         '[]'::json
     ) AS wallets,
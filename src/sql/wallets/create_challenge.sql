@@ -0,0 +1,3 @@
+INSERT INTO wallet_challenges (user_id, identity_id, address, network, testnet, message, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *
@@ -0,0 +1 @@
+DELETE FROM wallets WHERE id=$1
@@ -0,0 +1,3 @@
+SELECT * FROM wallets
+WHERE identity_id=$1
+ORDER BY network, is_primary DESC, testnet
@@ -0,0 +1 @@
+SELECT * FROM wallets WHERE id=$1 AND identity_id=$2
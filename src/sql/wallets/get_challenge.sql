@@ -0,0 +1 @@
+SELECT * FROM wallet_challenges WHERE id=$1 AND user_id=$2
@@ -0,0 +1,7 @@
+UPDATE wallets SET is_primary=true, updated_at=NOW()
+WHERE id=(
+  SELECT id FROM wallets
+  WHERE identity_id=$1 AND network=$2 AND testnet=false
+  LIMIT 1
+)
+RETURNING *
@@ -0,0 +1,3 @@
+UPDATE wallets SET is_primary=true, updated_at=NOW()
+WHERE id=$1
+RETURNING *
@@ -0,0 +1,2 @@
+UPDATE wallets SET is_primary=false, updated_at=NOW()
+WHERE identity_id=$1 AND network=$2 AND is_primary
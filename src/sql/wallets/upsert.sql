@@ -1,4 +1,9 @@
-INSERT INTO wallets (id, user_id, address, network, testnet) VALUES ($1, $2, $3, $4, $5)
-ON CONFLICT (user_id, network, testnet) DO UPDATE SET
+INSERT INTO wallets (id, user_id, identity_id, address, network, testnet, verified_at, is_primary)
+VALUES ($1, $2, $3, $4, $5, $6, $7,
+  NOT $6 AND NOT EXISTS (SELECT 1 FROM wallets WHERE identity_id=$3 AND network=$5 AND is_primary))
+ON CONFLICT (identity_id, network, testnet) DO UPDATE SET
+    user_id = EXCLUDED.user_id,
     address = EXCLUDED.address,
-    updated_at = NOW();
+    verified_at = EXCLUDED.verified_at,
+    updated_at = NOW()
+RETURNING *
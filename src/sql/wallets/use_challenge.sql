@@ -0,0 +1,3 @@
+UPDATE wallet_challenges SET used_at=NOW()
+WHERE id=$1 AND used_at IS NULL AND expires_at > NOW()
+RETURNING *
@@ -0,0 +1,4 @@
+INSERT INTO webhooks (party, event_id, event_type, fiat_service_name, content)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (party, event_id) WHERE event_id IS NOT NULL DO NOTHING
+RETURNING *
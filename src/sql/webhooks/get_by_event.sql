@@ -0,0 +1 @@
+SELECT * FROM webhooks WHERE party=$1 AND event_id=$2
@@ -0,0 +1,7 @@
+UPDATE webhooks
+SET response=$2,
+  response_status_code=$3,
+  response_at=NOW(),
+  processed_at=CASE WHEN $3 < 400 THEN NOW() ELSE NULL END
+WHERE id=$1
+RETURNING *
@@ -0,0 +1,176 @@
+package tests_test
+
+import (
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type amountsCase struct {
+	Amount       float64
+	Round        float64
+	Verified     bool
+	OrgReferred  bool
+	UserReferred bool
+	Service      models.PaymentService
+}
+
+type referralShares struct {
+	Org  int64
+	User int64
+}
+
+func amountsGroup() {
+
+	orgWallet, userWallet := "org-referrer-wallet", "user-referrer-wallet"
+
+	DescribeTable("should calculate amounts",
+		func(c amountsCase, expected models.AmountsBreakdown, shares referralShares) {
+			options := lib.AmountsOptions{
+				Amount:            c.Amount,
+				Round:             &c.Round,
+				IsVerified:        c.Verified,
+				Service:           c.Service,
+				OrgFeeDiscount:    true,
+				UserFeeDiscount:   true,
+				OrgReferrerShare:  0.2,
+				UserReferrerShare: 0.2,
+			}
+			if c.OrgReferred {
+				options.OrgReferredWallet = &orgWallet
+			}
+			if c.UserReferred {
+				options.UserReferredWallet = &userWallet
+			}
+
+			amounts := lib.CalculateAmounts(options)
+			Expect(amounts.Check()).To(Succeed())
+			Expect(amounts.Scale).To(Equal(expected.Scale))
+			Expect(amounts.Amount).To(Equal(expected.Amount))
+			Expect(amounts.Fee).To(Equal(expected.Fee))
+			Expect(amounts.StripeFee).To(Equal(expected.StripeFee))
+			Expect(amounts.Total).To(Equal(expected.Total))
+			Expect(amounts.Payout).To(Equal(expected.Payout))
+			Expect(amounts.PayoutFee).To(Equal(expected.PayoutFee))
+			Expect(amounts.AppFee).To(Equal(expected.AppFee))
+
+			items := map[models.AmountLineItemType]int64{}
+			for _, item := range amounts.LineItems {
+				items[item.Type] = item.Amount
+			}
+			Expect(items[models.AmountLineItemOrgReferralShare]).To(Equal(shares.Org))
+			Expect(items[models.AmountLineItemUserReferralShare]).To(Equal(shares.User))
+			Expect(items[models.AmountLineItemPlatformFee]).To(Equal(expected.Fee - shares.Org))
+			Expect(items[models.AmountLineItemPayoutFee]).To(Equal(expected.PayoutFee - shares.User))
+		},
+		Entry("unverified, org not referred, user not referred, stripe",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: false, OrgReferred: false, UserReferred: false, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 3000, StripeFee: 3708, Total: 106708, Payout: 90000, PayoutFee: 10000, AppFee: 13000},
+			referralShares{Org: 0, User: 0},
+		),
+		Entry("unverified, org not referred, user not referred, crypto",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: false, OrgReferred: false, UserReferred: false, Service: models.PaymentServiceCrypto},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 3000, StripeFee: 0, Total: 103000, Payout: 90000, PayoutFee: 10000, AppFee: 13000},
+			referralShares{Org: 0, User: 0},
+		),
+		Entry("unverified, org not referred, user referred, stripe",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: false, OrgReferred: false, UserReferred: true, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 3000, StripeFee: 3708, Total: 106708, Payout: 95000, PayoutFee: 5000, AppFee: 8000},
+			referralShares{Org: 0, User: 1000},
+		),
+		Entry("unverified, org not referred, user referred, crypto",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: false, OrgReferred: false, UserReferred: true, Service: models.PaymentServiceCrypto},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 3000, StripeFee: 0, Total: 103000, Payout: 95000, PayoutFee: 5000, AppFee: 8000},
+			referralShares{Org: 0, User: 1000},
+		),
+		Entry("unverified, org referred, user not referred, stripe",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: false, OrgReferred: true, UserReferred: false, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 1500, StripeFee: 3654, Total: 105154, Payout: 90000, PayoutFee: 10000, AppFee: 11500},
+			referralShares{Org: 300, User: 0},
+		),
+		Entry("unverified, org referred, user not referred, crypto",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: false, OrgReferred: true, UserReferred: false, Service: models.PaymentServiceCrypto},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 1500, StripeFee: 0, Total: 101500, Payout: 90000, PayoutFee: 10000, AppFee: 11500},
+			referralShares{Org: 300, User: 0},
+		),
+		Entry("unverified, org referred, user referred, stripe",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: false, OrgReferred: true, UserReferred: true, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 1500, StripeFee: 3654, Total: 105154, Payout: 95000, PayoutFee: 5000, AppFee: 6500},
+			referralShares{Org: 300, User: 1000},
+		),
+		Entry("unverified, org referred, user referred, crypto",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: false, OrgReferred: true, UserReferred: true, Service: models.PaymentServiceCrypto},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 1500, StripeFee: 0, Total: 101500, Payout: 95000, PayoutFee: 5000, AppFee: 6500},
+			referralShares{Org: 300, User: 1000},
+		),
+		Entry("verified, org not referred, user not referred, stripe",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: true, OrgReferred: false, UserReferred: false, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 2000, StripeFee: 3672, Total: 105672, Payout: 95000, PayoutFee: 5000, AppFee: 7000},
+			referralShares{Org: 0, User: 0},
+		),
+		Entry("verified, org not referred, user not referred, crypto",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: true, OrgReferred: false, UserReferred: false, Service: models.PaymentServiceCrypto},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 2000, StripeFee: 0, Total: 102000, Payout: 95000, PayoutFee: 5000, AppFee: 7000},
+			referralShares{Org: 0, User: 0},
+		),
+		Entry("verified, org not referred, user referred, stripe",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: true, OrgReferred: false, UserReferred: true, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 2000, StripeFee: 3672, Total: 105672, Payout: 97500, PayoutFee: 2500, AppFee: 4500},
+			referralShares{Org: 0, User: 500},
+		),
+		Entry("verified, org not referred, user referred, crypto",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: true, OrgReferred: false, UserReferred: true, Service: models.PaymentServiceCrypto},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 2000, StripeFee: 0, Total: 102000, Payout: 97500, PayoutFee: 2500, AppFee: 4500},
+			referralShares{Org: 0, User: 500},
+		),
+		Entry("verified, org referred, user not referred, stripe",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: true, OrgReferred: true, UserReferred: false, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 1000, StripeFee: 3636, Total: 104636, Payout: 95000, PayoutFee: 5000, AppFee: 6000},
+			referralShares{Org: 200, User: 0},
+		),
+		Entry("verified, org referred, user not referred, crypto",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: true, OrgReferred: true, UserReferred: false, Service: models.PaymentServiceCrypto},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 1000, StripeFee: 0, Total: 101000, Payout: 95000, PayoutFee: 5000, AppFee: 6000},
+			referralShares{Org: 200, User: 0},
+		),
+		Entry("verified, org referred, user referred, stripe",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: true, OrgReferred: true, UserReferred: true, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 1000, StripeFee: 3636, Total: 104636, Payout: 97500, PayoutFee: 2500, AppFee: 3500},
+			referralShares{Org: 200, User: 500},
+		),
+		Entry("verified, org referred, user referred, crypto",
+			amountsCase{Amount: 1000, Round: 100.0, Verified: true, OrgReferred: true, UserReferred: true, Service: models.PaymentServiceCrypto},
+			models.AmountsBreakdown{Scale: 100, Amount: 100000, Fee: 1000, StripeFee: 0, Total: 101000, Payout: 97500, PayoutFee: 2500, AppFee: 3500},
+			referralShares{Org: 200, User: 500},
+		),
+		Entry("JPY rounds to whole yen",
+			amountsCase{Amount: 1234, Round: 1.0, Verified: false, OrgReferred: true, UserReferred: true, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 1, Amount: 1234, Fee: 19, StripeFee: 45, Total: 1298, Payout: 1172, PayoutFee: 62, AppFee: 81},
+			referralShares{Org: 3, User: 12},
+		),
+		Entry("USD rounds up to cents",
+			amountsCase{Amount: 33.33, Round: 100.0, Verified: true, OrgReferred: true, UserReferred: true, Service: models.PaymentServiceStripe},
+			models.AmountsBreakdown{Scale: 100, Amount: 3333, Fee: 34, StripeFee: 121, Total: 3488, Payout: 3249, PayoutFee: 84, AppFee: 118},
+			referralShares{Org: 6, User: 16},
+		),
+		Entry("crypto rounds to 5 decimals",
+			amountsCase{Amount: 0.123, Round: 100000.0, Verified: false, OrgReferred: false, UserReferred: false, Service: models.PaymentServiceCrypto},
+			models.AmountsBreakdown{Scale: 100000, Amount: 12300, Fee: 369, StripeFee: 0, Total: 12669, Payout: 11070, PayoutFee: 1230, AppFee: 1599},
+			referralShares{Org: 0, User: 0},
+		),
+	)
+
+	It("should not dereference missing round", func() {
+		amounts := lib.CalculateAmounts(lib.AmountsOptions{Amount: 10, Service: models.PaymentServiceCrypto})
+		Expect(amounts.Scale).To(Equal(int64(1)))
+		Expect(amounts.Check()).To(Succeed())
+	})
+
+	It("should fail check on unbalanced line items", func() {
+		amounts := lib.CalculateAmounts(lib.AmountsOptions{Amount: 10, Service: models.PaymentServiceStripe})
+		amounts.LineItems[0].Amount++
+		Expect(amounts.Check()).NotTo(Succeed())
+	})
+}
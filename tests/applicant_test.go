@@ -0,0 +1,201 @@
+package tests_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"socious/src/apps/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/socious-io/gopay"
+)
+
+func applicantGroup() {
+
+	projects := map[string]*models.Project{}
+	applicants := map[string]string{}
+
+	BeforeAll(func() {
+		str := func(s string) *string { return &s }
+		paid := models.PaymentTypePaid
+		fixed := models.PaymentSchemeFixed
+
+		for name, title := range map[string]string{"hired": "Data analyst", "rejected": "Fundraising lead"} {
+			projects[name] = createProject(&models.Project{
+				Title:           str(title),
+				Description:     str(fmt.Sprintf("%s for the impact report", title)),
+				IdentityID:      usersData[0].ID,
+				PaymentType:     &paid,
+				PaymentScheme:   &fixed,
+				PaymentCurrency: str("USD"),
+			})
+		}
+	})
+
+	applicantPath := func(name, suffix string) string {
+		return fmt.Sprintf("/projects/%s/applicants/%s%s", projects[name].ID, applicants[name], suffix)
+	}
+
+	It("should apply to projects", func() {
+		for name := range projects {
+			code, body := request("POST", fmt.Sprintf("/projects/%s/applicants", projects[name].ID), map[string]any{
+				"cover_letter": "I have been doing this for years",
+				"payment_rate": 1500,
+			}, authTokens[1])
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(body["status"]).To(Equal(string(models.ApplicantStatusPending)))
+			Expect(body["payment_type"]).To(Equal(string(models.PaymentTypePaid)))
+			applicants[name] = body["id"].(string)
+		}
+	})
+
+	It("should not apply twice or to own project", func() {
+		code, body := request("POST", fmt.Sprintf("/projects/%s/applicants", projects["hired"].ID), map[string]any{
+			"cover_letter": "Once more",
+		}, authTokens[1])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(Equal("already applied to the project"))
+
+		code, _ = request("POST", fmt.Sprintf("/projects/%s/applicants", projects["hired"].ID), map[string]any{
+			"cover_letter": "My own project",
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should list applicants to the owner only", func() {
+		path := fmt.Sprintf("/projects/%s/applicants", projects["hired"].ID)
+		code, body := request("GET", path, nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["total"]).To(Equal(float64(1)))
+		applicant := body["results"].([]interface{})[0].(map[string]interface{})
+		Expect(applicant["id"]).To(Equal(applicants["hired"]))
+		Expect(applicant["user"].(map[string]interface{})["id"]).To(Equal(usersData[1].ID.String()))
+
+		code, _ = request("GET", path, nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusForbidden))
+
+		code, _ = request("GET", applicantPath("hired", ""), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusOK))
+	})
+
+	It("should shortlist and reject applicants", func() {
+		code, _ := request("POST", applicantPath("hired", "/shortlist"), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusForbidden))
+
+		code, body := request("POST", applicantPath("hired", "/shortlist"), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["status"]).To(Equal(string(models.ApplicantStatusShortlisted)))
+
+		code, body = request("POST", applicantPath("rejected", "/reject"), map[string]any{
+			"feedback": "We are looking for more experience",
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["status"]).To(Equal(string(models.ApplicantStatusRejected)))
+		Expect(body["feedback"]).To(Equal("We are looking for more experience"))
+		Expect(body["closed_at"]).ToNot(BeNil())
+
+		code, _ = request("POST", applicantPath("rejected", "/hire"), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should not hire applicant without payment type", func() {
+		code, body := request("POST", applicantPath("hired", "/hire"), map[string]any{"payment_type": nil}, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(Equal("payment_type is required"))
+	})
+
+	It("should hire applicant with a contract", func() {
+		code, body := request("POST", applicantPath("hired", "/hire"), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+
+		applicant := body["applicant"].(map[string]interface{})
+		Expect(applicant["status"]).To(Equal(string(models.ApplicantStatusHired)))
+
+		contract := body["contract"].(map[string]interface{})
+		Expect(contract["applicant_id"]).To(Equal(applicants["hired"]))
+		Expect(contract["project_id"]).To(Equal(projects["hired"].ID.String()))
+		Expect(contract["name"]).To(Equal("Data analyst"))
+		Expect(contract["total_amount"]).To(Equal(float64(1500)))
+		Expect(contract["currency"]).To(Equal("USD"))
+		// The project has no payment mode
+		Expect(contract["payment_type"]).To(Equal(string(models.PaymentModeTypeFiat)))
+	})
+
+	It("should pay hourly hires for the approved hours at the rate asked", func() {
+		title, hours := "Field interviewer", "10"
+		paid, hourly := models.PaymentTypePaid, models.PaymentSchemeHourly
+		currency := "USD"
+		project := createProject(&models.Project{
+			Title:             &title,
+			IdentityID:        usersData[0].ID,
+			PaymentType:       &paid,
+			PaymentScheme:     &hourly,
+			PaymentCurrency:   &currency,
+			WeeklyHoursHigher: &hours,
+		})
+		code, applicant := request("POST", fmt.Sprintf("/projects/%s/applicants", project.ID), map[string]any{
+			"cover_letter": "Paid per hour",
+			"payment_rate": 20,
+		}, authTokens[1])
+		Expect(code).To(Equal(http.StatusCreated))
+
+		code, body := request("POST", fmt.Sprintf("/projects/%s/applicants/%s/hire", project.ID, applicant["id"]), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		contract := body["contract"].(map[string]interface{})
+		Expect(contract["commitment"]).To(Equal(float64(10)))
+		Expect(contract["commitment_period"]).To(Equal(string(models.ContractCommitmentWeekly)))
+		Expect(contract["total_amount"]).To(Equal(float64(200)))
+
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/sign", contract["id"]), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusAccepted))
+		code, work := request("POST", fmt.Sprintf("/contracts/%s/works", contract["id"]), contractWorksData[0], authTokens[1])
+		Expect(code).To(Equal(http.StatusCreated))
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/works/%s/approve", contract["id"], work["id"]), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusAccepted))
+
+		payment, err := gopay.New(gopay.PaymentParams{
+			Tag:         title,
+			Description: title,
+			Ref:         contract["id"].(string),
+			Currency:    gopay.USD,
+			TotalAmount: 200,
+			Type:        gopay.FIAT,
+		})
+		Expect(err).To(BeNil())
+		payment.Status = gopay.DEPOSITED
+		Expect(payment.Update()).To(BeNil())
+		_, err = db.Exec("UPDATE contracts SET payment_id=$2 WHERE id=$1", contract["id"], payment.ID)
+		Expect(err).To(BeNil())
+
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/release", contract["id"]), nil)
+		req.Header.Set("Authorization", authTokens[0])
+		req.Header.Set("Idempotency-Key", fmt.Sprintf("release-%s", contract["id"]))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+
+		// 8 approved hours at 20 are paid out, the rest of the week goes back to the provider
+		payment, _ = gopay.Fetch(payment.ID)
+		payouts := map[string]gopay.Transaction{}
+		for _, t := range payment.Transactions {
+			if t.Type == gopay.PAYOUT {
+				payouts[t.Tag] = t
+			}
+		}
+		Expect(payouts["release"].IdentityID).To(Equal(usersData[1].ID))
+		Expect(payouts["release"].Amount).To(BeNumerically(">", 0))
+		Expect(payouts["release"].Amount).To(BeNumerically("<=", 160))
+		Expect(payouts["refund"].IdentityID).To(Equal(usersData[0].ID))
+		Expect(payouts["refund"].Amount).To(BeNumerically("~", 40, 0.01))
+	})
+
+	It("should hire applicant once", func() {
+		code, _ := request("POST", applicantPath("hired", "/hire"), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+
+		var contracts int
+		Expect(db.Get(&contracts, "SELECT COUNT(*) FROM contracts WHERE applicant_id=$1", applicants["hired"])).To(BeNil())
+		Expect(contracts).To(Equal(1))
+	})
+}
@@ -0,0 +1,160 @@
+package tests_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"socious/src/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stripe/stripe-go/v81"
+)
+
+// fakeCards saves the payment methods it knows as cards and records the deleted ones
+type fakeCards struct {
+	methods map[string]map[string]interface{}
+	deleted []string
+}
+
+func (f *fakeCards) SaveCard(ctx context.Context, params lib.SaveCardParams) (*models.Card, error) {
+	pm, ok := f.methods[params.PaymentMethodID]
+	if !ok {
+		return nil, fmt.Errorf("no such payment method: %s", params.PaymentMethodID)
+	}
+	meta, _ := json.Marshal(pm)
+	brand := pm["card"].(map[string]interface{})["brand"].(string)
+	customer := fmt.Sprintf("cus_%s", params.PaymentMethodID)
+	return &models.Card{Brand: &brand, Meta: meta, Customer: &customer}, nil
+}
+
+func (f *fakeCards) DeleteCard(ctx context.Context, fiat string, card models.Card) error {
+	f.deleted = append(f.deleted, card.PaymentMethodID())
+	return nil
+}
+
+func cardGroup() {
+
+	provider := &fakeCards{methods: map[string]map[string]interface{}{
+		"pm_visa": {"id": "pm_visa", "card": map[string]interface{}{"brand": "visa", "last4": "4242", "exp_month": 12, "exp_year": 2030}},
+		"pm_mc":   {"id": "pm_mc", "card": map[string]interface{}{"brand": "mastercard", "last4": "4444", "exp_month": 1, "exp_year": 2031}},
+	}}
+
+	BeforeAll(func() {
+		lib.SetCardProvider(provider)
+	})
+
+	AfterAll(func() {
+		lib.SetCardProvider(nil)
+	})
+
+	listCards := func() []interface{} {
+		code, body := request("GET", "/cards", nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		return body["results"].([]interface{})
+	}
+
+	cards := []map[string]interface{}{}
+
+	It("should not add card without setup intent or payment method", func() {
+		code, _ := request("POST", "/cards", map[string]any{}, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should add cards", func() {
+		for _, pm := range []string{"pm_visa", "pm_mc"} {
+			code, card := request("POST", "/cards", map[string]any{"payment_method_id": pm}, authTokens[0])
+			Expect(code).To(Equal(http.StatusCreated))
+			cards = append(cards, card)
+		}
+		Expect(cards[0]["brand"]).To(Equal("visa"))
+		Expect(cards[0]["last4"]).To(Equal("4242"))
+		Expect(cards[0]["exp_year"]).To(Equal(float64(2030)))
+		// The first card becomes the default one
+		Expect(cards[0]["default"]).To(BeTrue())
+		Expect(cards[1]["last4"]).To(Equal("4444"))
+		Expect(cards[1]["default"]).To(BeFalse())
+	})
+
+	It("should list cards with the default one first", func() {
+		results := listCards()
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].(map[string]interface{})["id"]).To(Equal(cards[0]["id"]))
+	})
+
+	It("should set default card", func() {
+		code, card := request("POST", fmt.Sprintf("/cards/%s/default", cards[1]["id"]), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(card["default"]).To(BeTrue())
+
+		defaultCard, err := models.GetDefaultCard(usersData[0].ID)
+		Expect(err).To(BeNil())
+		Expect(defaultCard.ID.String()).To(Equal(cards[1]["id"]))
+		Expect(*defaultCard.Last4).To(Equal("4444"))
+	})
+
+	It("should not delete others card", func() {
+		code, _ := request("DELETE", fmt.Sprintf("/cards/%s", cards[0]["id"]), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should delete default card", func() {
+		code, _ := request("DELETE", fmt.Sprintf("/cards/%s", cards[1]["id"]), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(provider.deleted).To(Equal([]string{"pm_mc"}))
+
+		// The remaining card takes over
+		results := listCards()
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].(map[string]interface{})["default"]).To(BeTrue())
+	})
+
+	It("should save every payment method on a customer of its own", func() {
+		defaults := map[string]string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			r.ParseForm()
+			switch r.URL.Path {
+			case "/v1/payment_methods/pm_attached":
+				fmt.Fprint(w, `{"id": "pm_attached", "object": "payment_method", "card": {"brand": "visa", "last4": "1881"}, "customer": "cus_pm_visa"}`)
+			case "/v1/payment_methods/pm_detached":
+				fmt.Fprint(w, `{"id": "pm_detached", "object": "payment_method", "card": {"brand": "visa", "last4": "4242"}}`)
+			case "/v1/payment_methods/pm_detached/attach":
+				fmt.Fprintf(w, `{"id": "pm_detached", "object": "payment_method", "card": {"brand": "visa", "last4": "4242"}, "customer": "%s"}`, r.PostForm.Get("customer"))
+			case "/v1/customers":
+				fmt.Fprint(w, `{"id": "cus_new", "object": "customer"}`)
+			case "/v1/customers/cus_new", "/v1/customers/cus_pm_visa":
+				defaults[r.URL.Path] = r.PostForm.Get("invoice_settings[default_payment_method]")
+				fmt.Fprint(w, `{"id": "cus_new", "object": "customer"}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+			URL: stripe.String(server.URL),
+		}))
+		DeferCleanup(func() {
+			stripe.SetBackend(stripe.APIBackend, nil)
+			server.Close()
+		})
+
+		stripeCards := lib.NewStripeCardProvider(config.Config.Payment.Fiats.Gopay())
+		params := lib.SaveCardParams{IdentityID: usersData[0].ID, Fiat: lib.CardFiat(false), Email: "card@socious.io"}
+
+		// Making it default on its customer would change the card charged on other rows
+		params.PaymentMethodID = "pm_attached"
+		_, err := stripeCards.SaveCard(context.Background(), params)
+		Expect(err).NotTo(BeNil())
+
+		params.PaymentMethodID = "pm_detached"
+		card, err := stripeCards.SaveCard(context.Background(), params)
+		Expect(err).To(BeNil())
+		Expect(*card.Customer).To(Equal("cus_new"))
+		Expect(card.PaymentMethodID()).To(Equal("pm_detached"))
+		Expect(defaults).To(Equal(map[string]string{"/v1/customers/cus_new": "pm_detached"}))
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"socious/src/apps/models"
 
+	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -71,4 +73,507 @@ func contractGroup() {
 			Expect(w.Code).To(Equal(http.StatusOK))
 		}
 	})
+
+	It("should snapshot contract fee policy", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/contracts/%s", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(body["fee_policy_id"]).NotTo(BeNil())
+			amounts := body["amounts"].(map[string]interface{})
+			Expect(amounts["fee_policy_id"]).To(Equal(body["fee_policy_id"]))
+			// The snapshotted policy is fetched with the contract
+			Expect(body["fee_policy"].(map[string]interface{})["id"]).To(Equal(body["fee_policy_id"]))
+		}
+	})
+
+	It("should create contract milestones", func() {
+		for _, data := range contractsData {
+			for j, milestone := range contractMilestonesData {
+				w := httptest.NewRecorder()
+				reqBody, _ := json.Marshal(milestone)
+				req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/milestones", data["id"]), bytes.NewBuffer(reqBody))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", authTokens[0])
+				router.ServeHTTP(w, req)
+				body := decodeBody(w.Body)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+				Expect(body["status"]).To(Equal("PENDING"))
+				contractMilestonesData[j]["id"] = body["id"]
+			}
+		}
+	})
+
+	It("should not exceed contract total amount with milestones", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(gin.H{"title": "extra milestone", "amount": 5000})
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/milestones", data["id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		}
+	})
+
+	It("should get contract milestones", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/contracts/%s/milestones", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[1])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			results := body["results"].([]interface{})
+			Expect(len(results)).To(Equal(len(contractMilestonesData)))
+			Expect(results[0].(map[string]interface{})["amounts"]).NotTo(BeNil())
+		}
+	})
+
+	It("should delete contract milestone", func() {
+		for _, data := range contractsData {
+			last := contractMilestonesData[len(contractMilestonesData)-1]
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", fmt.Sprintf("/contracts/%s/milestones/%s", data["id"], last["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+		}
+	})
+
+	It("should not complete unsigned contract", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/complete", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		}
+	})
+
+	It("should sign contract", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(map[string]any{"reason": "looks good"})
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/sign", data["id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[1])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(body["status"]).To(Equal("SIGNED"))
+		}
+	})
+
+	It("should get contract history", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/contracts/%s/history", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			results := body["results"].([]interface{})
+			Expect(len(results)).To(Equal(1))
+			Expect(results[0].(map[string]interface{})["old_status"]).To(Equal("CREATED"))
+			Expect(results[0].(map[string]interface{})["new_status"]).To(Equal("SIGNED"))
+		}
+	})
+
+	It("should get contract signatures", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/contracts/%s/signatures", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			results := body["results"].([]interface{})
+			Expect(len(results)).To(Equal(1))
+			Expect(len(results[0].(map[string]interface{})["hash"].(string))).To(Equal(64))
+		}
+	})
+
+	It("should verify signed contract terms", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/contracts/%s/signatures/verify", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[1])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(body["valid"]).To(Equal(true))
+		}
+	})
+
+	It("should download contract pdf", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/contracts/%s/pdf", data["id"]), nil)
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal("application/pdf"))
+		}
+	})
+
+	It("should require idempotency key to release contract", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/release", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		}
+	})
+
+	It("should not release or refund undeposited contract", func() {
+		for _, data := range contractsData {
+			for _, action := range []string{"release", "refund"} {
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/%s", data["id"], action), nil)
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", authTokens[1])
+				req.Header.Set("Idempotency-Key", fmt.Sprintf("%s-%s", action, data["id"]))
+				router.ServeHTTP(w, req)
+				Expect(w.Code).NotTo(Equal(http.StatusAccepted))
+			}
+		}
+	})
+
+	It("should open contract dispute", func() {
+		for i, data := range contractsData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(gin.H{
+				"title":    "work is not delivered",
+				"category": "INCOMPLETE_OR_UNSATISFACTORY_WORK",
+				"message":  "deliverables are missing",
+			})
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/disputes", data["id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[1])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(body["state"]).To(Equal("AWAITING_RESPONSE"))
+			Expect(body["jurors"]).To(HaveLen(3))
+			contractsData[i]["dispute_id"] = body["id"]
+		}
+	})
+
+	It("should not resolve dispute before voting deadline", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/disputes/%s/resolve", data["id"], data["dispute_id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[1])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		}
+	})
+
+	It("should freeze contract payout while dispute is open", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/release", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			req.Header.Set("Idempotency-Key", fmt.Sprintf("frozen-%s", data["id"]))
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(body["error"]).To(Equal("Contract payout is frozen by an open dispute"))
+		}
+	})
+
+	It("should respond to contract dispute", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(gin.H{"message": "deliverables are attached"})
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/disputes/%s/respond", data["id"], data["dispute_id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(body["state"]).To(Equal("PENDING_REVIEW"))
+			Expect(len(body["events"].([]interface{}))).To(Equal(2))
+		}
+	})
+
+	It("should withdraw contract dispute", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(gin.H{"message": "issue is solved"})
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/disputes/%s/withdraw", data["id"], data["dispute_id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[1])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(body["state"]).To(Equal("WITHDRAWN"))
+		}
+	})
+
+	hourly := gin.H{}
+
+	It("should submit works on hourly contracts only", func() {
+		code, body := request("POST", fmt.Sprintf("/contracts/%s/works", contractsData[0]["id"]), contractWorksData[0], authTokens[1])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(Equal("works are submitted on hourly contracts only"))
+
+		title, scheme := "Field coordinator", models.PaymentSchemeHourly
+		project := createProject(&models.Project{Title: &title, IdentityID: usersData[0].ID, PaymentScheme: &scheme})
+		code, hourly = request("POST", "/contracts", gin.H{
+			"title":             "hourly contract",
+			"description":       "paid per approved hour",
+			"total_amount":      200,
+			"currency":          "USD",
+			"type":              "PAID",
+			"payment_type":      "CRYPTO",
+			"commitment":        10,
+			"commitment_period": "WEEKLY",
+			"project_id":        project.ID,
+			"client_id":         usersData[1].ID,
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/sign", hourly["id"]), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusAccepted))
+	})
+
+	It("should submit contract works", func() {
+		workIDs := []interface{}{}
+		for _, work := range contractWorksData {
+			code, body := request("POST", fmt.Sprintf("/contracts/%s/works", hourly["id"]), work, authTokens[1])
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(body["status"]).To(Equal("PENDING"))
+			workIDs = append(workIDs, body["id"])
+		}
+		hourly["work_ids"] = workIDs
+	})
+
+	It("should not submit overlapping contract works", func() {
+		code, _ := request("POST", fmt.Sprintf("/contracts/%s/works", hourly["id"]), contractWorksData[0], authTokens[1])
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should approve and reject contract works", func() {
+		workIDs := hourly["work_ids"].([]interface{})
+		for i, action := range []string{"approve", "reject"} {
+			code, _ := request("POST", fmt.Sprintf("/contracts/%s/works/%s/%s", hourly["id"], workIDs[i], action), nil, authTokens[0])
+			Expect(code).To(Equal(http.StatusAccepted))
+		}
+	})
+
+	It("should not approve works over contract total amount", func() {
+		// 8 approved hours are paid 160 of 200 and 4 more hours would be 240
+		code, work := request("POST", fmt.Sprintf("/contracts/%s/works", hourly["id"]), gin.H{
+			"start_at": "2025-01-08T09:00:00Z",
+			"end_at":   "2025-01-08T13:00:00Z",
+		}, authTokens[1])
+		Expect(code).To(Equal(http.StatusCreated))
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/works/%s/approve", hourly["id"], work["id"]), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should get contract approved hours", func() {
+		code, body := request("GET", fmt.Sprintf("/contracts/%s/works", hourly["id"]), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["total"]).To(Equal(float64(3)))
+		Expect(body["approved_hours"]).To(Equal(float64(8)))
+		Expect(body["approved_amount"]).To(Equal(float64(160)))
+	})
+
+	It("should not update signed contract terms", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(gin.H{
+				"name":         data["title"],
+				"type":         data["type"],
+				"total_amount": 3000,
+				"client_id":    data["client_id"],
+			})
+			req, _ := http.NewRequest("PATCH", fmt.Sprintf("/contracts/%s", data["id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		}
+	})
+
+	It("should update signed contract without touching its terms", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(gin.H{"description": "updated desc"})
+			req, _ := http.NewRequest("PATCH", fmt.Sprintf("/contracts/%s", data["id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(body["description"]).To(Equal("updated desc"))
+			Expect(body["currency"]).To(Equal(data["currency"]))
+		}
+	})
+
+	It("should propose contract amendment", func() {
+		for i, data := range contractsData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(gin.H{"total_amount": 3000, "reason": "extended scope"})
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/amendments", data["id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(body["status"]).To(Equal("PENDING"))
+			Expect(body["old_terms"].(map[string]interface{})["total_amount"]).To(Equal(float64(2000)))
+			contractsData[i]["amendment_id"] = body["id"]
+		}
+	})
+
+	It("should not accept own contract amendment", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/amendments/%s/accept", data["id"], data["amendment_id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		}
+	})
+
+	It("should accept contract amendment", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/amendments/%s/accept", data["id"], data["amendment_id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[1])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(body["amendment"].(map[string]interface{})["status"]).To(Equal("ACCEPTED"))
+			contract := body["contract"].(map[string]interface{})
+			Expect(contract["total_amount"]).To(Equal(float64(3000)))
+			Expect(len(contract["amendments"].([]interface{}))).To(Equal(1))
+		}
+	})
+
+	It("should detect contract terms changed after signing", func() {
+		for _, data := range contractsData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/contracts/%s/signatures/verify", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[1])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(body["valid"]).To(Equal(false))
+		}
+	})
+
+	It("should create contract templates", func() {
+		for i, data := range contractTemplatesData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(data)
+			req, _ := http.NewRequest("POST", "/contract-templates", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(body["title"]).To(Equal(data["title"]))
+			contractTemplatesData[i]["id"] = body["id"]
+		}
+	})
+
+	It("should not get others contract template", func() {
+		for _, data := range contractTemplatesData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/contract-templates/%s", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[1])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		}
+	})
+
+	It("should create contract from template", func() {
+		for _, data := range contractTemplatesData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(gin.H{
+				"client_id":    usersData[1].ID,
+				"total_amount": 500,
+			})
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts?template_id=%s", data["id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(body["name"]).To(Equal(data["name"]))
+			Expect(body["type"]).To(Equal(data["type"]))
+			Expect(body["commitment_period"]).To(Equal(data["commitment_period"]))
+			Expect(body["requirement_description"]).To(Equal(data["requirement_description"]))
+			Expect(body["total_amount"]).To(Equal(float64(500)))
+		}
+	})
+
+	It("should keep crypto network of template contract", func() {
+		w := httptest.NewRecorder()
+		reqBody, _ := json.Marshal(gin.H{
+			"title":             "crypto agreement",
+			"name":              "crypto contract",
+			"type":              "PAID",
+			"commitment_period": "MONTHLY",
+			"payment_type":      "CRYPTO",
+			"currency":          "USD",
+			"crypto_network":    "sepolia",
+		})
+		req, _ := http.NewRequest("POST", "/contract-templates", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authTokens[0])
+		router.ServeHTTP(w, req)
+		body := decodeBody(w.Body)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		templateID := body["id"]
+
+		w = httptest.NewRecorder()
+		reqBody, _ = json.Marshal(gin.H{"client_id": usersData[1].ID, "total_amount": 500})
+		req, _ = http.NewRequest("POST", fmt.Sprintf("/contracts?template_id=%s", templateID), bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authTokens[0])
+		router.ServeHTTP(w, req)
+		body = decodeBody(w.Body)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		Expect(body["crypto_network"]).To(Equal("sepolia"))
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("DELETE", fmt.Sprintf("/contract-templates/%s", templateID), nil)
+		req.Header.Set("Authorization", authTokens[0])
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should delete contract templates", func() {
+		for _, data := range contractTemplatesData {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", fmt.Sprintf("/contract-templates/%s", data["id"]), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+		}
+	})
 }
@@ -27,6 +27,13 @@ var (
 		},
 	}
 
+	// Verified contributors invited as jurors to the disputes
+	jurorsData = []*models.User{
+		{Username: "juror1", Email: "juror1@test.com", Events: []string{}, Tags: []string{}},
+		{Username: "juror2", Email: "juror2@test.com", Events: []string{}, Tags: []string{}},
+		{Username: "juror3", Email: "juror3@test.com", Events: []string{}, Tags: []string{}},
+	}
+
 	jobCategoryData = []gin.H{
 		{
 			"name":                "OTHER",
@@ -59,7 +66,43 @@ var (
 			"currency":          "USD",
 			"type":              "PAID",
 			"payment_type":      "CRYPTO",
+			"commitment":        100,
 			"commitment_period": "MONTHLY",
 		},
 	}
+	contractTemplatesData = []gin.H{
+		{
+			"title":                   "volunteering agreement",
+			"name":                    "volunteering contract",
+			"type":                    "VOLUNTEER",
+			"commitment":              10,
+			"commitment_period":       "WEEKLY",
+			"payment_type":            "FIAT",
+			"currency":                "USD",
+			"requirement_description": "standard volunteering requirements",
+		},
+	}
+	contractWorksData = []gin.H{
+		{
+			"description": "first week",
+			"start_at":    "2025-01-06T09:00:00Z",
+			"end_at":      "2025-01-06T17:00:00Z",
+		},
+		{
+			"start_at": "2025-01-07T09:00:00Z",
+			"end_at":   "2025-01-07T13:00:00Z",
+		},
+	}
+	contractMilestonesData = []gin.H{
+		{
+			"title":       "first milestone",
+			"description": "first month deliverables",
+			"amount":      1000,
+			"due_date":    "2030-01-01T00:00:00Z",
+		},
+		{
+			"title":  "second milestone",
+			"amount": 500,
+		},
+	}
 )
@@ -0,0 +1,441 @@
+package tests_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"socious/src/apps/workers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/socious-io/gopay"
+)
+
+// fakeChain serves the receipts of the transactions it knows
+type fakeChain map[string]*lib.TxReceipt
+
+func (f fakeChain) Receipt(ctx context.Context, txHash, tokenAddress string) (*lib.TxReceipt, error) {
+	return f[txHash], nil
+}
+
+func depositGroup() {
+
+	ctx := context.Background()
+	recipient, token := "0xEscrow", "0xToken"
+
+	chain := fakeChain{
+		"0xunconfirmed":     {To: recipient, TokenAddress: token, Amount: 100, Confirmations: 3},
+		"0xwrong-recipient": {To: "0xSomeoneElse", TokenAddress: token, Amount: 100, Confirmations: 12},
+		"0xwrong-token":     {To: recipient, TokenAddress: "0xOtherToken", Amount: 100, Confirmations: 12},
+		"0xshort-amount":    {To: recipient, TokenAddress: token, Amount: 99.5, Confirmations: 12},
+		"0xvalid":           {To: "0xescrow", TokenAddress: "0xtoken", Amount: 100, Confirmations: 12},
+	}
+
+	newDeposit := func(txID string) (*models.CryptoDeposit, *gopay.Payment) {
+		payment, err := gopay.New(gopay.PaymentParams{
+			Tag:         "deposit",
+			Description: txID,
+			Ref:         uuid.NewString(),
+			Currency:    gopay.USD,
+			TotalAmount: 97,
+			Type:        gopay.CRYPTO,
+		})
+		Expect(err).To(BeNil())
+		Expect(payment.SetToCryptoMode(token, 1)).To(BeNil())
+
+		deposit := &models.CryptoDeposit{
+			ContractID:            uuid.MustParse(contractsData[0]["id"].(string)),
+			PaymentID:             payment.ID,
+			IdentityID:            usersData[0].ID,
+			TxID:                  txID,
+			TokenAddress:          token,
+			Recipient:             recipient,
+			ExpectedAmount:        100,
+			RequiredConfirmations: 12,
+		}
+		Expect(deposit.Create(ctx, map[string]string{"wallet": "0xClient"})).To(BeNil())
+		Expect(deposit.Status).To(Equal(models.CryptoDepositStatusPending))
+		return deposit, payment
+	}
+
+	It("should not reuse a transaction for deposits", func() {
+		newDeposit("0xunconfirmed")
+		deposit := &models.CryptoDeposit{
+			ContractID:            uuid.MustParse(contractsData[0]["id"].(string)),
+			PaymentID:             uuid.New(),
+			IdentityID:            usersData[0].ID,
+			TxID:                  "0xUNCONFIRMED",
+			TokenAddress:          token,
+			Recipient:             recipient,
+			ExpectedAmount:        100,
+			RequiredConfirmations: 12,
+		}
+		Expect(deposit.Create(ctx, nil)).NotTo(BeNil())
+	})
+
+	It("should keep deposits pending until found and confirmed", func() {
+		deposit, _ := newDeposit("0xmissing")
+		Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+		Expect(deposit.Status).To(Equal(models.CryptoDepositStatusPending))
+		Expect(deposit.Attempts).To(Equal(1))
+		Expect(*deposit.Error).To(Equal("transaction not found"))
+
+		deposit, payment := newDeposit("0xunconfirmed-2")
+		chain["0xunconfirmed-2"] = chain["0xunconfirmed"]
+		Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+		Expect(deposit.Status).To(Equal(models.CryptoDepositStatusPending))
+		Expect(deposit.Confirmations).To(Equal(3))
+
+		payment, _ = gopay.Fetch(payment.ID)
+		Expect(payment.Status).NotTo(Equal(gopay.DEPOSITED))
+	})
+
+	for _, txID := range []string{"0xwrong-recipient", "0xwrong-token", "0xshort-amount"} {
+		It(fmt.Sprintf("should fail mismatching deposit %s", txID), func() {
+			deposit, payment := newDeposit(txID)
+			Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+			Expect(deposit.Status).To(Equal(models.CryptoDepositStatusFailed))
+			Expect(*deposit.Error).To(ContainSubstring(lib.ErrDepositMismatch.Error()))
+
+			payment, _ = gopay.Fetch(payment.ID)
+			Expect(payment.Status).To(Equal(gopay.CANCLED))
+		})
+	}
+
+	It("should confirm matching deposit with enough confirmations", func() {
+		deposit, payment := newDeposit("0xvalid")
+		Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+		Expect(deposit.Status).To(Equal(models.CryptoDepositStatusConfirmed))
+		Expect(deposit.ConfirmedAt).NotTo(BeNil())
+
+		payment, _ = gopay.Fetch(payment.ID)
+		Expect(payment.Status).To(Equal(gopay.DEPOSITED))
+		Expect(payment.Transactions).To(HaveLen(1))
+		Expect(payment.Transactions[0].TXID).To(Equal("0xvalid"))
+
+		// Settled deposits are not checked again
+		Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).NotTo(BeNil())
+	})
+
+	It("should confirm deposit retried after its transaction was recorded", func() {
+		deposit, payment := newDeposit("0xretried")
+		chain["0xretried"] = chain["0xvalid"]
+
+		// A previous check recorded the transaction and failed before the deposit was confirmed
+		t := &gopay.Transaction{
+			PaymentID:  payment.ID,
+			TXID:       deposit.TxID,
+			IdentityID: deposit.IdentityID,
+			Tag:        string(gopay.DEPOSIT),
+			Amount:     payment.TotalAmount,
+			Type:       gopay.DEPOSIT,
+		}
+		Expect(t.Create()).To(BeNil())
+
+		Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+		Expect(deposit.Status).To(Equal(models.CryptoDepositStatusConfirmed))
+		payment, _ = gopay.Fetch(payment.ID)
+		Expect(payment.Status).To(Equal(gopay.DEPOSITED))
+		Expect(payment.Transactions).To(HaveLen(1))
+	})
+
+	It("should fund milestone once its deposit is confirmed and release it on approval", func() {
+		contractID := uuid.MustParse(contractsData[0]["id"].(string))
+		milestones, err := models.GetContractMilestones(contractID)
+		Expect(err).To(BeNil())
+		milestone := &milestones[0]
+		Expect(milestone.Status).To(Equal(models.ContractMilestoneStatusPending))
+
+		deposit, payment := newDeposit("0xmilestone")
+		chain["0xmilestone"] = chain["0xvalid"]
+		Expect(milestone.LinkPayment(ctx, payment.ID)).To(BeNil())
+		Expect(milestone.Status).To(Equal(models.ContractMilestoneStatusPending))
+
+		approve := func() (int, gin.H) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/milestones/%s/approve", contractID, milestone.ID), nil)
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			return w.Code, decodeBody(w.Body)
+		}
+		code, _ := approve()
+		Expect(code).To(Equal(http.StatusBadRequest))
+
+		Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+		milestone, err = models.GetContractMilestone(milestone.ID, contractID)
+		Expect(err).To(BeNil())
+		Expect(milestone.Status).To(Equal(models.ContractMilestoneStatusFunded))
+
+		code, body := approve()
+		Expect(code).To(Equal(http.StatusAccepted))
+		Expect(body["status"]).To(Equal(string(models.ContractMilestoneStatusApproved)))
+
+		payment, _ = gopay.Fetch(payment.ID)
+		Expect(payment.Status).To(Equal(gopay.PAID_OUT))
+
+		// Released milestones are not paid out twice
+		code, _ = approve()
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should refund the escrow left of the deposit once", func() {
+		code, contract := request("POST", "/contracts", gin.H{
+			"title":             "refunded contract",
+			"description":       "canceled before the work started",
+			"total_amount":      97,
+			"currency":          "USD",
+			"type":              "PAID",
+			"payment_type":      "CRYPTO",
+			"commitment":        10,
+			"commitment_period": "MONTHLY",
+			"client_id":         usersData[1].ID,
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/sign", contract["id"]), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusAccepted))
+
+		deposit, payment := newDeposit("0xrefund")
+		chain["0xrefund"] = chain["0xvalid"]
+		Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+		_, err := db.Exec("UPDATE contracts SET payment_id=$2 WHERE id=$1", contract["id"], payment.ID)
+		Expect(err).To(BeNil())
+
+		refund := func() (int, map[string]interface{}) {
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/refund", contract["id"]), nil)
+			req.Header.Set("Authorization", authTokens[1])
+			req.Header.Set("Idempotency-Key", fmt.Sprintf("refund-%s", contract["id"]))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w.Code, decodeBody(w.Body)
+		}
+		code, body := refund()
+		Expect(code).To(Equal(http.StatusAccepted))
+		refundID := body["escrow"].(map[string]interface{})["refund_id"]
+
+		payment, _ = gopay.Fetch(payment.ID)
+		Expect(payment.Status).To(Equal(gopay.REFUNDED))
+		Expect(payment.Transactions).To(HaveLen(2))
+		for _, t := range payment.Transactions {
+			if t.Type == gopay.PAYOUT {
+				Expect(t.Tag).To(Equal("refund"))
+				Expect(t.Amount).To(Equal(float64(97)))
+			}
+		}
+
+		// Retrying the refund settles nothing again
+		code, body = refund()
+		Expect(code).To(Equal(http.StatusAccepted))
+		Expect(body["escrow"].(map[string]interface{})["refund_id"]).To(Equal(refundID))
+		payment, _ = gopay.Fetch(payment.ID)
+		Expect(payment.Transactions).To(HaveLen(2))
+	})
+
+	It("should not amend money terms of funded contracts", func() {
+		code, contract := request("POST", "/contracts", gin.H{
+			"title":             "funded contract",
+			"description":       "amended after the deposit",
+			"total_amount":      97,
+			"currency":          "USD",
+			"type":              "PAID",
+			"payment_type":      "CRYPTO",
+			"commitment":        10,
+			"commitment_period": "MONTHLY",
+			"client_id":         usersData[1].ID,
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/sign", contract["id"]), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusAccepted))
+
+		// Proposed before the deposit, accepted after it
+		code, amendment := request("POST", fmt.Sprintf("/contracts/%s/amendments", contract["id"]), gin.H{"total_amount": 120}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+
+		_, payment := newDeposit("0xamended")
+		_, err := db.Exec("UPDATE contracts SET payment_id=$2 WHERE id=$1", contract["id"], payment.ID)
+		Expect(err).To(BeNil())
+
+		code, body := request("POST", fmt.Sprintf("/contracts/%s/amendments/%s/accept", contract["id"], amendment["id"]), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(Equal("money terms of funded contracts can't be amended"))
+		code, body = request("POST", fmt.Sprintf("/contracts/%s/amendments", contract["id"]), gin.H{"currency": "JPY"}, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(Equal("money terms of funded contracts can't be amended"))
+
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/amendments", contract["id"]), gin.H{"commitment": 12}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+	})
+
+	It("should fund contracts per milestone only and refund funded milestones", func() {
+		code, contract := request("POST", "/contracts", gin.H{
+			"title":             "milestone contract",
+			"description":       "funded per milestone",
+			"total_amount":      97,
+			"currency":          "USD",
+			"type":              "PAID",
+			"payment_type":      "CRYPTO",
+			"commitment":        10,
+			"commitment_period": "MONTHLY",
+			"client_id":         usersData[1].ID,
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/sign", contract["id"]), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusAccepted))
+		code, first := request("POST", fmt.Sprintf("/contracts/%s/milestones", contract["id"]), gin.H{"title": "first half", "amount": 50}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+
+		// Milestones not covering the contract total can't be funded
+		form := gin.H{"txid": "0xmilestone-short", "meta": gin.H{}}
+		code, body := request("POST", fmt.Sprintf("/contracts/%s/milestones/%s/fund", contract["id"], first["id"]), form, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(ContainSubstring("must equal contract total amount"))
+
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/milestones", contract["id"]), gin.H{"title": "second half", "amount": 47}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+
+		// Contracts split into milestones are not deposited in full
+		code, body = request("POST", fmt.Sprintf("/contracts/%s/deposit", contract["id"]), form, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(Equal("contract is funded per milestone"))
+
+		contractID := uuid.MustParse(contract["id"].(string))
+		milestone, err := models.GetContractMilestone(uuid.MustParse(first["id"].(string)), contractID)
+		Expect(err).To(BeNil())
+		deposit, payment := newDeposit("0xmilestone-refund")
+		chain["0xmilestone-refund"] = chain["0xvalid"]
+		Expect(milestone.LinkPayment(ctx, payment.ID)).To(BeNil())
+		Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/refund", contract["id"]), nil)
+		req.Header.Set("Authorization", authTokens[1])
+		req.Header.Set("Idempotency-Key", fmt.Sprintf("refund-%s", contract["id"]))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+		Expect(decodeBody(w.Body)["milestones"]).To(HaveLen(1))
+
+		milestone, err = models.GetContractMilestone(milestone.ID, contractID)
+		Expect(err).To(BeNil())
+		Expect(milestone.Status).To(Equal(models.ContractMilestoneStatusRefunded))
+		payment, _ = gopay.Fetch(payment.ID)
+		Expect(payment.Status).To(Equal(gopay.REFUNDED))
+	})
+
+	It("should credit referral rewards once per released milestone", func() {
+		referrer := jurorsData[2]
+		_, err := db.Exec("INSERT INTO referrings (referred_identity_id, referred_by_id) VALUES ($1, $2)", usersData[1].ID, referrer.ID)
+		Expect(err).To(BeNil())
+		DeferCleanup(func() {
+			db.Exec("DELETE FROM referrings WHERE referred_identity_id=$1", usersData[1].ID)
+		})
+
+		code, contract := request("POST", "/contracts", gin.H{
+			"title":             "referred contract",
+			"description":       "released per milestone",
+			"total_amount":      97,
+			"currency":          "USD",
+			"type":              "PAID",
+			"payment_type":      "CRYPTO",
+			"commitment":        10,
+			"commitment_period": "MONTHLY",
+			"client_id":         usersData[1].ID,
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/sign", contract["id"]), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusAccepted))
+
+		contractID := uuid.MustParse(contract["id"].(string))
+		payments := []uuid.UUID{}
+		for i, amount := range []float64{50, 47} {
+			code, m := request("POST", fmt.Sprintf("/contracts/%s/milestones", contractID), gin.H{"title": fmt.Sprintf("milestone %d", i), "amount": amount}, authTokens[0])
+			Expect(code).To(Equal(http.StatusCreated))
+			milestone, err := models.GetContractMilestone(uuid.MustParse(m["id"].(string)), contractID)
+			Expect(err).To(BeNil())
+
+			txID := fmt.Sprintf("0xreferred-%d", i)
+			deposit, payment := newDeposit(txID)
+			chain[txID] = chain["0xvalid"]
+			Expect(milestone.LinkPayment(ctx, payment.ID)).To(BeNil())
+			Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+			payments = append(payments, payment.ID)
+
+			approve := fmt.Sprintf("/contracts/%s/milestones/%s/approve", contractID, milestone.ID)
+			code, _ = request("POST", approve, nil, authTokens[0])
+			Expect(code).To(Equal(http.StatusAccepted))
+			code, _ = request("POST", approve, nil, authTokens[0])
+			Expect(code).To(Equal(http.StatusBadRequest))
+		}
+
+		credited := []uuid.UUID{}
+		err = db.Select(&credited, "SELECT payment_id FROM referral_rewards WHERE contract_id=$1 AND referrer_id=$2", contractID, referrer.ID)
+		Expect(err).To(BeNil())
+		Expect(credited).To(ConsistOf(payments))
+	})
+
+	It("should refund what's left over the approved works when releasing hourly contract", func() {
+		title, scheme := "Survey enumerator", models.PaymentSchemeHourly
+		project := createProject(&models.Project{Title: &title, IdentityID: usersData[0].ID, PaymentScheme: &scheme})
+		code, contract := request("POST", "/contracts", gin.H{
+			"title":             "hourly survey",
+			"description":       "paid per approved hour",
+			"total_amount":      97,
+			"currency":          "USD",
+			"type":              "PAID",
+			"payment_type":      "CRYPTO",
+			"commitment":        10,
+			"commitment_period": "WEEKLY",
+			"project_id":        project.ID,
+			"client_id":         usersData[1].ID,
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/sign", contract["id"]), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusAccepted))
+
+		// 8 of the 10 hours are approved
+		code, work := request("POST", fmt.Sprintf("/contracts/%s/works", contract["id"]), contractWorksData[0], authTokens[1])
+		Expect(code).To(Equal(http.StatusCreated))
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/works/%s/approve", contract["id"], work["id"]), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusAccepted))
+
+		deposit, payment := newDeposit("0xhourly")
+		chain["0xhourly"] = chain["0xvalid"]
+		Expect(workers.CheckCryptoDeposit(ctx, chain, deposit)).To(BeNil())
+		_, err := db.Exec("UPDATE contracts SET payment_id=$2 WHERE id=$1", contract["id"], payment.ID)
+		Expect(err).To(BeNil())
+
+		release := func() int {
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/contracts/%s/release", contract["id"]), nil)
+			req.Header.Set("Authorization", authTokens[0])
+			req.Header.Set("Idempotency-Key", fmt.Sprintf("release-%s", contract["id"]))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		// Charged back payments are held until the Stripe dispute is won
+		_, err = db.Exec("UPDATE contracts SET chargeback_id='dp_test' WHERE id=$1", contract["id"])
+		Expect(err).To(BeNil())
+		Expect(release()).To(Equal(http.StatusBadRequest))
+		_, err = db.Exec("UPDATE contracts SET chargeback_id=NULL WHERE id=$1", contract["id"])
+		Expect(err).To(BeNil())
+		Expect(release()).To(Equal(http.StatusAccepted))
+
+		payment, _ = gopay.Fetch(payment.ID)
+		Expect(payment.Status).To(Equal(gopay.PAID_OUT))
+		payouts := map[string]gopay.Transaction{}
+		for _, t := range payment.Transactions {
+			if t.Type == gopay.PAYOUT {
+				payouts[t.Tag] = t
+			}
+		}
+		Expect(payouts).To(HaveLen(2))
+		Expect(payouts["release"].IdentityID).To(Equal(usersData[1].ID))
+		Expect(payouts["release"].Amount).To(BeNumerically("<=", 77.6))
+		Expect(payouts["refund"].IdentityID).To(Equal(usersData[0].ID))
+		Expect(payouts["refund"].Amount).To(BeNumerically("~", 19.4, 1e-9))
+	})
+}
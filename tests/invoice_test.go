@@ -0,0 +1,189 @@
+package tests_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"socious/src/config"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/stripe/stripe-go/v81"
+)
+
+func invoiceGroup() {
+
+	ctx := context.Background()
+	var invoice *models.Invoice
+
+	It("should issue invoices of the payout", func() {
+		contract, err := models.GetContract(uuid.MustParse(contractsData[0]["id"].(string)))
+		Expect(err).To(BeNil())
+		amounts := lib.CalculateAmounts(lib.AmountsOptionsFromContract(*contract, nil, nil))
+
+		invoice, err = lib.IssueInvoice(ctx, *contract, uuid.New(), models.InvoiceTypeDeposit, amounts)
+		Expect(err).To(BeNil())
+		Expect(invoice.IssuerID).To(Equal(contract.ClientID))
+		Expect(invoice.RecipientID).To(Equal(contract.ProviderID))
+		Expect(invoice.Number).To(Equal(fmt.Sprintf("INV-%06d", invoice.Sequence)))
+		Expect(invoice.Total).To(Equal(amounts.Major(amounts.Payout)))
+		Expect(invoice.Subtotal + invoice.TaxAmount).To(BeNumerically("~", invoice.Total, 1e-9))
+		Expect(invoice.IssuerDetails.Email).NotTo(BeNil())
+
+		// Fees are not billed by the client
+		sum := 0.0
+		for _, item := range invoice.LineItems {
+			Expect(item.Type).To(Equal(models.AmountLineItemPayout))
+			sum += item.Amount
+		}
+		Expect(sum).To(BeNumerically("~", invoice.Subtotal, 1e-9))
+	})
+
+	It("should issue an invoice once per payment", func() {
+		contract, _ := models.GetContract(invoice.ContractID)
+		amounts := lib.CalculateAmounts(lib.AmountsOptionsFromContract(*contract, nil, nil))
+
+		again, err := lib.IssueInvoice(ctx, *contract, invoice.PaymentID, models.InvoiceTypeDeposit, amounts)
+		Expect(err).To(BeNil())
+		Expect(again.ID).To(Equal(invoice.ID))
+
+		release, err := lib.IssueInvoice(ctx, *contract, invoice.PaymentID, models.InvoiceTypeRelease, amounts)
+		Expect(err).To(BeNil())
+		Expect(release.ID).NotTo(Equal(invoice.ID))
+		Expect(release.Sequence).To(Equal(invoice.Sequence + 1))
+	})
+
+	It("should list invoices of both parties", func() {
+		for _, token := range authTokens[:2] {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/invoices", nil)
+			req.Header.Set("Authorization", token)
+			router.ServeHTTP(w, req)
+			body := decodeBody(w.Body)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(body["total"]).To(BeNumerically(">=", 2))
+		}
+	})
+
+	It("should get invoice and its pdf", func() {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/invoices/%s", invoice.ID), nil)
+		req.Header.Set("Authorization", authTokens[0])
+		router.ServeHTTP(w, req)
+		body := decodeBody(w.Body)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(body["number"]).To(Equal(invoice.Number))
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", fmt.Sprintf("/invoices/%s/pdf", invoice.ID), nil)
+		req.Header.Set("Authorization", authTokens[1])
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/pdf"))
+	})
+
+	It("should wrap long invoice lines over pages", func() {
+		long := *invoice
+		long.LineItems = []models.InvoiceLineItem{}
+		for i := 0; i < 40; i++ {
+			long.LineItems = append(long.LineItems, models.InvoiceLineItem{
+				Type:        models.AmountLineItemPayout,
+				Description: strings.Repeat("Contract payout of the approved works ", 8),
+				Amount:      1,
+			})
+		}
+		pdf, err := lib.InvoicePdf(config.Config.Invoices.Template, long)
+		Expect(err).To(BeNil())
+		pages, err := api.PageCount(bytes.NewReader(pdf), nil)
+		Expect(err).To(BeNil())
+		Expect(pages).To(BeNumerically(">", 1))
+	})
+
+	It("should invoice synchronous fiat deposits", func() {
+		// Stripe lists the card of the customer and confirms the payment intent right away
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/v1/payment_methods":
+				fmt.Fprint(w, `{"object": "list", "url": "/v1/payment_methods", "has_more": false, "data": [{"id": "pm_deposit", "object": "payment_method"}]}`)
+			case "/v1/payment_intents":
+				fmt.Fprint(w, `{"id": "pi_deposit", "object": "payment_intent", "amount": 50000, "currency": "usd", "status": "succeeded"}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+			URL: stripe.String(server.URL),
+		}))
+		DeferCleanup(func() {
+			stripe.SetBackend(stripe.APIBackend, nil)
+			server.Close()
+		})
+
+		customer := "cus_deposit"
+		card := &models.Card{IdentityId: usersData[0].ID, Meta: []byte(`{"id": "pm_deposit"}`), Customer: &customer}
+		Expect(card.Create(ctx)).To(BeNil())
+		DeferCleanup(func() {
+			Expect(card.Delete(ctx)).To(BeNil())
+		})
+		oauthConnect := &models.OauthConnect{
+			IdentityId:     usersData[1].ID,
+			Provider:       models.OauthConnectedProvidersStripeJp,
+			MatrixUniqueID: "acct_deposit",
+			AccessToken:    "access_token",
+		}
+		Expect(oauthConnect.Upsert(ctx)).To(BeNil())
+
+		code, contract := request("POST", "/contracts", gin.H{
+			"title":             "fiat contract",
+			"description":       "paid by card",
+			"total_amount":      500,
+			"currency":          "USD",
+			"type":              "PAID",
+			"payment_type":      "FIAT",
+			"commitment":        10,
+			"commitment_period": "MONTHLY",
+			"client_id":         usersData[1].ID,
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		depositPath := fmt.Sprintf("/contracts/%s/deposit", contract["id"])
+
+		code, _ = request("POST", depositPath, gin.H{}, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+
+		code, _ = request("POST", fmt.Sprintf("/contracts/%s/sign", contract["id"]), gin.H{}, authTokens[1])
+		Expect(code).To(Equal(http.StatusAccepted))
+
+		code, _ = request("POST", depositPath, gin.H{}, authTokens[1])
+		Expect(code).To(Equal(http.StatusForbidden))
+
+		code, contract = request("POST", depositPath, gin.H{}, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(contract["payment_id"]).NotTo(BeNil())
+
+		// A deposited payment is never replaced
+		code, body := request("POST", depositPath, gin.H{}, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(Equal("contract is already deposited"))
+
+		code, body = request("GET", "/invoices?limit=100", nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		var deposited map[string]interface{}
+		for _, result := range body["results"].([]interface{}) {
+			if result.(map[string]interface{})["payment_id"] == contract["payment_id"] {
+				deposited = result.(map[string]interface{})
+			}
+		}
+		Expect(deposited).NotTo(BeNil())
+		Expect(deposited["contract_id"]).To(Equal(contract["id"]))
+		Expect(deposited["type"]).To(Equal(string(models.InvoiceTypeDeposit)))
+	})
+}
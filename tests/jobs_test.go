@@ -0,0 +1,113 @@
+package tests_test
+
+import (
+	"context"
+	"net/http"
+	"socious/src/apps/models"
+	"socious/src/apps/workers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func jobGroup() {
+
+	ctx := context.Background()
+
+	newContract := func(name string) uuid.UUID {
+		code, contract := request("POST", "/contracts", gin.H{
+			"title":             name,
+			"description":       "waiting for the client signature",
+			"total_amount":      97,
+			"currency":          "USD",
+			"type":              "PAID",
+			"payment_type":      "CRYPTO",
+			"commitment":        10,
+			"commitment_period": "MONTHLY",
+			"client_id":         usersData[1].ID,
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		return uuid.MustParse(contract["id"].(string))
+	}
+
+	It("should expire projects past their expiry", func() {
+		title := "Expired survey"
+		expired := createProject(&models.Project{Title: &title, IdentityID: usersData[0].ID})
+		title = "Running survey"
+		running := createProject(&models.Project{Title: &title, IdentityID: usersData[0].ID})
+		_, err := db.Exec("UPDATE projects SET expires_at=NOW() - interval '1 day' WHERE id=$1", expired.ID)
+		Expect(err).To(BeNil())
+		_, err = db.Exec("UPDATE projects SET expires_at=NOW() + interval '30 days' WHERE id=$1", running.ID)
+		Expect(err).To(BeNil())
+
+		Expect(workers.ExpireProjects(ctx)).To(BeNil())
+
+		p, err := models.GetProject(expired.ID)
+		Expect(err).To(BeNil())
+		Expect(*p.Status).To(Equal(models.ProjectStatusExpire))
+		p, err = models.GetProject(running.ID)
+		Expect(err).To(BeNil())
+		Expect(*p.Status).To(Equal(models.ProjectStatusActive))
+	})
+
+	It("should cancel contracts left unsigned", func() {
+		unsigned := newContract("unsigned survey")
+		recent := newContract("recent survey")
+		_, err := db.Exec("UPDATE contracts SET created_at=NOW() - interval '365 days' WHERE id=$1", unsigned)
+		Expect(err).To(BeNil())
+
+		Expect(workers.CancelUnsignedContracts(ctx)).To(BeNil())
+
+		contract, err := models.GetContract(unsigned)
+		Expect(err).To(BeNil())
+		Expect(contract.Status).To(Equal(models.ContractStatusProviderCanceled))
+		contract, err = models.GetContract(recent)
+		Expect(err).To(BeNil())
+		Expect(contract.Status).To(Equal(models.ContractStatusCreated))
+	})
+
+	It("should claim expiry reminders once until released", func() {
+		title := "Expiring survey"
+		project := createProject(&models.Project{Title: &title, IdentityID: usersData[0].ID})
+		_, err := db.Exec("UPDATE projects SET expires_at=NOW() + interval '1 day' WHERE id=$1", project.ID)
+		Expect(err).To(BeNil())
+		contractID := newContract("expiring survey")
+		_, err = db.Exec("UPDATE contracts SET created_at=NOW() - interval '13 days' WHERE id=$1", contractID)
+		Expect(err).To(BeNil())
+
+		expiring := func() []uuid.UUID {
+			ids := []uuid.UUID{}
+			projects, err := models.GetExpiringProjects(3, 100)
+			Expect(err).To(BeNil())
+			contracts, err := models.GetExpiringContracts(14, 3, 100)
+			Expect(err).To(BeNil())
+			for _, r := range append(projects, contracts...) {
+				ids = append(ids, r.ID)
+			}
+			return ids
+		}
+		Expect(expiring()).To(ContainElements(project.ID, contractID))
+
+		for _, ids := range [][2]*uuid.UUID{{&project.ID, nil}, {nil, &contractID}} {
+			claimed, err := models.ClaimExpiryReminder(ctx, ids[0], ids[1])
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeTrue())
+			claimed, err = models.ClaimExpiryReminder(ctx, ids[0], ids[1])
+			Expect(err).To(BeNil())
+			Expect(claimed).To(BeFalse())
+		}
+		// Claimed reminders are not picked up again
+		Expect(expiring()).NotTo(ContainElement(project.ID))
+		Expect(expiring()).NotTo(ContainElement(contractID))
+
+		// Reminders failed to send are released to be retried
+		Expect(models.ReleaseExpiryReminder(ctx, &project.ID, nil)).To(BeNil())
+		Expect(expiring()).To(ContainElement(project.ID))
+		Expect(expiring()).NotTo(ContainElement(contractID))
+		claimed, err := models.ClaimExpiryReminder(ctx, &project.ID, nil)
+		Expect(err).To(BeNil())
+		Expect(claimed).To(BeTrue())
+	})
+}
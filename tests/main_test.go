@@ -1,15 +1,19 @@
 package tests_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"socious/src/apps"
+	"socious/src/apps/models"
 	"socious/src/config"
 	"strings"
 	"testing"
@@ -50,6 +54,12 @@ var _ = BeforeSuite(func() {
 		token, _ := goaccount.GenerateToken(u.ID.String(), false)
 		authTokens = append(authTokens, token)
 	}
+	for _, u := range jurorsData {
+		u.IdentityVerified = true
+		Expect(u.Upsert(ctx)).To(BeNil())
+		_, err := db.Exec("UPDATE users SET is_contributor=true WHERE id=$1", u.ID)
+		Expect(err).To(BeNil())
+	}
 })
 
 // Drop the database after all tests have run
@@ -67,7 +77,19 @@ var _ = Describe("Socious Test Suite", Ordered, func() {
 	Context("Auth", authGroup)
 	Context("User", userGroup)
 	Context("Projects", projectGroup)
+	Context("Project Search", projectSearchGroup)
 	Context("Contracts", contractGroup)
+	Context("Amounts", amountsGroup)
+	Context("Webhooks", webhookGroup)
+	Context("Deposits", depositGroup)
+	Context("Referrals", referralGroup)
+	Context("Rates", rateGroup)
+	Context("Invoices", invoiceGroup)
+	Context("Cards", cardGroup)
+	Context("Applicants", applicantGroup)
+	Context("Questions", questionGroup)
+	Context("Project Marks", projectMarkGroup)
+	Context("Jobs", jobGroup)
 })
 
 func init() {
@@ -99,6 +121,32 @@ func decodeBody(responseBody io.Reader) gin.H {
 	decoder.Decode(&body)
 	return body
 }
+
+// request sends the body as JSON to the router authorized with the token, nil bodies and empty tokens are left out
+func request(method, path string, body interface{}, token string) (int, map[string]interface{}) {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req, err := http.NewRequest(method, path, bytes.NewBuffer(data))
+	Expect(err).ToNot(HaveOccurred())
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code, decodeBody(w.Body)
+}
+
+// createProject creates an active job project with the fields set on the project
+func createProject(p *models.Project) *models.Project {
+	status := models.ProjectStatusActive
+	p.Status = &status
+	p.Kind = models.ProjectKindJob
+	Expect(p.Create(context.Background(), nil)).To(BeNil())
+	return p
+}
 func bodyExpect(body, expect gin.H) {
 	replaceAny(expect, body)
 	Expect(body).To(Equal(expect))
@@ -143,7 +191,7 @@ func setupTestEnvironment() (*sqlx.DB, *gin.Engine) {
 		DB:     database.GetDB(),
 		Prefix: "gopay",
 		Chains: config.Config.Payment.Chains,
-		Fiats:  config.Config.Payment.Fiats,
+		Fiats:  config.Config.Payment.Fiats.Gopay(),
 	}); err != nil {
 		log.Fatalf("gopay error %v", err)
 	}
@@ -0,0 +1,75 @@
+package tests_test
+
+import (
+	"net/http"
+	"socious/src/apps/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func projectMarkGroup() {
+
+	projects := map[string]*models.Project{}
+
+	BeforeAll(func() {
+		country := "NZ"
+		for _, name := range []string{"saved", "hidden"} {
+			title := name
+			projects[name] = createProject(&models.Project{
+				Title:      &title,
+				IdentityID: usersData[1].ID,
+				Country:    &country,
+			})
+		}
+	})
+
+	resultIDs := func(body map[string]interface{}) []interface{} {
+		ids := []interface{}{}
+		for _, r := range body["results"].([]interface{}) {
+			ids = append(ids, r.(map[string]interface{})["id"])
+		}
+		return ids
+	}
+
+	It("should mark projects", func() {
+		code, body := request("POST", "/projects/"+projects["saved"].ID.String()+"/marks", nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		Expect(body["marked_as"]).To(Equal(string(models.ProjectMarkTypeSave)))
+
+		code, _ = request("POST", "/projects/"+projects["hidden"].ID.String()+"/marks", map[string]any{"type": "HIDDEN"}, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+
+		code, body = request("POST", "/projects/"+projects["hidden"].ID.String()+"/marks", map[string]any{"type": "NOT_INTERESTED"}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		Expect(body["marked_as"]).To(Equal(string(models.ProjectMarkTypeNotInterested)))
+	})
+
+	It("should list marked projects by type", func() {
+		code, body := request("GET", "/projects/marked?type=save", nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(resultIDs(body)).To(Equal([]interface{}{projects["saved"].ID.String()}))
+
+		_, body = request("GET", "/projects/marked", nil, authTokens[0])
+		Expect(body["total"]).To(Equal(float64(2)))
+
+		_, body = request("GET", "/projects/marked", nil, authTokens[1])
+		Expect(body["total"]).To(Equal(float64(0)))
+	})
+
+	It("should exclude not interested projects from search", func() {
+		_, body := request("GET", "/projects/search?filter.country=NZ", nil, authTokens[0])
+		Expect(resultIDs(body)).To(Equal([]interface{}{projects["saved"].ID.String()}))
+
+		_, body = request("GET", "/projects/search?filter.country=NZ", nil, "")
+		Expect(body["total"]).To(Equal(float64(2)))
+	})
+
+	It("should unmark projects", func() {
+		code, _ := request("DELETE", "/projects/"+projects["hidden"].ID.String()+"/marks", nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+
+		_, body := request("GET", "/projects/search?filter.country=NZ", nil, authTokens[0])
+		Expect(body["total"]).To(Equal(float64(2)))
+	})
+}
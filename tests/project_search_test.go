@@ -0,0 +1,140 @@
+package tests_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"socious/src/apps/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func projectSearchGroup() {
+
+	ctx := context.Background()
+	projects := map[string]*models.Project{}
+
+	BeforeAll(func() {
+		// Tokyo, Yokohama (~28km away) and Osaka (~400km away)
+		_, err := db.Exec(`
+			INSERT INTO geonames (id, name, asciiname, latlong, country_code) VALUES
+			(1850147, 'Tokyo', 'Tokyo', point(35.6895, 139.69171), 'JP'),
+			(1848354, 'Yokohama', 'Yokohama', point(35.44778, 139.6425), 'JP'),
+			(1853909, 'Osaka', 'Osaka', point(34.69374, 135.50218), 'JP')
+			ON CONFLICT DO NOTHING`)
+		Expect(err).To(BeNil())
+
+		str := func(s string) *string { return &s }
+		geoname := func(id int) *int { return &id }
+		status := models.ProjectStatusActive
+		remote, onsite := models.ProjectRemotePreferenceRemote, models.ProjectRemotePreferenceOnsite
+		paid, volunteer := models.PaymentTypePaid, models.PaymentTypeVolunteer
+
+		for name, p := range map[string]*models.Project{
+			"tokyo": {
+				Title: str("Climate data engineer"), Description: str("Build pipelines measuring carbon emissions"),
+				Skills: []string{"GO", "SQL"}, CausesTags: []string{"CLIMATE_CHANGE"}, RemotePreference: &onsite,
+				PaymentType: &paid, PaymentRangeLower: str("3000"), PaymentRangeHigher: str("5000"),
+				Country: str("JP"), City: str("Tokyo"), GeonameId: geoname(1850147),
+			},
+			"yokohama": {
+				Title: str("Community organizer"), Description: str("Organize climate events in the city"),
+				Skills: []string{"COMMUNICATION"}, CausesTags: []string{"CLIMATE_CHANGE", "SOCIAL"}, RemotePreference: &onsite,
+				PaymentType: &volunteer, Country: str("JP"), City: str("Yokohama"), GeonameId: geoname(1848354),
+			},
+			"osaka": {
+				Title: str("Backend developer"), Description: str("Maintain the donation platform"),
+				Skills: []string{"GO"}, CausesTags: []string{"POVERTY"}, RemotePreference: &remote,
+				PaymentType: &paid, PaymentRangeLower: str("1000"), PaymentRangeHigher: str("2000"),
+				Country: str("JP"), City: str("Osaka"), GeonameId: geoname(1853909),
+			},
+		} {
+			p.IdentityID = usersData[1].ID
+			p.Status = &status
+			p.Kind = models.ProjectKindJob
+			Expect(p.Create(ctx, nil)).To(BeNil())
+			projects[name] = p
+		}
+	})
+
+	search := func(params url.Values) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/search?%s", params.Encode()), nil)
+		router.ServeHTTP(w, req)
+		return w.Code, decodeBody(w.Body)
+	}
+
+	resultIDs := func(body map[string]interface{}) []interface{} {
+		ids := []interface{}{}
+		for _, r := range body["results"].([]interface{}) {
+			ids = append(ids, r.(map[string]interface{})["id"])
+		}
+		return ids
+	}
+
+	idOf := func(name string) interface{} {
+		return projects[name].ID.String()
+	}
+
+	It("should search projects without login", func() {
+		code, body := search(url.Values{"filter.country": {"JP"}})
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["total"]).To(Equal(float64(3)))
+	})
+
+	It("should rank full-text matches", func() {
+		_, body := search(url.Values{"q": {"climate"}, "filter.country": {"JP"}})
+		Expect(resultIDs(body)).To(ConsistOf(idOf("tokyo"), idOf("yokohama")))
+
+		_, body = search(url.Values{"q": {"donation platform"}})
+		Expect(resultIDs(body)).To(Equal([]interface{}{idOf("osaka")}))
+	})
+
+	It("should filter by any of the values", func() {
+		_, body := search(url.Values{"filter.skills": {"GO"}, "filter.country": {"JP"}})
+		Expect(resultIDs(body)).To(ConsistOf(idOf("tokyo"), idOf("osaka")))
+
+		_, body = search(url.Values{"filter.causes_tags": {"social,poverty"}, "filter.country": {"JP"}})
+		Expect(resultIDs(body)).To(ConsistOf(idOf("yokohama"), idOf("osaka")))
+
+		_, body = search(url.Values{"filter.remote_preference": {"REMOTE"}, "filter.payment_type": {"PAID"}, "filter.city": {"osaka"}})
+		Expect(resultIDs(body)).To(Equal([]interface{}{idOf("osaka")}))
+	})
+
+	It("should filter by payment range", func() {
+		_, body := search(url.Values{"filter.payment_min": {"2500"}, "filter.country": {"JP"}})
+		Expect(resultIDs(body)).To(Equal([]interface{}{idOf("tokyo")}))
+
+		_, body = search(url.Values{"filter.payment_min": {"1500"}, "filter.payment_max": {"2500"}, "filter.country": {"JP"}})
+		Expect(resultIDs(body)).To(Equal([]interface{}{idOf("osaka")}))
+	})
+
+	It("should filter by radius around geoname", func() {
+		_, body := search(url.Values{"filter.geoname_id": {"1850147"}})
+		Expect(resultIDs(body)).To(Equal([]interface{}{idOf("tokyo")}))
+
+		_, body = search(url.Values{"filter.geoname_id": {"1850147"}, "filter.radius": {"50"}})
+		Expect(resultIDs(body)).To(ConsistOf(idOf("tokyo"), idOf("yokohama")))
+
+		code, _ := search(url.Values{"filter.radius": {"50"}})
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should count facets of the results", func() {
+		_, body := search(url.Values{"filter.country": {"JP"}})
+		facets := body["facets"].(map[string]interface{})
+		Expect(facets["skills"]).To(ContainElement(map[string]interface{}{"value": "GO", "count": float64(2)}))
+		Expect(facets["causes_tags"]).To(ContainElement(map[string]interface{}{"value": "CLIMATE_CHANGE", "count": float64(2)}))
+		Expect(facets["remote_preference"]).To(ContainElement(map[string]interface{}{"value": "ONSITE", "count": float64(2)}))
+		Expect(facets["kind"]).To(Equal([]interface{}{map[string]interface{}{"value": "JOB", "count": float64(3)}}))
+	})
+
+	It("should paginate results", func() {
+		_, body := search(url.Values{"filter.country": {"JP"}, "limit": {"2"}, "page": {"2"}})
+		Expect(body["total"]).To(Equal(float64(3)))
+		Expect(body["results"]).To(HaveLen(1))
+	})
+}
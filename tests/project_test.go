@@ -79,6 +79,58 @@ func projectGroup() {
 		}
 	})
 
+	It("should not update or delete service of others", func() {
+		for _, data := range servicesData {
+			for _, method := range []string{"PATCH", "DELETE"} {
+				w := httptest.NewRecorder()
+				reqBody, _ := json.Marshal(gin.H{"title": "taken over"})
+				req, _ := http.NewRequest(method, fmt.Sprintf("/projects/%s", data["id"]), bytes.NewBuffer(reqBody))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", authTokens[1])
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+			}
+		}
+	})
+
+	It("should update only the fields of the request", func() {
+		for _, data := range servicesData {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(gin.H{"title": "updated service", "project_type": "PART_TIME"})
+			req, _ := http.NewRequest("PATCH", fmt.Sprintf("/projects/%s", data["id"]), bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			body := decodeBody(w.Body)
+			Expect(body["title"]).To(Equal("updated service"))
+			Expect(body["project_type"]).To(Equal("PART_TIME"))
+			Expect(body["description"]).To(Equal(data["description"]))
+			Expect(body["project_length"]).To(Equal(data["project_length"]))
+			Expect(body["payment_currency"]).To(Equal(data["payment_currency"]))
+			Expect(body["work_samples"]).To(HaveLen(len(data["work_samples"].([]string))))
+		}
+	})
+
+	It("should not update service with invalid enum values", func() {
+		for _, data := range servicesData {
+			for _, update := range []gin.H{
+				{"project_length": "FOREVER"},
+				{"status": "ARCHIVED"},
+				{"remote_preference": "ANYWHERE"},
+				{"causes_tags": []string{"CLIMATE_CHANGE", "NOT_A_CAUSE"}},
+			} {
+				w := httptest.NewRecorder()
+				reqBody, _ := json.Marshal(update)
+				req, _ := http.NewRequest("PATCH", fmt.Sprintf("/projects/%s", data["id"]), bytes.NewBuffer(reqBody))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", authTokens[0])
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			}
+		}
+	})
+
 	It("should delete service", func() {
 		for _, data := range servicesData {
 			w := httptest.NewRecorder()
@@ -0,0 +1,135 @@
+package tests_test
+
+import (
+	"fmt"
+	"net/http"
+	"socious/src/apps/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func questionGroup() {
+
+	project := new(models.Project)
+	questions := map[string]string{}
+
+	BeforeAll(func() {
+		title, description := "Program manager", "Run the reforestation program"
+		project.Title = &title
+		project.Description = &description
+		project.IdentityID = usersData[0].ID
+		createProject(project)
+	})
+
+	questionsPath := func() string {
+		return fmt.Sprintf("/projects/%s/questions", project.ID)
+	}
+
+	It("should add questions to own project only", func() {
+		code, _ := request("POST", questionsPath(), map[string]any{"question": "Why us?"}, authTokens[1])
+		Expect(code).To(Equal(http.StatusForbidden))
+
+		code, _ = request("POST", questionsPath(), map[string]any{"question": "Pick one", "options": []string{"Only"}}, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+
+		code, body := request("POST", questionsPath(), map[string]any{
+			"question": "Why do you want to join?",
+			"required": true,
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		questions["text"] = body["id"].(string)
+
+		code, body = request("POST", questionsPath(), map[string]any{
+			"question": "How many years of field experience?",
+			"required": true,
+			"options":  []string{"None", "1-3", "4+"},
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		questions["choice"] = body["id"].(string)
+
+		code, body = request("POST", questionsPath(), map[string]any{"question": "Anything else?"}, authTokens[0])
+		Expect(code).To(Equal(http.StatusCreated))
+		questions["optional"] = body["id"].(string)
+	})
+
+	It("should include questions in project", func() {
+		code, body := request("GET", fmt.Sprintf("/projects/%s", project.ID), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["questions"]).To(HaveLen(3))
+		choice := body["questions"].([]interface{})[1].(map[string]interface{})
+		Expect(choice["options"]).To(Equal([]interface{}{"None", "1-3", "4+"}))
+
+		code, body = request("GET", questionsPath(), nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["total"]).To(Equal(float64(3)))
+	})
+
+	It("should update and delete questions", func() {
+		path := fmt.Sprintf("%s/%s", questionsPath(), questions["optional"])
+		code, body := request("PATCH", path, map[string]any{"question": "Anything else to share?"}, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["question"]).To(Equal("Anything else to share?"))
+
+		code, _ = request("DELETE", path, nil, authTokens[1])
+		Expect(code).To(Equal(http.StatusForbidden))
+
+		code, _ = request("DELETE", path, nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+	})
+
+	It("should require answers to required questions", func() {
+		path := fmt.Sprintf("/projects/%s/applicants", project.ID)
+		for _, answers := range [][]map[string]any{
+			{{"question_id": questions["text"], "answer": "To plant trees"}},
+			{{"question_id": questions["text"], "answer": " "}, {"question_id": questions["choice"], "selected_option": 1}},
+			{{"question_id": questions["text"], "answer": "To plant trees"}, {"question_id": questions["choice"], "selected_option": 3}},
+		} {
+			code, _ := request("POST", path, map[string]any{"cover_letter": "Hello", "answers": answers}, authTokens[1])
+			Expect(code).To(Equal(http.StatusBadRequest))
+		}
+
+		code, body := request("POST", path, map[string]any{
+			"cover_letter": "Hello",
+			"answers": []map[string]any{
+				{"question_id": questions["text"], "answer": "To plant trees"},
+				{"question_id": questions["choice"], "selected_option": 2},
+			},
+		}, authTokens[1])
+		Expect(code).To(Equal(http.StatusCreated))
+		Expect(body["answers"]).To(HaveLen(2))
+	})
+
+	It("should show answers to the owner with applicants", func() {
+		code, body := request("GET", fmt.Sprintf("/projects/%s/applicants", project.ID), nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		applicant := body["results"].([]interface{})[0].(map[string]interface{})
+		answers := applicant["answers"].([]interface{})
+		Expect(answers[0].(map[string]interface{})["answer"]).To(Equal("To plant trees"))
+		Expect(answers[1].(map[string]interface{})["question"]).To(Equal("How many years of field experience?"))
+		Expect(answers[1].(map[string]interface{})["option"]).To(Equal("4+"))
+	})
+
+	It("should keep options and answered questions", func() {
+		path := fmt.Sprintf("%s/%s", questionsPath(), questions["choice"])
+		code, body := request("PATCH", path, map[string]any{
+			"question": "How many years of field experience?",
+			"required": true,
+			"options":  []string{"4+", "1-3", "None"},
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(Equal("options of answered question can not be changed"))
+
+		code, body = request("PATCH", path, map[string]any{
+			"question": "Years of field experience?",
+			"required": true,
+			"options":  []string{"None", "1-3", "4+"},
+		}, authTokens[0])
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["question"]).To(Equal("Years of field experience?"))
+
+		code, body = request("DELETE", path, nil, authTokens[0])
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(body["error"]).To(Equal("answered question can not be deleted"))
+	})
+}
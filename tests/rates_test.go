@@ -0,0 +1,119 @@
+package tests_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func rateGroup() {
+
+	ctx := context.Background()
+	var requests atomic.Int32
+
+	// fake http source of the rates
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		rates := map[string]map[string]float64{
+			"USD": {"JPY": 150, "EUR": 0.9},
+			"ADA": {"USD": 0.35},
+		}
+		base := r.URL.Query().Get("base")
+		json.NewEncoder(w).Encode(map[string]interface{}{"base": base, "rates": rates[base]})
+	}))
+
+	BeforeAll(func() {
+		lib.SetRateSource(lib.NewHTTPRateSource(source.URL, ""))
+	})
+
+	AfterAll(func() {
+		lib.SetRateSource(nil)
+		source.Close()
+	})
+
+	It("should get rates of the base", func() {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/rates?base=usd&quotes=JPY", nil)
+		router.ServeHTTP(w, req)
+		body := decodeBody(w.Body)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(body["base"]).To(Equal("USD"))
+		Expect(body["total"]).To(Equal(float64(1)))
+		rate := body["results"].([]interface{})[0].(map[string]interface{})
+		Expect(rate["quote"]).To(Equal("JPY"))
+		Expect(rate["rate"]).To(Equal(float64(150)))
+		Expect(rate["source"]).To(Equal("http"))
+		Expect(requests.Load()).To(Equal(int32(1)))
+	})
+
+	It("should refuse unknown base", func() {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/rates?base=nope", nil)
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(requests.Load()).To(Equal(int32(1)))
+	})
+
+	It("should serve cached rates until they expire", func() {
+		rates, err := lib.GetRates(ctx, "USD")
+		Expect(err).To(BeNil())
+		Expect(rates).To(HaveLen(2))
+		Expect(requests.Load()).To(Equal(int32(1)))
+
+		_, err = db.Exec(`UPDATE currency_rates SET fetched_at=NOW() - INTERVAL '1 day' WHERE base='USD'`)
+		Expect(err).To(BeNil())
+		_, err = lib.GetRates(ctx, "USD")
+		Expect(err).To(BeNil())
+		Expect(requests.Load()).To(Equal(int32(2)))
+	})
+
+	It("should invert rates of the quote", func() {
+		rate, err := lib.GetRate(ctx, "ADA", "USD")
+		Expect(err).To(BeNil())
+		Expect(rate.Rate).To(Equal(0.35))
+
+		rate, err = lib.GetRate(ctx, "USD", "ADA")
+		Expect(err).To(BeNil())
+		Expect(rate.Rate).To(BeNumerically("~", 1/0.35, 1e-9))
+
+		_, err = lib.GetRate(ctx, "USD", "BTC")
+		Expect(err).To(MatchError(lib.ErrRateNotFound))
+	})
+
+	It("should read rates from file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "rates.yml")
+		Expect(os.WriteFile(path, []byte("USD:\n  JPY: 151.5\n"), 0o600)).To(Succeed())
+
+		rates, err := lib.NewFileRateSource(path).Rates(ctx, "usd")
+		Expect(err).To(BeNil())
+		Expect(rates).To(Equal(map[string]float64{"JPY": 151.5}))
+	})
+
+	It("should convert amounts to tokens at the rate", func() {
+		Expect(lib.TokenAmount(100, 0.35, 6)).To(Equal(285.714285))
+		Expect(lib.TokenAmount(100, 1, 6)).To(Equal(100.0))
+	})
+
+	It("should lock contract rate once apart from the offered rate", func() {
+		contract, err := models.GetContract(uuid.MustParse(contractsData[0]["id"].(string)))
+		Expect(err).To(BeNil())
+		offered := contract.CurrencyRate
+
+		Expect(contract.LockCurrencyRate(ctx, 2.5)).To(Succeed())
+		Expect(*contract.LockedCurrencyRate).To(Equal(2.5))
+		Expect(contract.LockCurrencyRate(ctx, 3)).To(Succeed())
+		Expect(*contract.LockedCurrencyRate).To(Equal(2.5))
+		Expect(contract.CurrencyRate).To(Equal(offered))
+	})
+}
@@ -0,0 +1,149 @@
+package tests_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"socious/src/apps/lib"
+	"socious/src/apps/models"
+	"socious/src/apps/workers"
+	"sync"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stripe/stripe-go/v81"
+)
+
+func referralGroup() {
+
+	ctx := context.Background()
+
+	credit := func(currency string, network *models.WalletNetwork) *models.ReferralReward {
+		paymentID := uuid.New()
+		reward := &models.ReferralReward{
+			ReferrerID:         jurorsData[2].ID,
+			ReferredIdentityID: usersData[1].ID,
+			ContractID:         uuid.MustParse(contractsData[0]["id"].(string)),
+			PaymentID:          &paymentID,
+			Amount:             12.5,
+			Currency:           currency,
+			CryptoNetwork:      network,
+		}
+		created, err := reward.Create(ctx)
+		Expect(err).To(BeNil())
+		Expect(created).To(BeTrue())
+		return reward
+	}
+
+	rewardStatus := func(reward *models.ReferralReward) (status models.ReferralRewardStatus, payoutID *uuid.UUID) {
+		row := db.QueryRow("SELECT status, payout_id FROM referral_rewards WHERE id=$1", reward.ID)
+		Expect(row.Scan(&status, &payoutID)).To(BeNil())
+		return status, payoutID
+	}
+
+	It("should transfer fiat rewards to the stripe account of the referrer once", func() {
+		var (
+			mu           sync.Mutex
+			transfers    = map[string]int{}
+			destinations = []string{}
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path != "/v1/transfers" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			r.ParseForm()
+			mu.Lock()
+			transfers[r.Header.Get("Idempotency-Key")]++
+			destinations = append(destinations, r.Form.Get("destination"))
+			mu.Unlock()
+			fmt.Fprintf(w, `{"id": "tr_%s", "object": "transfer", "amount": %s, "currency": "usd"}`, r.Form.Get("amount"), r.Form.Get("amount"))
+		}))
+		stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+			URL: stripe.String(server.URL),
+		}))
+		DeferCleanup(func() {
+			stripe.SetBackend(stripe.APIBackend, nil)
+			server.Close()
+		})
+
+		oauthConnect := &models.OauthConnect{
+			IdentityId:     jurorsData[2].ID,
+			Provider:       models.OauthConnectedProvidersStripe,
+			MatrixUniqueID: "acct_referrer",
+			AccessToken:    "access_token",
+		}
+		Expect(oauthConnect.Upsert(ctx)).To(BeNil())
+		DeferCleanup(func() {
+			db.Exec("DELETE FROM oauth_connects WHERE matrix_unique_id=$1", oauthConnect.MatrixUniqueID)
+		})
+
+		reward := credit("USD", nil)
+		Expect(workers.PayoutReferralRewards(ctx)).To(BeNil())
+
+		status, payoutID := rewardStatus(reward)
+		Expect(status).To(Equal(models.ReferralRewardStatusPaidOut))
+		payout, err := models.GetReferralPayout(*payoutID)
+		Expect(err).To(BeNil())
+		Expect(payout.Status).To(Equal(models.ReferralPayoutStatusPaidOut))
+		Expect(*payout.FiatService).To(Equal(string(models.OauthConnectedProvidersStripe)))
+		Expect(*payout.TxID).To(HavePrefix("tr_"))
+		Expect(transfers).To(HaveKeyWithValue(fmt.Sprintf("referral-payout-%s", payout.ID), 1))
+		Expect(destinations).To(HaveEach("acct_referrer"))
+
+		// Paid out payouts are not transferred again
+		Expect(workers.PayoutReferralRewards(ctx)).To(BeNil())
+		Expect(transfers).To(HaveKeyWithValue(fmt.Sprintf("referral-payout-%s", payout.ID), 1))
+	})
+
+	It("should pay crypto payouts out once their transfer is confirmed", func() {
+		network := models.WalletNetworkSepolia
+		token := "0xreferraltoken"
+		reward := credit(token, &network)
+
+		pending := models.PendingReferralPayout{
+			ReferrerID:    jurorsData[2].ID,
+			Currency:      token,
+			CryptoNetwork: &network,
+			Address:       "0xReferrer",
+			Network:       &network,
+		}
+		payout, err := pending.Settle(ctx)
+		Expect(err).To(BeNil())
+		Expect(payout.Status).To(Equal(models.ReferralPayoutStatusScheduled))
+		Expect(payout.Amount).To(BeNumerically(">=", reward.Amount))
+		status, _ := rewardStatus(reward)
+		Expect(status).To(Equal(models.ReferralRewardStatusScheduled))
+
+		chain := fakeChain{
+			"0xpayout-wrong": {To: "0xSomeoneElse", TokenAddress: token, Amount: payout.Amount, Confirmations: 12},
+			"0xpayout":       {To: "0xreferrer", TokenAddress: token, Amount: payout.Amount, Confirmations: 3},
+		}
+
+		// Not transferred from the platform wallet yet
+		Expect(workers.PayReferralPayout(ctx, chain, payout)).To(BeNil())
+		Expect(payout.Status).To(Equal(models.ReferralPayoutStatusScheduled))
+
+		Expect(payout.Transferred(ctx, "0xpayout-wrong")).To(BeNil())
+		Expect(workers.PayReferralPayout(ctx, chain, payout)).NotTo(BeNil())
+		Expect(payout.Status).To(Equal(models.ReferralPayoutStatusScheduled))
+
+		// A wrong transfer is replaced, the payout waits for its confirmations
+		Expect(payout.Transferred(ctx, "0xpayout")).To(BeNil())
+		Expect(workers.PayReferralPayout(ctx, chain, payout)).To(BeNil())
+		Expect(payout.Status).To(Equal(models.ReferralPayoutStatusScheduled))
+
+		chain["0xpayout"].Confirmations = lib.DepositConfirmations()
+		Expect(workers.PayReferralPayout(ctx, chain, payout)).To(BeNil())
+		Expect(payout.Status).To(Equal(models.ReferralPayoutStatusPaidOut))
+		Expect(*payout.TxID).To(Equal("0xpayout"))
+		status, _ = rewardStatus(reward)
+		Expect(status).To(Equal(models.ReferralRewardStatusPaidOut))
+
+		// Paid out payouts can't be transferred again
+		Expect(payout.Transferred(ctx, "0xpayout-again")).NotTo(BeNil())
+	})
+}
@@ -2,24 +2,35 @@ package tests_test
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/fxamacker/cbor/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
 )
 
 func userGroup() {
 
 	Describe("User", func() {
-		It("should update user wallet", func() {
-			wallet := map[string]any{
-				"address": "0x123",
-				"network": "bsc",
-				"testnet": false,
-			}
+		evmKey, _ := secp256k1.GeneratePrivateKey()
+		evmAddress := "0x" + hex.EncodeToString(keccak256(evmKey.PubKey().SerializeUncompressed()[1:])[12:])
+
+		cardanoPub, cardanoKey, _ := ed25519.GenerateKey(nil)
+		keyHash, _ := blake2b.New(28, nil)
+		keyHash.Write(cardanoPub)
+		// Enterprise mainnet address of the key
+		cardanoAddress := append([]byte{0x61}, keyHash.Sum(nil)...)
+
+		putWallet := func(wallet map[string]any) (int, map[string]interface{}) {
 			data, _ := json.Marshal(wallet)
 			req, err := http.NewRequest("PUT", "/users/wallets", bytes.NewBuffer(data))
 			Expect(err).ToNot(HaveOccurred())
@@ -28,9 +39,166 @@ func userGroup() {
 
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
-			body := decodeBody(w.Body)
-			fmt.Println(body)
+			return w.Code, decodeBody(w.Body)
+		}
+
+		challenge := func(address, network string, testnet bool) map[string]interface{} {
+			data, _ := json.Marshal(map[string]any{"address": address, "network": network, "testnet": testnet})
+			req, err := http.NewRequest("POST", "/users/wallets/challenge", bytes.NewBuffer(data))
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authTokens[0])
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			return decodeBody(w.Body)
+		}
+
+		signEIP191 := func(key *secp256k1.PrivateKey, message string) string {
+			hash := keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+			compact := ecdsa.SignCompact(key, hash, false)
+			// Compact signatures lead with the recovery id, wallets append it
+			return "0x" + hex.EncodeToString(append(compact[1:], compact[0]))
+		}
+
+		It("should not update user wallet without proof", func() {
+			code, _ := putWallet(map[string]any{
+				"address": evmAddress,
+				"network": "bsc",
+				"testnet": false,
+			})
+			Expect(code).NotTo(Equal(http.StatusOK))
+		})
+
+		It("should not update user wallet signed by another wallet", func() {
+			other, _ := secp256k1.GeneratePrivateKey()
+			c := challenge(evmAddress, "bsc", false)
+			code, _ := putWallet(map[string]any{
+				"address":      evmAddress,
+				"network":      "bsc",
+				"challenge_id": c["id"],
+				"signature":    signEIP191(other, c["message"].(string)),
+			})
+			Expect(code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should update user wallet", func() {
+			c := challenge(evmAddress, "bsc", false)
+			wallet := map[string]any{
+				"address":      evmAddress,
+				"network":      "bsc",
+				"testnet":      false,
+				"challenge_id": c["id"],
+				"signature":    signEIP191(evmKey, c["message"].(string)),
+			}
+			code, body := putWallet(wallet)
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(body["verified_at"]).NotTo(BeNil())
+			Expect(body["primary"]).To(BeTrue())
+
+			// Challenges are signed once
+			code, _ = putWallet(wallet)
+			Expect(code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should update user cardano wallet", func() {
+			address := hex.EncodeToString(cardanoAddress)
+			c := challenge(address, "cardano", false)
+
+			protected, _ := cbor.Marshal(map[interface{}]interface{}{1: -8, "address": cardanoAddress})
+			payload := []byte(c["message"].(string))
+			sigStructure, _ := cbor.Marshal([]interface{}{"Signature1", protected, []byte{}, payload})
+			signature, _ := cbor.Marshal([]interface{}{protected, map[interface{}]interface{}{"hashed": false}, payload, ed25519.Sign(cardanoKey, sigStructure)})
+			key, _ := cbor.Marshal(map[int]interface{}{1: 1, 3: -8, -1: 6, -2: []byte(cardanoPub)})
+
+			code, body := putWallet(map[string]any{
+				"address":      address,
+				"network":      "cardano",
+				"challenge_id": c["id"],
+				"signature":    hex.EncodeToString(signature),
+				"key":          hex.EncodeToString(key),
+			})
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(body["verified_at"]).NotTo(BeNil())
+		})
+
+		listWallets := func() []interface{} {
+			req, err := http.NewRequest("GET", "/users/wallets", nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Authorization", authTokens[0])
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
 			Expect(w.Code).To(Equal(http.StatusOK))
+			return decodeBody(w.Body)["results"].([]interface{})
+		}
+
+		primaryOn := func(network string) map[string]interface{} {
+			for _, w := range listWallets() {
+				wallet := w.(map[string]interface{})
+				if wallet["network"] == network && wallet["primary"] == true {
+					return wallet
+				}
+			}
+			return nil
+		}
+
+		It("should not make testnet wallet primary", func() {
+			c := challenge(evmAddress, "bsc", true)
+			code, testnet := putWallet(map[string]any{
+				"address":      evmAddress,
+				"network":      "bsc",
+				"testnet":      true,
+				"challenge_id": c["id"],
+				"signature":    signEIP191(evmKey, c["message"].(string)),
+			})
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(testnet["primary"]).To(BeFalse())
+			Expect(listWallets()).To(HaveLen(3))
+
+			setPrimary := func(id interface{}) int {
+				req, _ := http.NewRequest("POST", fmt.Sprintf("/users/wallets/%s/primary", id), nil)
+				req.Header.Set("Authorization", authTokens[0])
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w.Code
+			}
+
+			// Contracts pay out to the primary wallet which stays on mainnet
+			mainnet := primaryOn("bsc")
+			Expect(mainnet["testnet"]).To(BeFalse())
+			Expect(setPrimary(testnet["id"])).To(Equal(http.StatusBadRequest))
+			Expect(setPrimary(mainnet["id"])).To(Equal(http.StatusOK))
+			Expect(primaryOn("bsc")["id"]).To(Equal(mainnet["id"]))
+		})
+
+		It("should delete user wallet", func() {
+			primary := primaryOn("bsc")
+			// Payouts made to the wallet don't block removing it
+			_, err := db.Exec(
+				"INSERT INTO referral_payouts (referrer_id, wallet_id, address, network, currency, amount) VALUES ($1, $2, $3, 'bsc', '0xtoken', 1)",
+				usersData[0].ID, primary["id"], primary["address"],
+			)
+			Expect(err).To(BeNil())
+
+			req, _ := http.NewRequest("DELETE", fmt.Sprintf("/users/wallets/%s", primary["id"]), nil)
+			req.Header.Set("Authorization", authTokens[0])
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			// The testnet wallet left on the network doesn't take over
+			Expect(listWallets()).To(HaveLen(2))
+			Expect(primaryOn("bsc")).To(BeNil())
+		})
+
+		It("should not delete others wallet", func() {
+			req, _ := http.NewRequest("DELETE", fmt.Sprintf("/users/wallets/%s", primaryOn("cardano")["id"]), nil)
+			req.Header.Set("Authorization", authTokens[1])
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
 		})
 	})
 
@@ -45,4 +213,30 @@ func userGroup() {
 		Expect(w.Code).To(Equal(http.StatusOK))
 	})
 
+	It("should get referrals with earnings", func() {
+		_, err := db.Exec("INSERT INTO referrings (referred_identity_id, referred_by_id) VALUES ($1, $2)", usersData[1].ID, usersData[0].ID)
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err := http.NewRequest("GET", "/referrals", nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authTokens[0])
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		body := decodeBody(w.Body)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		results := body["results"].([]interface{})
+		Expect(len(results)).To(Equal(1))
+		referral := results[0].(map[string]interface{})
+		Expect(referral["identity_id"]).To(Equal(usersData[1].ID.String()))
+		Expect(referral["earnings"]).To(BeEmpty())
+	})
+
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
 }
@@ -0,0 +1,77 @@
+package tests_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"socious/src/apps/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stripe/stripe-go/v81/webhook"
+)
+
+func webhookGroup() {
+	ctx := context.Background()
+
+	event := []byte(`{"id": "evt_test_account", "object": "event", "type": "account.updated", "data": {"object": {"id": "acct_test", "object": "account", "charges_enabled": true, "payouts_enabled": true}}}`)
+
+	sendStripeEvent := func(payload []byte, secret string) (int, map[string]interface{}) {
+		signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: secret})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/webhooks/stripe", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Stripe-Signature", signed.Header)
+		router.ServeHTTP(w, req)
+		return w.Code, decodeBody(w.Body)
+	}
+
+	It("should reject stripe events with invalid signature", func() {
+		code, _ := sendStripeEvent(event, "whsec_wrong")
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should receive stripe events", func() {
+		code, body := sendStripeEvent(event, "whsec_test")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["received"]).To(BeTrue())
+	})
+
+	It("should acknowledge duplicated stripe events once", func() {
+		code, body := sendStripeEvent(event, "whsec_test")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body["duplicate"]).To(BeTrue())
+	})
+
+	It("should deactivate connected accounts failing to pay out", func() {
+		connects := []*models.OauthConnect{}
+		for _, provider := range []models.OauthConnectedProviders{models.OauthConnectedProvidersStripe, models.OauthConnectedProvidersStripeJp} {
+			oauthConnect := &models.OauthConnect{
+				IdentityId:     jurorsData[0].ID,
+				Provider:       provider,
+				MatrixUniqueID: "acct_payout_failed",
+				AccessToken:    "access_token",
+			}
+			Expect(oauthConnect.Upsert(ctx)).To(BeNil())
+			Expect(oauthConnect.UpdateStatus(ctx, models.UserStatusActive)).To(BeNil())
+			connects = append(connects, oauthConnect)
+		}
+		DeferCleanup(func() {
+			db.Exec("DELETE FROM oauth_connects WHERE matrix_unique_id='acct_payout_failed'")
+		})
+
+		payload := []byte(`{"id": "evt_test_payout_failed", "object": "event", "type": "payout.failed", "account": "acct_payout_failed", "data": {"object": {"id": "po_test", "object": "payout", "status": "failed"}}}`)
+		code, _ := sendStripeEvent(payload, "whsec_test")
+		Expect(code).To(Equal(http.StatusOK))
+
+		// Only the connect of the fiat service signed the event is deactivated
+		statuses := []models.UserStatus{}
+		for _, oauthConnect := range connects {
+			var status models.UserStatus
+			Expect(db.Get(&status, "SELECT status FROM oauth_connects WHERE id=$1", oauthConnect.ID)).To(BeNil())
+			statuses = append(statuses, status)
+		}
+		Expect(statuses).To(ContainElement(models.UserStatusInactive))
+	})
+}